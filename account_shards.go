@@ -0,0 +1,84 @@
+package bankingsystem
+
+import (
+	"hash/maphash"
+	"iter"
+)
+
+// DefaultAccountShards is the number of shards accounts are spread over unless
+// WithAccountShards says otherwise.
+const DefaultAccountShards = 16
+
+// WithAccountShards spreads accounts over n maps instead of the default. With millions of
+// accounts, smaller maps grow in smaller steps and give the garbage collector less to scan at
+// once. Sharding does not reduce contention: every access still goes through the store's own
+// lock, so it changes no behaviour. Values below 1 mean 1.
+func WithAccountShards(n int) Option {
+	return func(s *AccountStore) {
+		s.accountShards = n
+	}
+}
+
+// accountMap is the store's accounts, spread over shards by a hash of the account ID. It has
+// no lock of its own: callers hold the store's lock, read locked to read and write locked to
+// put or delete.
+type accountMap struct {
+	seed   maphash.Seed
+	shards []map[string]*Account
+}
+
+func newAccountMap(shards int) *accountMap {
+	m := &accountMap{seed: maphash.MakeSeed(), shards: make([]map[string]*Account, max(shards, 1))}
+	for i := range m.shards {
+		m.shards[i] = make(map[string]*Account)
+	}
+	return m
+}
+
+func (m *accountMap) shard(accountID string) map[string]*Account {
+	if len(m.shards) == 1 {
+		return m.shards[0]
+	}
+	return m.shards[maphash.String(m.seed, accountID)%uint64(len(m.shards))]
+}
+
+// lookup returns the account and whether it exists.
+func (m *accountMap) lookup(accountID string) (*Account, bool) {
+	account, exists := m.shard(accountID)[accountID]
+	return account, exists
+}
+
+// get returns the account, or nil if it does not exist.
+func (m *accountMap) get(accountID string) *Account {
+	account, _ := m.lookup(accountID)
+	return account
+}
+
+func (m *accountMap) put(account *Account) {
+	m.shard(account.accountID)[account.accountID] = account
+}
+
+func (m *accountMap) delete(accountID string) {
+	delete(m.shard(accountID), accountID)
+}
+
+func (m *accountMap) len() int {
+	n := 0
+	for _, shard := range m.shards {
+		n += len(shard)
+	}
+	return n
+}
+
+// all yields every account, shard by shard, in no particular order.
+func (m *accountMap) all() iter.Seq2[string, *Account] {
+	return func(yield func(string, *Account) bool) {
+		for _, shard := range m.shards {
+			for accountID, account := range shard {
+				if !yield(accountID, account) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package bankingsystem
+
+import (
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountMap(t *testing.T) {
+	for _, shards := range []int{0, 1, 16} {
+		t.Run(fmt.Sprintf("%d Shards", shards), func(t *testing.T) {
+			// ARRANGE
+			m := newAccountMap(shards)
+			for i := 0; i < 100; i++ {
+				m.put(&Account{accountID: strconv.Itoa(i), balance: float64(i)})
+			}
+
+			// ACT
+			m.delete("7")
+			m.put(&Account{accountID: "8", balance: 800})
+			seen := 0
+			for range m.all() {
+				seen++
+			}
+
+			// ASSERT
+			assert.Equal(t, 99, m.len(), "length mismatch")
+			assert.Equal(t, 99, seen, "iteration should visit every account")
+			_, exists := m.lookup("7")
+			assert.False(t, exists, "deleted account should be gone")
+			assert.Equal(t, float64(800), m.get("8").balance, "put should replace the account")
+			assert.Nil(t, m.get("missing"), "missing accounts should be nil")
+		})
+	}
+
+	t.Run("Keeps The Store Behaviour", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithAccountShards(4))
+		for i := 0; i < 20; i++ {
+			store.CreateAccount(1, strconv.Itoa(i), 10)
+		}
+
+		// ACT
+		store.MergeAccounts(2, "3", "4")
+		var accountIDs []string
+		store.ForEachAccount(func(account AccountSnapshot) bool {
+			accountIDs = append(accountIDs, account.AccountID)
+			return true
+		})
+
+		// ASSERT
+		assert.Len(t, accountIDs, 19, "merged account should be removed")
+		merged, _ := store.GetAccount("4")
+		assert.Equal(t, float64(20), merged.Balance, "merge should combine the balances")
+	})
+}
+
+const benchmarkAccounts = 100_000
+
+var benchmarkShards = []int{1, 16, 64}
+
+func newBenchmarkAccountMap(shards int) *accountMap {
+	m := newAccountMap(shards)
+	for i := 0; i < benchmarkAccounts; i++ {
+		m.put(&Account{accountID: "account-" + strconv.Itoa(i)})
+	}
+	return m
+}
+
+func BenchmarkAccountMapLookup(b *testing.B) {
+	for _, shards := range benchmarkShards {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			m := newBenchmarkAccountMap(shards)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.lookup("account-" + strconv.Itoa(i%benchmarkAccounts))
+			}
+		})
+	}
+}
+
+func BenchmarkAccountMapInsert(b *testing.B) {
+	for _, shards := range benchmarkShards {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			m := newAccountMap(shards)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.put(&Account{accountID: "account-" + strconv.Itoa(i)})
+			}
+		})
+	}
+}
+
+func BenchmarkAccountMapMixed(b *testing.B) {
+	for _, shards := range benchmarkShards {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			m := newBenchmarkAccountMap(shards)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				accountID := "account-" + strconv.Itoa(i%benchmarkAccounts)
+				if i%10 == 0 {
+					m.put(&Account{accountID: accountID})
+				} else {
+					m.lookup(accountID)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkStoreGetAccount(b *testing.B) {
+	for _, shards := range benchmarkShards {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			store := NewAccountStore(WithAccountShards(shards))
+			for i := 0; i < benchmarkAccounts; i++ {
+				store.CreateAccount(1, "account-"+strconv.Itoa(i), 100)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					store.GetAccount("account-" + strconv.Itoa(i%benchmarkAccounts))
+					i++
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkStoreCreateAccount(b *testing.B) {
+	for _, shards := range benchmarkShards {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			store := NewAccountStore(WithAccountShards(shards))
+			var next atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					store.CreateAccount(1, "account-"+strconv.FormatInt(next.Add(1), 10), 100)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkStoreTransfer(b *testing.B) {
+	for _, shards := range benchmarkShards {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			store := NewAccountStore(WithAccountShards(shards))
+			for i := 0; i < benchmarkAccounts; i++ {
+				store.CreateAccount(1, "account-"+strconv.Itoa(i), 1_000_000)
+			}
+			var next atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					i := int(next.Add(2))
+					from := "account-" + strconv.Itoa(i%benchmarkAccounts)
+					to := "account-" + strconv.Itoa((i+1)%benchmarkAccounts)
+					store.Transfer(2, from, to, 1)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrAmountBelowMinimum is returned when a transfer or payment is smaller than its AmountPolicy
+// allows. Amounts above the policy's maximum return ErrTransferLimitExceeded.
+var ErrAmountBelowMinimum = errors.New("amount is below the minimum")
+
+// AmountPolicy bounds the amount of each transfer and scheduled payment. A zero field means no
+// bound.
+type AmountPolicy struct {
+	MinAmount float64
+	MaxAmount float64
+}
+
+// SetAmountPolicy sets the policy for accounts of tenantID. The policy set for the empty tenant
+// ID applies to accounts without a tenant and to tenants without a policy.
+func (s *AccountStore) SetAmountPolicy(tenantID string, policy AmountPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSetAmountPolicy, TenantID: tenantID, AmountPolicy: &policy})
+
+	s.amountPolicies[tenantID] = policy
+}
+
+// SetTierAmountPolicy sets the policy for accounts in tier, which is an account tag set with
+// SetAccountTags. A tier policy takes precedence over the account's tenant policy; an account
+// in several tiers with a policy uses the first tier in tag order.
+func (s *AccountStore) SetTierAmountPolicy(tier string, policy AmountPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSetTierAmountPolicy, Name: tier, AmountPolicy: &policy})
+
+	s.tierPolicies[tier] = policy
+}
+
+// amountPolicy looks up the policy for account: its tier, then its tenant, then the default.
+// Callers must hold the lock.
+func (s *AccountStore) amountPolicy(account *Account) (AmountPolicy, string) {
+	var tiers []string
+	for tag := range s.accountTags[account.accountID] {
+		if _, exists := s.tierPolicies[tag]; exists {
+			tiers = append(tiers, tag)
+		}
+	}
+	if len(tiers) > 0 {
+		sort.Strings(tiers)
+		return s.tierPolicies[tiers[0]], "tier " + tiers[0]
+	}
+	if policy, exists := s.amountPolicies[account.tenantID]; exists && account.tenantID != "" {
+		return policy, "tenant " + account.tenantID
+	}
+	return s.amountPolicies[""], "default"
+}
+
+func (s *AccountStore) checkAmountPolicy(account *Account, amount float64) error {
+	policy, source := s.amountPolicy(account)
+	if policy.MinAmount > 0 && amount < policy.MinAmount {
+		message := fmt.Sprintf("%s of %v for %s", ErrAmountBelowMinimum, policy.MinAmount, source)
+		return newError(ErrAmountBelowMinimum, message, map[string]any{ParamAmount: amount, ParamMinimum: policy.MinAmount})
+	}
+	if policy.MaxAmount > 0 && amount > policy.MaxAmount {
+		message := fmt.Sprintf("%s of %v for %s", ErrTransferLimitExceeded, policy.MaxAmount, source)
+		return newError(ErrTransferLimitExceeded, message, map[string]any{ParamAmount: amount, ParamLimit: policy.MaxAmount})
+	}
+	return nil
+}
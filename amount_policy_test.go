@@ -0,0 +1,51 @@
+package bankingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAmountPolicy(t *testing.T) {
+	t.Run("Default Policy", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 2_000_000)
+		store.CreateAccount(1, "b", 0)
+		store.SetAmountPolicy("", AmountPolicy{MinAmount: 0.01, MaxAmount: 1_000_000})
+
+		// ACT
+		_, tooSmall := store.Transfer(2, "a", "b", 0.001)
+		_, tooLarge := store.Transfer(2, "a", "b", 1_500_000)
+		_, scheduleErr := store.SchedulePayment(2, "a", 0.001, 60)
+		_, ok := store.Transfer(2, "a", "b", 100)
+
+		// ASSERT
+		assert.ErrorIs(t, tooSmall, ErrAmountBelowMinimum, "expected minimum to apply")
+		assert.EqualError(t, tooSmall, "amount is below the minimum of 0.01 for default", "unexpected error message")
+		assert.ErrorIs(t, tooLarge, ErrTransferLimitExceeded, "expected maximum to apply")
+		assert.ErrorIs(t, scheduleErr, ErrAmountBelowMinimum, "scheduled payments should be checked too")
+		assert.NoError(t, ok, "amounts within the policy should go through")
+	})
+
+	t.Run("Tenant And Tier Lookup", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateTenantAccount(1, "acme", "basic", 10_000)
+		store.CreateTenantAccount(1, "acme", "premium", 10_000)
+		store.CreateTenantAccount(1, "globex", "other", 10_000)
+		store.SetAccountTags("premium", "tier-premium")
+		store.SetAmountPolicy("acme", AmountPolicy{MaxAmount: 100})
+		store.SetTierAmountPolicy("tier-premium", AmountPolicy{MaxAmount: 5000})
+
+		// ACT
+		_, basicErr := store.Transfer(2, "basic", "other", 500)
+		_, premiumErr := store.Transfer(2, "premium", "other", 500)
+		_, otherErr := store.Transfer(2, "other", "basic", 500)
+
+		// ASSERT
+		assert.EqualError(t, basicErr, "amount exceeds the transfer limit of 100 for tenant acme", "tenant policy should apply")
+		assert.NoError(t, premiumErr, "tier policy should take precedence")
+		assert.NoError(t, otherErr, "tenants without a policy are unbounded")
+	})
+}
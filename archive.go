@@ -0,0 +1,212 @@
+package bankingsystem
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"sort"
+	"sync"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// historyStart is the lower bound of a history range that covers every earlier event.
+const historyStart = math.MinInt
+
+// Archive is cold storage for events that have aged out of the hot store.
+type Archive interface {
+	// Append stores events. It is called with events in commit order.
+	Append(events []Event) error
+	// Range returns archived events whose timestamp falls within [from, to], in commit order.
+	Range(from, to int) ([]Event, error)
+}
+
+// EnableArchival configures where ArchiveTransactions moves events and how many days of
+// history stay in the hot store.
+func (s *AccountStore) EnableArchival(archive Archive, retentionDays int) error {
+	if err := checkArchival(archive, retentionDays); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceUnreplayable, Name: "EnableArchival"})
+
+	s.archive = archive
+	s.retentionDays = retentionDays
+	return nil
+}
+
+func checkArchival(archive Archive, retentionDays int) error {
+	if archive == nil {
+		return errors.New("archive is required")
+	}
+	if retentionDays < 0 {
+		return errors.New("retention days must not be negative")
+	}
+	return nil
+}
+
+// ArchiveTransactions moves every event older than the retention window ending at now into
+// the archive and returns how many events were moved.
+func (s *AccountStore) ArchiveTransactions(now int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceArchiveTransactions, Timestamp: now})
+
+	if s.archive == nil {
+		return 0, errors.New("archival is not enabled")
+	}
+
+	cutoff := now - s.retentionDays*secondsPerDay
+	var archived, kept []Event
+	for _, event := range s.events {
+		if event.Timestamp < cutoff {
+			archived = append(archived, event)
+		} else {
+			kept = append(kept, event)
+		}
+	}
+	if len(archived) == 0 {
+		return 0, nil
+	}
+
+	if err := s.archive.Append(archived); err != nil {
+		return 0, err
+	}
+	s.events = kept
+	s.archivedEvents += len(archived)
+	return len(archived), nil
+}
+
+// Transactions returns every event whose timestamp falls within [from, to], in commit order,
+// reading archived ranges as needed.
+func (s *AccountStore) Transactions(from, to int) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.history(from, to)
+}
+
+// TransactionCount returns the number of events ever committed, archived or not.
+func (s *AccountStore) TransactionCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.archivedEvents + len(s.events)
+}
+
+// history merges archived and hot events within [from, to]. Callers must hold the lock.
+func (s *AccountStore) history(from, to int) ([]Event, error) {
+	var events []Event
+	if s.archive != nil && s.archivedEvents > 0 {
+		archived, err := s.archive.Range(from, to)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, archived...)
+	}
+	for _, event := range s.events {
+		if event.Timestamp >= from && event.Timestamp <= to {
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Seq < events[j].Seq
+	})
+	return events, nil
+}
+
+// MemoryArchive keeps archived events in memory. It is mainly useful for tests.
+type MemoryArchive struct {
+	mu     sync.RWMutex
+	events []Event
+}
+
+func NewMemoryArchive() *MemoryArchive {
+	return &MemoryArchive{}
+}
+
+func (a *MemoryArchive) Append(events []Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.events = append(a.events, events...)
+	return nil
+}
+
+func (a *MemoryArchive) Range(from, to int) ([]Event, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	var events []Event
+	for _, event := range a.events {
+		if event.Timestamp >= from && event.Timestamp <= to {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// FileArchive appends archived events to a file as JSON lines.
+type FileArchive struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileArchive(path string) *FileArchive {
+	return &FileArchive{path: path}
+}
+
+func (a *FileArchive) Append(events []Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (a *FileArchive) Range(from, to int) ([]Event, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	file, err := os.Open(a.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var events []Event
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		var event Event
+		if err := decoder.Decode(&event); err != nil {
+			return nil, err
+		}
+		if event.Timestamp >= from && event.Timestamp <= to {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
@@ -0,0 +1,81 @@
+package bankingsystem
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArchiveTransactions(t *testing.T) {
+	archives := map[string]func(t *testing.T) Archive{
+		"Memory Archive": func(t *testing.T) Archive {
+			return NewMemoryArchive()
+		},
+		"File Archive": func(t *testing.T) Archive {
+			return NewFileArchive(filepath.Join(t.TempDir(), "archive.jsonl"))
+		},
+	}
+
+	for name, newArchive := range archives {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE
+			store := NewAccountStore()
+			archive := newArchive(t)
+			assert.NoError(t, store.EnableArchival(archive, 30), "unexpected error enabling archival")
+
+			fromID := randomAccountID()
+			toID := randomAccountID()
+			day := secondsPerDay
+			store.CreateAccount(1*day, fromID, 1000)
+			store.CreateAccount(1*day, toID, 1000)
+			store.Transfer(2*day, fromID, toID, 100)
+			store.Transfer(40*day, fromID, toID, 50)
+
+			// ACT
+			moved, err := store.ArchiveTransactions(45 * day)
+
+			// ASSERT
+			assert.NoError(t, err, "unexpected error during archival")
+			assert.Equal(t, 3, moved, "expected events older than 30 days to be archived")
+			assert.Len(t, store.events, 1, "expected one event left in the hot store")
+			assert.Equal(t, 4, store.TransactionCount(), "transaction count should include archived events")
+
+			events, err := store.Transactions(2*day, 40*day)
+			assert.NoError(t, err, "unexpected error reading transactions")
+			assert.Len(t, events, 2, "expected archived and hot transactions in range")
+			assert.Equal(t, 3, events[0].Seq, "expected archived transaction first")
+			assert.Equal(t, 4, events[1].Seq, "expected hot transaction second")
+
+			view, err := store.StateAt(45 * day)
+			assert.NoError(t, err, "unexpected error materializing view")
+			from, _ := view.Account(fromID)
+			assert.Equal(t, store.accounts.get(fromID).snapshot(), from, "view should replay archived history")
+		})
+	}
+
+	t.Run("Not Enabled", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+
+		// ACT
+		_, err := store.ArchiveTransactions(100)
+
+		// ASSERT
+		assert.Error(t, err, "expected error when archival is not enabled")
+	})
+
+	t.Run("Invalid Archive Options Leave Archival Disabled", func(t *testing.T) {
+		// ARRANGE
+		withoutArchive := NewAccountStore(WithArchive(nil, 1))
+		negativeRetention := NewAccountStore(WithArchive(NewMemoryArchive(), -1))
+
+		// ACT
+		_, withoutArchiveErr := withoutArchive.ArchiveTransactions(100)
+		_, negativeRetentionErr := negativeRetention.ArchiveTransactions(100)
+
+		// ASSERT
+		assert.EqualError(t, withoutArchiveErr, "archival is not enabled", "unexpected error message")
+		assert.EqualError(t, negativeRetentionErr, "archival is not enabled", "unexpected error message")
+	})
+}
@@ -0,0 +1,120 @@
+package bankingsystem
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Signer signs attestations. Use HMACSigner for a shared secret, or plug in a KMS or
+// asymmetric key.
+type Signer interface {
+	Sign(payload []byte) ([]byte, error)
+	Verify(payload, signature []byte) error
+}
+
+// HMACSigner signs with HMAC-SHA256 under Key.
+type HMACSigner struct {
+	Key []byte
+}
+
+func (h HMACSigner) Sign(payload []byte) ([]byte, error) {
+	if len(h.Key) == 0 {
+		return nil, errors.New("signing key is required")
+	}
+	mac := hmac.New(sha256.New, h.Key)
+	mac.Write(payload)
+	return mac.Sum(nil), nil
+}
+
+func (h HMACSigner) Verify(payload, signature []byte) error {
+	expected, err := h.Sign(payload)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, signature) {
+		return errors.New("attestation signature mismatch")
+	}
+	return nil
+}
+
+// Attestation certifies the store's totals at AsOf. Digest is a SHA-256 over every account's
+// state, so a later check catches offsetting changes that leave the totals intact.
+type Attestation struct {
+	AsOf             int
+	AccountCount     int
+	TotalBalance     float64
+	TotalTransferred float64
+	Digest           string
+	Signature        []byte
+}
+
+// GenerateAttestation summarizes the store as of the given timestamp, replaying history the
+// same way StateAt does, and signs the summary.
+func (s *AccountStore) GenerateAttestation(asOf int, signer Signer) (*Attestation, error) {
+	view, err := s.StateAt(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	attestation := summarize(view)
+	attestation.Signature, err = signer.Sign([]byte(attestation.payload()))
+	if err != nil {
+		return nil, err
+	}
+	return attestation, nil
+}
+
+// VerifyAttestation checks the attestation's signature and that the store's history still
+// produces the same summary.
+func (s *AccountStore) VerifyAttestation(attestation *Attestation, signer Signer) error {
+	if err := attestation.Verify(signer); err != nil {
+		return err
+	}
+
+	view, err := s.StateAt(attestation.AsOf)
+	if err != nil {
+		return err
+	}
+	if summarize(view).payload() != attestation.payload() {
+		return fmt.Errorf("attestation as of %d no longer matches the store's history", attestation.AsOf)
+	}
+	return nil
+}
+
+// Verify checks that the attestation was signed by signer and has not been altered.
+func (a *Attestation) Verify(signer Signer) error {
+	return signer.Verify([]byte(a.payload()), a.Signature)
+}
+
+func summarize(view *StoreView) *Attestation {
+	attestation := &Attestation{AsOf: view.AsOf()}
+	digest := sha256.New()
+	for _, account := range view.Accounts() {
+		attestation.AccountCount++
+		attestation.TotalBalance += account.Balance
+		attestation.TotalTransferred += account.TotalTransferred
+		fmt.Fprintf(digest, "%s|%s|%s|%d|%s|%s\n",
+			account.AccountID,
+			account.TenantID,
+			account.Currency,
+			account.UpdatedAt,
+			strconv.FormatFloat(account.Balance, 'g', -1, 64),
+			strconv.FormatFloat(account.TotalTransferred, 'g', -1, 64))
+	}
+	attestation.Digest = hex.EncodeToString(digest.Sum(nil))
+	return attestation
+}
+
+// payload is the canonical text that gets signed.
+func (a *Attestation) payload() string {
+	return fmt.Sprintf("as_of=%d accounts=%d balance=%s transferred=%s digest=%s",
+		a.AsOf,
+		a.AccountCount,
+		strconv.FormatFloat(a.TotalBalance, 'g', -1, 64),
+		strconv.FormatFloat(a.TotalTransferred, 'g', -1, 64),
+		a.Digest)
+}
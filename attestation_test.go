@@ -0,0 +1,62 @@
+package bankingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateAttestation(t *testing.T) {
+	signer := HMACSigner{Key: []byte("audit-key")}
+
+	t.Run("Summarizes And Verifies", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 500)
+		store.Transfer(5, "a", "b", 200)
+		store.CreateAccount(20, "c", 50)
+
+		// ACT
+		attestation, err := store.GenerateAttestation(10, signer)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error generating attestation")
+		assert.Equal(t, 10, attestation.AsOf, "asOf mismatch")
+		assert.Equal(t, 2, attestation.AccountCount, "account count mismatch")
+		assert.Equal(t, float64(1500), attestation.TotalBalance, "total balance mismatch")
+		assert.Equal(t, float64(200), attestation.TotalTransferred, "total transferred mismatch")
+		assert.NoError(t, store.VerifyAttestation(attestation, signer), "expected attestation to verify")
+	})
+
+	t.Run("Detects Tampering", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		attestation, _ := store.GenerateAttestation(10, signer)
+
+		// ACT
+		attestation.TotalBalance = 2000
+		tampered := attestation.Verify(signer)
+		wrongKey := (&Attestation{AsOf: 10}).Verify(HMACSigner{Key: []byte("other")})
+
+		// ASSERT
+		assert.EqualError(t, tampered, "attestation signature mismatch", "unexpected error message")
+		assert.EqualError(t, wrongKey, "attestation signature mismatch", "unexpected error message")
+	})
+
+	t.Run("Detects Changed History", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		attestation, _ := store.GenerateAttestation(10, signer)
+		other := NewAccountStore()
+		other.CreateAccount(1, "a", 900)
+
+		// ACT
+		err := other.VerifyAttestation(attestation, signer)
+
+		// ASSERT
+		assert.EqualError(t, err, "attestation as of 10 no longer matches the store's history", "unexpected error message")
+	})
+}
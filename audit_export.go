@@ -0,0 +1,306 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditEntry is one committed operation, as exported to a security information and event
+// management (SIEM) system. Export is at-least-once, so collectors should drop entries whose
+// Seq they have already seen. It carries every field of the Event it comes from, with Type
+// renamed Operation.
+type AuditEntry struct {
+	Seq            int
+	Timestamp      int
+	Operation      EventType
+	AccountID      string
+	CounterpartyID string       `json:",omitempty"`
+	TenantID       string       `json:",omitempty"`
+	Amount         float64      `json:",omitempty"`
+	Currency       string       `json:",omitempty"`
+	SourceIDs      []string     `json:",omitempty"`
+	State          AccountState `json:",omitempty"`
+	Reason         ReasonCode   `json:",omitempty"`
+	Actor          string       `json:",omitempty"`
+	ValueTimestamp int          `json:",omitempty"`
+	CreditedAmount float64      `json:",omitempty"`
+}
+
+func auditEntryFor(event Event) AuditEntry {
+	return AuditEntry{
+		Seq:            event.Seq,
+		Timestamp:      event.Timestamp,
+		Operation:      event.Type,
+		AccountID:      event.AccountID,
+		CounterpartyID: event.CounterpartyID,
+		TenantID:       event.TenantID,
+		Amount:         event.Amount,
+		Currency:       event.Currency,
+		SourceIDs:      event.SourceIDs,
+		State:          event.State,
+		Reason:         event.Reason,
+		Actor:          event.Actor,
+		ValueTimestamp: event.ValueTimestamp,
+		CreditedAmount: event.CreditedAmount,
+	}
+}
+
+// AuditSink receives batches of audit entries. Send must not report success until the whole
+// batch is accepted.
+type AuditSink interface {
+	Send(ctx context.Context, entries []AuditEntry) error
+}
+
+// AuditCheckpoint remembers how far an export got, as a resume token of StreamTransactions, so
+// that a restarted export continues where the last one stopped. An empty cursor means nothing
+// was exported yet.
+type AuditCheckpoint interface {
+	Load() (string, error)
+	Save(cursor string) error
+}
+
+// AuditExportOptions configures ExportAudit. Zero fields take the defaults below.
+type AuditExportOptions struct {
+	// BatchSize is the most entries sent at once. The default is 100.
+	BatchSize int
+	// FlushInterval is the longest an entry waits for its batch to fill. The default is one
+	// second.
+	FlushInterval time.Duration
+	// Retries governs resending a batch the sink failed to accept.
+	Retries RetryPolicy
+}
+
+// ExportAudit forwards every committed operation to sink until ctx is done, starting after
+// the cursor in checkpoint, or with the whole history if it is empty. Entries are buffered into
+// batches, and the checkpoint only advances once the sink accepts a batch, so entries may be
+// sent again after a failure or restart but are never skipped. It returns ctx's error when ctx
+// is done, the sink's error once a batch still fails after the retries, or ErrStreamOverflowed
+// if the sink fell too far behind, and can then be called again to resume.
+func (s *AccountStore) ExportAudit(ctx context.Context, sink AuditSink, checkpoint AuditCheckpoint, options AuditExportOptions) error {
+	if options.BatchSize <= 0 {
+		options.BatchSize = 100
+	}
+	if options.FlushInterval <= 0 {
+		options.FlushInterval = time.Second
+	}
+	cursor, err := checkpoint.Load()
+	if err != nil {
+		return fmt.Errorf("loading audit checkpoint: %w", err)
+	}
+	if cursor == "" {
+		cursor = StartOfHistoryToken()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := s.StreamTransactions(ctx, TransactionFilter{}, cursor)
+	if err != nil {
+		return err
+	}
+
+	var batch []AuditEntry
+	var flushTimer Timer
+	flushDue := make(chan struct{}, 1)
+	flush := func() error {
+		if flushTimer != nil {
+			flushTimer.Stop()
+			flushTimer = nil
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := s.sendAudit(ctx, sink, batch, options.Retries); err != nil {
+			return err
+		}
+		batch = nil
+		return checkpoint.Save(cursor)
+	}
+
+	for {
+		select {
+		case transaction, ok := <-stream:
+			if !ok {
+				return ctx.Err()
+			}
+			if transaction.Err != nil {
+				if err := flush(); err != nil {
+					return err
+				}
+				return transaction.Err
+			}
+			batch = append(batch, auditEntryFor(transaction.Event))
+			cursor = transaction.ResumeToken
+			if len(batch) >= options.BatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			} else if flushTimer == nil {
+				flushTimer = s.clock.AfterFunc(options.FlushInterval, func() {
+					select {
+					case flushDue <- struct{}{}:
+					default:
+					}
+				})
+			}
+		case <-flushDue:
+			if err := flush(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			if flushTimer != nil {
+				flushTimer.Stop()
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *AccountStore) sendAudit(ctx context.Context, sink AuditSink, batch []AuditEntry, retries RetryPolicy) error {
+	var err error
+	for attempt := 0; attempt <= retries.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if sleepErr := sleepContext(ctx, s.clock, retries.Backoff); sleepErr != nil {
+				return sleepErr
+			}
+		}
+		if err = sink.Send(ctx, batch); err == nil {
+			return nil
+		}
+		s.logger.Warn("sending audit entries", "entries", len(batch), "attempt", attempt+1, "error", err)
+	}
+	return fmt.Errorf("sending audit entries: %w", err)
+}
+
+// HTTPAuditSink posts each batch to URL as a JSON array. Any response other than 2xx fails the
+// batch.
+type HTTPAuditSink struct {
+	URL string
+	// Client defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+func (h HTTPAuditSink) Send(ctx context.Context, entries []AuditEntry) error {
+	payload, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	client := h.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("audit collector responded %s", response.Status)
+	}
+	return nil
+}
+
+// SyslogAuditSink sends each entry as an RFC 5424 message with a JSON body, at the
+// informational severity of the log audit facility. Over TCP messages are separated by
+// newlines; over UDP each message is its own datagram.
+type SyslogAuditSink struct {
+	// Network is "tcp" or "udp".
+	Network string
+	Address string
+	// AppName identifies the store in each message. The default is "bankingsystem".
+	AppName string
+}
+
+// syslogPriority is the log audit facility (13) at the informational severity (6).
+const syslogPriority = 13*8 + 6
+
+func (sl SyslogAuditSink) Send(ctx context.Context, entries []AuditEntry) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, sl.Network, sl.Address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	appName := sl.AppName
+	if appName == "" {
+		appName = "bankingsystem"
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	for _, entry := range entries {
+		body, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		timestamp := time.Unix(int64(entry.Timestamp), 0).UTC().Format(time.RFC3339)
+		message := fmt.Sprintf("<%d>1 %s %s %s - %s - %s", syslogPriority, timestamp, hostname, appName, entry.Operation, body)
+		if !strings.HasPrefix(sl.Network, "udp") {
+			message += "\n"
+		}
+		if _, err := conn.Write([]byte(message)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileAuditCheckpoint keeps the cursor in the file at Path, replacing it atomically on every
+// save.
+type FileAuditCheckpoint struct {
+	Path string
+}
+
+func (f FileAuditCheckpoint) Load() (string, error) {
+	contents, err := os.ReadFile(f.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	}
+	return strings.TrimSpace(string(contents)), err
+}
+
+func (f FileAuditCheckpoint) Save(cursor string) error {
+	return writeFileAtomically(f.Path, func(file *os.File) error {
+		_, err := file.WriteString(cursor + "\n")
+		return err
+	})
+}
+
+// MemoryAuditCheckpoint keeps the cursor in memory. It is mainly useful for tests.
+type MemoryAuditCheckpoint struct {
+	mu     sync.Mutex
+	cursor string
+}
+
+func (m *MemoryAuditCheckpoint) Load() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.cursor, nil
+}
+
+func (m *MemoryAuditCheckpoint) Save(cursor string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cursor = cursor
+	return nil
+}
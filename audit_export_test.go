@@ -0,0 +1,192 @@
+package bankingsystem
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingAuditSink collects the entries it accepts, failing the first failures sends.
+type recordingAuditSink struct {
+	mu       sync.Mutex
+	batches  [][]AuditEntry
+	failures int
+}
+
+func (r *recordingAuditSink) Send(ctx context.Context, entries []AuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures > 0 {
+		r.failures--
+		return errors.New("collector unavailable")
+	}
+	r.batches = append(r.batches, append([]AuditEntry(nil), entries...))
+	return nil
+}
+
+func (r *recordingAuditSink) seqs() []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var seqs []int
+	for _, batch := range r.batches {
+		for _, entry := range batch {
+			seqs = append(seqs, entry.Seq)
+		}
+	}
+	return seqs
+}
+
+func TestExportAudit(t *testing.T) {
+	newStore := func() *AccountStore {
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 0)
+		store.Transfer(20, "a", "b", 40)
+		return store
+	}
+
+	t.Run("Exports In Batches And Checkpoints", func(t *testing.T) {
+		// ARRANGE
+		store := newStore()
+		sink := &recordingAuditSink{}
+		checkpoint := &MemoryAuditCheckpoint{}
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+
+		// ACT
+		go func() {
+			done <- store.ExportAudit(ctx, sink, checkpoint, AuditExportOptions{BatchSize: 2, FlushInterval: 10 * time.Millisecond})
+		}()
+		store.Transfer(30, "b", "a", 10)
+
+		// ASSERT
+		assert.Eventually(t, func() bool { return len(sink.seqs()) == 4 }, time.Second, time.Millisecond, "every operation should be exported")
+		assert.Eventually(t, func() bool {
+			cursor, _ := checkpoint.Load()
+			return cursor == encodeResumeToken(4)
+		}, time.Second, time.Millisecond, "checkpoint should follow the last accepted batch")
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled, "export should stop with the context")
+		assert.Equal(t, []int{1, 2, 3, 4}, sink.seqs(), "entries should be exported in order")
+		assert.Equal(t, AuditEntry{Seq: 3, Timestamp: 20, Operation: EventTransfer, AccountID: "a", CounterpartyID: "b", Amount: 40}, sink.batches[1][0], "entry mismatch")
+	})
+
+	t.Run("Exports Every Event Field", func(t *testing.T) {
+		// ARRANGE
+		store := newStore()
+		store.CreateAccount(20, "c", 5)
+		store.MergeAccountsMany(30, []string{"b", "c"}, "a")
+		sink := &recordingAuditSink{}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// ACT
+		go store.ExportAudit(ctx, sink, &MemoryAuditCheckpoint{}, AuditExportOptions{FlushInterval: time.Millisecond})
+
+		// ASSERT
+		assert.Eventually(t, func() bool { return len(sink.seqs()) == 5 }, time.Second, time.Millisecond, "every operation should be exported")
+		sink.mu.Lock()
+		defer sink.mu.Unlock()
+		last := sink.batches[len(sink.batches)-1]
+		assert.Equal(t, AuditEntry{Seq: 5, Timestamp: 30, Operation: EventAccountsMergedMany, CounterpartyID: "a", Amount: 45, SourceIDs: []string{"b", "c"}}, last[len(last)-1], "entry mismatch")
+	})
+
+	t.Run("Resumes From The Checkpoint", func(t *testing.T) {
+		// ARRANGE
+		store := newStore()
+		checkpoint := &MemoryAuditCheckpoint{}
+		checkpoint.Save(encodeResumeToken(2))
+		sink := &recordingAuditSink{}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// ACT
+		go store.ExportAudit(ctx, sink, checkpoint, AuditExportOptions{FlushInterval: time.Millisecond})
+
+		// ASSERT
+		assert.Eventually(t, func() bool { return len(sink.seqs()) == 1 }, time.Second, time.Millisecond, "only later operations should be exported")
+		assert.Equal(t, []int{3}, sink.seqs(), "export should resume after the checkpoint")
+	})
+
+	t.Run("Keeps The Checkpoint When The Sink Keeps Failing", func(t *testing.T) {
+		// ARRANGE
+		store := newStore()
+		checkpoint := &MemoryAuditCheckpoint{}
+		failing := &recordingAuditSink{failures: 2}
+
+		// ACT
+		err := store.ExportAudit(context.Background(), failing, checkpoint, AuditExportOptions{BatchSize: 3, Retries: RetryPolicy{MaxRetries: 1}})
+		cursor, _ := checkpoint.Load()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		recovered := &recordingAuditSink{}
+		go store.ExportAudit(ctx, recovered, checkpoint, AuditExportOptions{BatchSize: 3})
+
+		// ASSERT
+		assert.Error(t, err, "export should fail once retries run out")
+		assert.Empty(t, cursor, "failed batches should not advance the checkpoint")
+		assert.Eventually(t, func() bool { return len(recovered.seqs()) == 3 }, time.Second, time.Millisecond, "failed entries should be sent again")
+	})
+}
+
+func TestHTTPAuditSink(t *testing.T) {
+	// ARRANGE
+	var received []AuditEntry
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	sink := HTTPAuditSink{URL: server.URL}
+
+	// ACT
+	err := sink.Send(context.Background(), []AuditEntry{{Seq: 1, Operation: EventAccountCreated, AccountID: "a"}})
+
+	// ASSERT
+	assert.NoError(t, err, "unexpected error sending entries")
+	assert.Equal(t, []AuditEntry{{Seq: 1, Operation: EventAccountCreated, AccountID: "a"}}, received, "entries mismatch")
+}
+
+func TestSyslogAuditSink(t *testing.T) {
+	// ARRANGE
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err, "unexpected error listening")
+	defer listener.Close()
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	sink := SyslogAuditSink{Network: "tcp", Address: listener.Addr().String(), AppName: "bank"}
+
+	// ACT
+	err = sink.Send(context.Background(), []AuditEntry{
+		{Seq: 1, Timestamp: 0, Operation: EventAccountCreated, AccountID: "a"},
+		{Seq: 2, Timestamp: 60, Operation: EventTransfer, AccountID: "a", CounterpartyID: "b", Amount: 5},
+	})
+
+	// ASSERT
+	assert.NoError(t, err, "unexpected error sending entries")
+	first := <-lines
+	second := <-lines
+	assert.Regexp(t, `^<110>1 1970-01-01T00:00:00Z \S+ bank - account_created - \{"Seq":1,`, first, "first message mismatch")
+	assert.Regexp(t, `^<110>1 1970-01-01T00:01:00Z \S+ bank - transfer - .*"Amount":5\}$`, second, "second message mismatch")
+}
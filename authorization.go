@@ -0,0 +1,137 @@
+package bankingsystem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrForbidden is returned when the Authorizer denies an operation.
+var ErrForbidden = errors.New("operation not authorized")
+
+// Principal is who is asking for an operation, as established by whatever authenticates
+// requests in front of the store.
+type Principal struct {
+	ID    string
+	Roles []string
+}
+
+// Operation is what a principal asks to do.
+type Operation string
+
+const (
+	OperationCreateAccount   Operation = "create_account"
+	OperationReadAccount     Operation = "read_account"
+	OperationTransfer        Operation = "transfer"
+	OperationSchedulePayment Operation = "schedule_payment"
+	OperationCancelPayment   Operation = "cancel_payment"
+	OperationMergeAccounts   Operation = "merge_accounts"
+	OperationQuoteTransfer   Operation = "quote_transfer"
+)
+
+// Resource is what an operation acts on. AccountID is the account acted on, the source of
+// transfers and merges, whose destination is CounterpartyID. PaymentID is set for operations
+// on scheduled payments and Amount for operations that move money. Fields that do not apply
+// are left zero.
+type Resource struct {
+	AccountID      string
+	CounterpartyID string
+	PaymentID      string
+	Amount         float64
+}
+
+// Authorizer decides whether principal may perform operation on resource. It returns nil to
+// allow the operation and an error to deny it, preferably one matching ErrForbidden. Errors
+// that do not, such as a policy engine being unreachable, deny the operation too and are
+// reported as internal errors. ctx is the context of the request, for policies that depend on
+// more than the principal and resource.
+type Authorizer interface {
+	Authorize(ctx context.Context, principal Principal, operation Operation, resource Resource) error
+}
+
+// AuthorizerFunc adapts a function to Authorizer.
+type AuthorizerFunc func(ctx context.Context, principal Principal, operation Operation, resource Resource) error
+
+func (f AuthorizerFunc) Authorize(ctx context.Context, principal Principal, operation Operation, resource Resource) error {
+	return f(ctx, principal, operation, resource)
+}
+
+// AllowAll is the default Authorizer. It permits every operation, leaving access control to
+// whatever sits in front of the store.
+var AllowAll Authorizer = AuthorizerFunc(func(context.Context, Principal, Operation, Resource) error {
+	return nil
+})
+
+// RoleAuthorizer is a built-in role-based Authorizer. It maps each role to the operations it
+// grants and permits an operation if any of the principal's roles grants it.
+type RoleAuthorizer map[string][]Operation
+
+func (r RoleAuthorizer) Authorize(ctx context.Context, principal Principal, operation Operation, resource Resource) error {
+	for _, role := range principal.Roles {
+		for _, granted := range r[role] {
+			if granted == operation {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: %s may not %s", ErrForbidden, principal.ID, operation)
+}
+
+// WithAuthorizer makes the store's front ends, such as NewHTTPHandler, ask authorizer before
+// every operation.
+func WithAuthorizer(authorizer Authorizer) Option {
+	return func(s *AccountStore) {
+		s.authorizer = authorizer
+	}
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying principal. Authentication middleware in
+// front of NewHTTPHandler uses it to tell the handler who made the request.
+func ContextWithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal carried by ctx, or the zero Principal if there is
+// none.
+func PrincipalFromContext(ctx context.Context) Principal {
+	principal, _ := ctx.Value(principalKey{}).(Principal)
+	return principal
+}
+
+// Authorize asks the store's Authorizer whether the principal carried by ctx may perform
+// operation on resource.
+func (s *AccountStore) Authorize(ctx context.Context, operation Operation, resource Resource) error {
+	principal := PrincipalFromContext(ctx)
+	if err := s.authorizer.Authorize(ctx, principal, operation, resource); err != nil {
+		s.logger.Info("denied operation", "principal", principal.ID, "operation", operation, "accountID", resource.AccountID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// quoteResource returns what executing the quote acts on, or the zero Resource if there is no
+// such quote.
+func (s *AccountStore) quoteResource(quoteID string) Resource {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	quote, exists := s.quotes[quoteID]
+	if !exists {
+		return Resource{}
+	}
+	return Resource{AccountID: quote.FromID, CounterpartyID: quote.ToID, Amount: quote.Amount}
+}
+
+// paymentAccount returns the account of the scheduled payment, or "" if there is no such
+// payment.
+func (s *AccountStore) paymentAccount(paymentID string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if payment, exists := s.scheduledPayments[paymentID]; exists {
+		return payment.accountID
+	}
+	return ""
+}
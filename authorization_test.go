@@ -0,0 +1,120 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthorization(t *testing.T) {
+	sendWithKey := func(handler http.Handler, principal Principal, method, path, idempotencyKey string, body any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		request := httptest.NewRequest(method, path, bytes.NewReader(encoded))
+		request = request.WithContext(ContextWithPrincipal(request.Context(), principal))
+		if idempotencyKey != "" {
+			request.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+	send := func(handler http.Handler, principal Principal, method, path string, body any) *httptest.ResponseRecorder {
+		return sendWithKey(handler, principal, method, path, "", body)
+	}
+
+	t.Run("Roles Grant Operations", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithAuthorizer(RoleAuthorizer{
+			"teller":  {OperationReadAccount, OperationTransfer},
+			"auditor": {OperationReadAccount},
+		}))
+		store.CreateAccount(1, "a", 100)
+		store.CreateAccount(1, "b", 0)
+		handler := NewHTTPHandler(store)
+		teller := Principal{ID: "alex", Roles: []string{"teller"}}
+		auditor := Principal{ID: "sam", Roles: []string{"auditor"}}
+
+		// ACT
+		allowed := send(handler, teller, http.MethodPost, "/transfers", TransferRequest{Timestamp: 2, FromID: "a", ToID: "b", Amount: 10})
+		denied := send(handler, auditor, http.MethodPost, "/transfers", TransferRequest{Timestamp: 2, FromID: "a", ToID: "b", Amount: 10})
+		read := send(handler, auditor, http.MethodGet, "/accounts/a", nil)
+
+		// ASSERT
+		assert.Equal(t, http.StatusNoContent, allowed.Code, "status mismatch")
+		assert.Equal(t, http.StatusForbidden, denied.Code, "status mismatch")
+		var apiError APIError
+		assert.NoError(t, json.Unmarshal(denied.Body.Bytes(), &apiError), "unexpected error decoding error")
+		assert.Equal(t, CodeForbidden, apiError.Code, "code mismatch")
+		assert.ErrorIs(t, apiError.Err(), ErrForbidden, "expected the sentinel to match")
+		assert.Equal(t, http.StatusOK, read.Code, "status mismatch")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(90), account.Balance, "only the allowed transfer should apply")
+	})
+
+	t.Run("Custom Authorizers See The Resource", func(t *testing.T) {
+		// ARRANGE
+		var seen []Resource
+		store := NewAccountStore(WithAuthorizer(AuthorizerFunc(func(ctx context.Context, principal Principal, operation Operation, resource Resource) error {
+			seen = append(seen, resource)
+			if operation == OperationCancelPayment && principal.ID != resource.AccountID {
+				return ErrForbidden
+			}
+			return nil
+		})))
+		store.CreateAccount(1, "a", 100)
+		paymentID, _ := store.SchedulePayment(1, "a", 10, 60)
+		handler := NewHTTPHandler(store)
+
+		// ACT
+		denied := send(handler, Principal{ID: "b"}, http.MethodDelete, "/payments/"+*paymentID, nil)
+		allowed := send(handler, Principal{ID: "a"}, http.MethodDelete, "/payments/"+*paymentID, nil)
+
+		// ASSERT
+		assert.Equal(t, http.StatusForbidden, denied.Code, "status mismatch")
+		assert.Equal(t, http.StatusNoContent, allowed.Code, "status mismatch")
+		assert.Equal(t, Resource{AccountID: "a", PaymentID: *paymentID}, seen[0], "resource mismatch")
+	})
+
+	t.Run("Policy Failures Deny", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithAuthorizer(AuthorizerFunc(func(context.Context, Principal, Operation, Resource) error {
+			return errors.New("policy engine unreachable")
+		})))
+		handler := NewHTTPHandler(store)
+
+		// ACT
+		response := send(handler, Principal{}, http.MethodPost, "/accounts", CreateAccountRequest{Timestamp: 1, AccountID: "a"})
+
+		// ASSERT
+		assert.Equal(t, http.StatusInternalServerError, response.Code, "status mismatch")
+		_, err := store.GetAccount("a")
+		assert.ErrorIs(t, err, ErrAccountNotFound, "the account should not be created")
+	})
+
+	t.Run("Replays Are Authorized", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithAuthorizer(RoleAuthorizer{"admin": {OperationCreateAccount}}))
+		handler := NewHTTPHandler(store)
+		admin := Principal{ID: "alex", Roles: []string{"admin"}}
+		create := CreateAccountRequest{Timestamp: 1, AccountID: "a", InitialBalance: 100}
+		created := sendWithKey(handler, admin, http.MethodPost, "/accounts", "key", create)
+
+		// ACT
+		otherPrincipal := sendWithKey(handler, Principal{ID: "sam"}, http.MethodPost, "/accounts", "key", create)
+		revokedRoles := sendWithKey(handler, Principal{ID: "alex"}, http.MethodPost, "/accounts", "key", create)
+		retry := sendWithKey(handler, admin, http.MethodPost, "/accounts", "key", create)
+
+		// ASSERT
+		assert.Equal(t, http.StatusCreated, created.Code, "status mismatch")
+		assert.Equal(t, http.StatusForbidden, otherPrincipal.Code, "another principal should not see the cached response")
+		assert.Equal(t, http.StatusForbidden, revokedRoles.Code, "replays should be authorized again")
+		assert.Equal(t, http.StatusCreated, retry.Code, "retry should replay the first response")
+		assert.Equal(t, created.Body.String(), retry.Body.String(), "body mismatch")
+	})
+}
@@ -0,0 +1,182 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrHoldNotFound is returned when releasing a hold that does not exist or was released.
+var ErrHoldNotFound = errors.New("hold not found")
+
+// Hold reserves part of an account's balance, for example for a card authorization, until it
+// is released.
+type Hold struct {
+	ID        string
+	AccountID string
+	Amount    float64
+	PlacedAt  int
+}
+
+// WithAvailabilityWindow makes payments scheduled to execute within window of an operation
+// count against the available balance, so that money already promised cannot be spent
+// elsewhere first. A zero window, the default, ignores pending payments.
+func WithAvailabilityWindow(window time.Duration) Option {
+	return func(s *AccountStore) {
+		s.availabilityWindow = window
+	}
+}
+
+// availableBalance is what the account can spend at timestamp: its booked balance plus its
+// overdraft limit, less holds, less its minimum balance, less pending payments due within the
+// availability window. except is left out of the pending payments, so a payment can be checked
+// against the balance it is itself counted in. Only the account's own pending payments are
+// looked at. Callers must hold the lock.
+func (s *AccountStore) availableBalance(account *Account, timestamp int, except *scheduledPayment) float64 {
+	available := account.balance + s.overdraftLimit(account.accountID) - account.held - account.minimumBalance
+	if s.availabilityWindow <= 0 {
+		return available
+	}
+
+	horizon := timestamp + int(s.availabilityWindow/time.Second)
+	for payment := range s.pendingByAccount[account.accountID] {
+		// Payments about to run together are flagged executed so they do not count against
+		// each other.
+		if payment != except && !payment.executed && payment.executeAt <= horizon {
+			available -= payment.amount
+		}
+	}
+	return available
+}
+
+// insufficientBalance reports that the account cannot spend amount out of available.
+func insufficientBalance(accountID string, amount, available float64) error {
+	return newError(ErrInsufficientBalance, "", map[string]any{ParamAccountID: accountID, ParamAmount: amount, ParamAvailable: available})
+}
+
+// liveSnapshot is the account's snapshot with AvailableBalance filled in as of the store's
+// clock. Callers must hold the lock.
+func (s *AccountStore) liveSnapshot(account *Account) AccountSnapshot {
+	snapshot := account.snapshot()
+	snapshot.AvailableBalance = s.availableBalance(account, int(s.clock.Now().Unix()), nil)
+	return snapshot
+}
+
+// PlaceHold reserves amount of the account's available balance and returns the hold's ID.
+func (s *AccountStore) PlaceHold(timestamp int, accountID string, amount float64) (string, error) {
+	if amount <= 0 {
+		return "", errors.New("hold amount must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TracePlaceHold, Timestamp: timestamp, AccountID: accountID, Amount: amount})
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists {
+		return "", ErrAccountNotFound
+	}
+	if err := checkOperation(account, opDebit); err != nil {
+		return "", err
+	}
+	if available := s.availableBalance(account, timestamp, nil); available < amount {
+		return "", insufficientBalance(accountID, amount, available)
+	}
+
+	seq, err := s.nextSequence(SequenceHold)
+	if err != nil {
+		return "", err
+	}
+	hold := &Hold{
+		ID:        fmt.Sprintf("hold-%d", seq),
+		AccountID: accountID,
+		Amount:    amount,
+		PlacedAt:  timestamp,
+	}
+	s.holds[hold.ID] = hold
+	account.held += amount
+	return hold.ID, nil
+}
+
+// ReleaseHold returns the held amount to the account's available balance.
+func (s *AccountStore) ReleaseHold(holdID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceReleaseHold, ID: holdID})
+
+	hold, exists := s.holds[holdID]
+	if !exists {
+		return ErrHoldNotFound
+	}
+	s.releaseHold(hold)
+	return nil
+}
+
+// releaseHold removes a hold. Callers must hold the write lock.
+func (s *AccountStore) releaseHold(hold *Hold) {
+	delete(s.holds, hold.ID)
+	if account, exists := s.accounts.lookup(hold.AccountID); exists {
+		account.held -= hold.Amount
+	}
+}
+
+// dropHolds forgets every hold on accountID, as when the account is replaced. Callers must hold
+// the write lock.
+func (s *AccountStore) dropHolds(accountID string) {
+	for id, hold := range s.holds {
+		if hold.AccountID == accountID {
+			delete(s.holds, id)
+		}
+	}
+}
+
+// Holds returns the account's active holds, oldest first.
+func (s *AccountStore) Holds(accountID string) []Hold {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var holds []Hold
+	for _, hold := range s.holds {
+		if hold.AccountID == accountID {
+			holds = append(holds, *hold)
+		}
+	}
+	sort.Slice(holds, func(i, j int) bool {
+		if holds[i].PlacedAt != holds[j].PlacedAt {
+			return holds[i].PlacedAt < holds[j].PlacedAt
+		}
+		return holds[i].ID < holds[j].ID
+	})
+	return holds
+}
+
+// SetMinimumBalance sets the balance the account must keep; spending below it is refused.
+func (s *AccountStore) SetMinimumBalance(accountID string, minimum float64) error {
+	if minimum < 0 {
+		return errors.New("minimum balance must not be negative")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSetMinimumBalance, AccountID: accountID, Amount: minimum})
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists {
+		return ErrAccountNotFound
+	}
+	account.minimumBalance = minimum
+	return nil
+}
+
+// moveHolds reassigns every hold on fromAccount to toAccount, as when merging. Callers must
+// hold the write lock.
+func (s *AccountStore) moveHolds(fromAccount, toAccount *Account) {
+	for _, hold := range s.holds {
+		if hold.AccountID == fromAccount.accountID {
+			hold.AccountID = toAccount.accountID
+		}
+	}
+	toAccount.held += fromAccount.held
+	fromAccount.held = 0
+}
@@ -0,0 +1,116 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAvailableBalance(t *testing.T) {
+	t.Run("Holds And Minimum Balance Limit Spending", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 0)
+		holdID, err := store.PlaceHold(2, "a", 300)
+		assert.NoError(t, err, "unexpected error placing hold")
+		assert.NoError(t, store.SetMinimumBalance("a", 200), "unexpected error setting minimum balance")
+
+		// ACT
+		_, overspent := store.Transfer(3, "a", "b", 600)
+		_, withinAvailable := store.Transfer(3, "a", "b", 500)
+
+		// ASSERT
+		assert.ErrorIs(t, overspent, ErrInsufficientBalance, "expected transfer above the available balance to fail")
+		assert.NoError(t, withinAvailable, "unexpected error during transfer")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(500), account.Balance, "booked balance mismatch")
+		assert.Equal(t, float64(0), account.AvailableBalance, "available balance mismatch")
+
+		assert.NoError(t, store.ReleaseHold(holdID), "unexpected error releasing hold")
+		account, _ = store.GetAccount("a")
+		assert.Equal(t, float64(300), account.AvailableBalance, "release should restore the available balance")
+		assert.ErrorIs(t, store.ReleaseHold(holdID), ErrHoldNotFound, "expected released hold to be gone")
+	})
+
+	t.Run("Pending Payments Within The Window", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		store := NewAccountStore(WithClock(clock), WithAvailabilityWindow(time.Hour))
+		store.CreateAccount(1000, "a", 1000)
+		store.CreateAccount(1000, "b", 0)
+		store.SchedulePayment(1000, "a", 700, 1800)
+		store.SchedulePayment(1000, "a", 200, 7200)
+
+		// ACT
+		_, err := store.Transfer(1000, "a", "b", 400)
+		clock.Advance(30 * time.Minute)
+
+		// ASSERT
+		assert.ErrorIs(t, err, ErrInsufficientBalance, "payment due within the window should be reserved")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(300), account.Balance, "the payment due first should execute")
+		assert.Equal(t, float64(300), account.AvailableBalance, "payment beyond the window should not count yet")
+	})
+
+	t.Run("Holds Follow Merged Accounts", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 100)
+		store.CreateAccount(1, "b", 100)
+		store.PlaceHold(1, "a", 50)
+
+		// ACT
+		store.MergeAccounts(2, "a", "b")
+
+		// ASSERT
+		account, _ := store.GetAccount("b")
+		assert.Equal(t, float64(150), account.AvailableBalance, "hold should move to the merged account")
+		assert.Len(t, store.Holds("b"), 1, "expected the hold on the merged account")
+	})
+
+	t.Run("Replaced Accounts Start Without Holds", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 100)
+		holdID, _ := store.PlaceHold(1, "a", 50)
+
+		// ACT
+		store.CreateAccount(2, "a", 100)
+
+		// ASSERT
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(100), account.AvailableBalance, "old holds should not reserve the new account's balance")
+		assert.Empty(t, store.Holds("a"), "expected the old holds to be gone")
+		assert.ErrorIs(t, store.ReleaseHold(holdID), ErrHoldNotFound, "expected the old hold to be gone")
+		account, _ = store.GetAccount("a")
+		assert.Equal(t, float64(100), account.AvailableBalance, "releasing an old hold should not change the new account")
+	})
+
+	t.Run("Payments Due Together Do Not Reserve Against Each Other", func(t *testing.T) {
+		for name, batching := range map[string]time.Duration{"Unbatched": 0, "Batched": time.Minute} {
+			t.Run(name, func(t *testing.T) {
+				// ARRANGE
+				clock := newManualClock(time.Unix(1000, 0))
+				store := NewAccountStore(WithClock(clock), WithAvailabilityWindow(time.Hour), WithPaymentBatching(batching))
+				store.CreateAccount(1000, "a", 100)
+				payroll, _ := store.SchedulePaymentWithPriority(1000, "a", 60, 60, PriorityPayroll)
+				sweep, _ := store.SchedulePaymentWithPriority(1000, "a", 50, 60, PrioritySweep)
+
+				// ACT
+				clock.Advance(2 * time.Minute)
+
+				// ASSERT
+				payrollAttempts, _ := store.GetPaymentAttempts(*payroll)
+				sweepAttempts, _ := store.GetPaymentAttempts(*sweep)
+				assert.Len(t, payrollAttempts, 1, "expected one payroll attempt")
+				assert.Equal(t, PaymentExecuted, payrollAttempts[0].Outcome, "payroll should run first and succeed")
+				assert.Len(t, sweepAttempts, 1, "expected one sweep attempt")
+				assert.Equal(t, FailureInsufficientFunds, sweepAttempts[0].FailureReason, "sweep should find the balance already spent")
+				account, _ := store.GetAccount("a")
+				assert.Equal(t, float64(40), account.Balance, "balance mismatch")
+			})
+		}
+	})
+}
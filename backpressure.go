@@ -0,0 +1,48 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSchedulingLimitExceeded is returned when scheduling a payment would exceed one of the
+// pending payment caps in Limits.
+var ErrSchedulingLimitExceeded = errors.New("scheduling limit exceeded")
+
+func (s *AccountStore) checkSchedulingLimits(accountID string) error {
+	if limit := s.limits.MaxPendingPayments; limit > 0 && s.pendingPayments >= limit {
+		return fmt.Errorf("%w: store has %d pending payments", ErrSchedulingLimitExceeded, s.pendingPayments)
+	}
+	if limit := s.limits.MaxPendingPaymentsPerAccount; limit > 0 && len(s.pendingByAccount[accountID]) >= limit {
+		return fmt.Errorf("%w: account has %d pending payments", ErrSchedulingLimitExceeded, len(s.pendingByAccount[accountID]))
+	}
+	return nil
+}
+
+// markPending and markDone keep the pending payment counters, and the index of each account's
+// pending payments, in step with payment.executed. Callers must hold the write lock.
+func (s *AccountStore) markPending(payment *scheduledPayment) {
+	payment.executed = false
+	s.pendingPayments++
+	pending, exists := s.pendingByAccount[payment.accountID]
+	if !exists {
+		pending = make(map[*scheduledPayment]struct{})
+		s.pendingByAccount[payment.accountID] = pending
+	}
+	pending[payment] = struct{}{}
+	if payment.tenantID != "" {
+		s.tenantPayments[payment.tenantID]++
+	}
+}
+
+func (s *AccountStore) markDone(payment *scheduledPayment) {
+	payment.executed = true
+	s.pendingPayments--
+	delete(s.pendingByAccount[payment.accountID], payment)
+	if len(s.pendingByAccount[payment.accountID]) == 0 {
+		delete(s.pendingByAccount, payment.accountID)
+	}
+	if payment.tenantID != "" {
+		s.tenantPayments[payment.tenantID]--
+	}
+}
@@ -0,0 +1,69 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulingLimits(t *testing.T) {
+	t.Run("Per Account Cap", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock), WithLimits(Limits{MaxPendingPaymentsPerAccount: 2}))
+		accountID := randomAccountID()
+		otherID := randomAccountID()
+		store.CreateAccount(100, accountID, 1000)
+		store.CreateAccount(100, otherID, 1000)
+		store.SchedulePayment(100, accountID, 10, 60)
+		store.SchedulePayment(100, accountID, 10, 60)
+
+		// ACT
+		_, err := store.SchedulePayment(100, accountID, 10, 60)
+		_, otherErr := store.SchedulePayment(100, otherID, 10, 60)
+
+		// ASSERT
+		assert.ErrorIs(t, err, ErrSchedulingLimitExceeded, "expected the per-account cap to apply")
+		assert.NoError(t, otherErr, "other accounts should not be affected")
+	})
+
+	t.Run("Store-Wide Cap", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock), WithLimits(Limits{MaxPendingPayments: 2}))
+		accountID := randomAccountID()
+		otherID := randomAccountID()
+		store.CreateAccount(100, accountID, 1000)
+		store.CreateAccount(100, otherID, 1000)
+		store.SchedulePayment(100, accountID, 10, 60)
+		store.SchedulePayment(100, otherID, 10, 60)
+
+		// ACT
+		_, err := store.SchedulePayment(100, otherID, 10, 60)
+
+		// ASSERT
+		assert.ErrorIs(t, err, ErrSchedulingLimitExceeded, "expected the store-wide cap to apply")
+	})
+
+	t.Run("Executed And Cancelled Payments Free Capacity", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock), WithLimits(Limits{MaxPendingPayments: 2}))
+		accountID := randomAccountID()
+		store.CreateAccount(100, accountID, 1000)
+		store.SchedulePayment(100, accountID, 10, 10)
+		cancelled, _ := store.SchedulePayment(100, accountID, 10, 60)
+
+		// ACT
+		clock.Advance(10 * time.Second)
+		store.CancelScheduledPayment(*cancelled)
+		_, firstErr := store.SchedulePayment(110, accountID, 10, 60)
+		_, secondErr := store.SchedulePayment(110, accountID, 10, 60)
+
+		// ASSERT
+		assert.NoError(t, firstErr, "executed payment should free capacity")
+		assert.NoError(t, secondErr, "cancelled payment should free capacity")
+		assert.Equal(t, 2, store.DebugStats().PendingPayments, "pending payments count mismatch")
+	})
+}
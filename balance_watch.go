@@ -0,0 +1,98 @@
+package bankingsystem
+
+import (
+	"errors"
+)
+
+// BalanceCrossing reports that an account's balance came to satisfy a watcher's predicate.
+// Event is the commit that moved the balance.
+type BalanceCrossing struct {
+	AccountID string
+	Balance   float64
+	Event     Event
+}
+
+// BalanceBelow is a WatchBalance predicate that holds while the balance is below threshold.
+func BalanceBelow(threshold float64) func(balance float64) bool {
+	return func(balance float64) bool {
+		return balance < threshold
+	}
+}
+
+// BalanceAbove is a WatchBalance predicate that holds while the balance is above threshold.
+func BalanceAbove(threshold float64) func(balance float64) bool {
+	return func(balance float64) bool {
+		return balance > threshold
+	}
+}
+
+type balanceWatcher struct {
+	predicate func(balance float64) bool
+	fn        func(BalanceCrossing)
+	matched   bool
+}
+
+// WatchBalance calls fn whenever the account's balance crosses into predicate: each time a
+// commit leaves the balance satisfying predicate when it did not before. A balance that already
+// satisfies predicate when the watch starts does not fire until it has left it and come back.
+// predicate is evaluated after every commit that involves the account, before the commit's
+// subscribers run, so no crossing is missed. Like Subscribe's, fn runs while the store's write
+// lock is held, so it must not block or call back into the store; to hand crossings to a
+// goroutine, send them on a buffered channel. The watch ends when the account is removed, as
+// by a merge, and does not carry over to a later account with the same ID.
+func (s *AccountStore) WatchBalance(accountID string, predicate func(balance float64) bool, fn func(BalanceCrossing)) (stop func(), err error) {
+	if predicate == nil || fn == nil {
+		return nil, errors.New("balance watch needs a predicate and a callback")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists {
+		return nil, ErrAccountNotFound
+	}
+	watcher := &balanceWatcher{predicate: predicate, fn: fn, matched: predicate(account.balance)}
+	s.balanceWatchers[accountID] = append(s.balanceWatchers[accountID], watcher)
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		watchers := s.balanceWatchers[accountID]
+		for i, other := range watchers {
+			if other == watcher {
+				s.balanceWatchers[accountID] = append(watchers[:i:i], watchers[i+1:]...)
+				break
+			}
+		}
+		if len(s.balanceWatchers[accountID]) == 0 {
+			delete(s.balanceWatchers, accountID)
+		}
+	}, nil
+}
+
+// checkBalanceWatchers evaluates the watchers of every account event involves. Callers must
+// hold the write lock.
+func (s *AccountStore) checkBalanceWatchers(event Event) {
+	if len(s.balanceWatchers) == 0 {
+		return
+	}
+	for _, accountID := range append([]string{event.AccountID, event.CounterpartyID}, event.SourceIDs...) {
+		watchers := s.balanceWatchers[accountID]
+		if len(watchers) == 0 {
+			continue
+		}
+		account, exists := s.accounts.lookup(accountID)
+		if !exists {
+			continue
+		}
+		for _, watcher := range watchers {
+			matched := watcher.predicate(account.balance)
+			if matched && !watcher.matched {
+				watcher.fn(BalanceCrossing{AccountID: accountID, Balance: account.balance, Event: event})
+			}
+			watcher.matched = matched
+		}
+	}
+}
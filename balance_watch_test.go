@@ -0,0 +1,77 @@
+package bankingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchBalance(t *testing.T) {
+	t.Run("Fires On Each Crossing", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 150)
+		store.CreateAccount(1, "b", 0)
+		var crossings []BalanceCrossing
+		stop, err := store.WatchBalance("a", BalanceBelow(100), func(crossing BalanceCrossing) {
+			crossings = append(crossings, crossing)
+		})
+		assert.NoError(t, err, "unexpected error watching balance")
+
+		// ACT
+		store.Transfer(2, "a", "b", 40)
+		store.Transfer(3, "a", "b", 20)
+		store.Transfer(4, "a", "b", 10)
+		store.Transfer(5, "b", "a", 70)
+		store.Transfer(6, "a", "b", 60)
+		stop()
+		store.Transfer(7, "b", "a", 100)
+		store.Transfer(8, "a", "b", 100)
+
+		// ASSERT
+		assert.Len(t, crossings, 2, "expected a crossing each time the balance dropped below")
+		assert.Equal(t, float64(90), crossings[0].Balance, "balance mismatch")
+		assert.Equal(t, 3, crossings[0].Event.Timestamp, "the crossing commit should be reported")
+		assert.Equal(t, float64(90), crossings[1].Balance, "balance mismatch")
+		assert.Equal(t, 6, crossings[1].Event.Timestamp, "the crossing commit should be reported")
+	})
+
+	t.Run("Starts From The Current Balance", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 50)
+		store.CreateAccount(1, "b", 500)
+		fired := 0
+		store.WatchBalance("a", BalanceBelow(100), func(BalanceCrossing) { fired++ })
+		store.WatchBalance("b", BalanceAbove(400), func(BalanceCrossing) { fired++ })
+
+		// ACT
+		store.Transfer(2, "a", "b", 10)
+
+		// ASSERT
+		assert.Equal(t, 0, fired, "balances already past the threshold should not fire")
+		_, err := store.WatchBalance("missing", BalanceBelow(1), func(BalanceCrossing) {})
+		assert.ErrorIs(t, err, ErrAccountNotFound, "expected unknown account to fail")
+	})
+
+	t.Run("Ends When The Account Is Removed", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 150)
+		store.CreateAccount(1, "b", 0)
+		var crossings []BalanceCrossing
+		stop, _ := store.WatchBalance("a", BalanceBelow(100), func(crossing BalanceCrossing) {
+			crossings = append(crossings, crossing)
+		})
+
+		// ACT
+		store.MergeAccounts(2, "a", "b")
+		store.CreateAccount(3, "a", 150)
+		store.Transfer(4, "a", "b", 100)
+		stop()
+
+		// ASSERT
+		assert.Empty(t, crossings, "the watch should not carry over to a new account with the same ID")
+		assert.Empty(t, store.balanceWatchers, "expected the watch to be dropped with the account")
+	})
+}
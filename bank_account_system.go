@@ -1,124 +1,458 @@
-package main
+package bankingsystem
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"sync"
 	"time"
 )
 
 type Account struct {
 	accountID        string
+	tenantID         string
 	updatedAt        int
 	balance          float64
 	totalTransferred float64
+	held             float64
+	minimumBalance   float64
+	currency         string
+	state            AccountState
 }
 
 type AccountStore struct {
-	mu                sync.RWMutex
-	accounts          map[string]*Account
-	nextPaymentID     int
-	scheduledPayments map[string]*time.Timer
+	mu                 sync.RWMutex
+	accounts           *accountMap
+	accountShards      int
+	scheduledPayments  map[string]*scheduledPayment
+	events             []Event
+	lastSeq            int
+	archive            Archive
+	retentionDays      int
+	archivedEvents     int
+	clock              Clock
+	logger             *slog.Logger
+	storage            Storage
+	migration          *changeCapture
+	newPaymentID       IDGenerator
+	limits             Limits
+	paymentRetries     RetryPolicy
+	deadLetters        map[string]*DeadLetter
+	nextDeadLetterID   int
+	pendingPayments    int
+	pendingByAccount   map[string]map[*scheduledPayment]struct{}
+	tenantQuotas       map[string]Quota
+	tenantAccounts     map[string]int
+	tenantPayments     map[string]int
+	tenantVolume       map[string]map[int]float64
+	subscribers        []subscriber
+	nextSubscriberID   int
+	webhooks           *WebhookDispatcher
+	region             string
+	versions           map[string]VectorClock
+	tombstones         map[string]bool
+	conflicts          []Conflict
+	nextConflictID     int
+	availabilityWindow time.Duration
+	holds              map[string]*Hold
+	counterparties     map[string]map[string][]counterpartyMovement
+	batchWindow        time.Duration
+	paymentBatches     map[int]*paymentBatch
+	transferGuards     map[string]TransferGuard
+	transferRules      []TransferRule
+	flaggedTransfers   []FlaggedTransfer
+	operationTimeout   time.Duration
+	accountTags        map[string]map[string]bool
+	statements         map[statementKey]*StatementDelivery
+	amountPolicies     map[string]AmountPolicy
+	tierPolicies       map[string]AmountPolicy
+	overdrafts         map[string]*overdraftFacility
+	sequences          SequenceProvider
+	systemAccounts     map[string]*Account
+	cutOff             *cutOffCalendar
+	accountNotes       map[string][]Note
+	cases              map[string]*Case
+	caseOrder          []*Case
+	schedulingPaused   bool
+	heldPayments       []*scheduledPayment
+	idempotencyCaches  []*idempotencyCache
+	sweeps             int
+	expiredHolds       int
+	expiredKeys        int
+	inboxes            map[string]*accountInbox
+	inboxCapacity      int
+	authorizer         Authorizer
+	pricing            TransferPricing
+	quotes             map[string]*TransferQuote
+	balanceWatchers    map[string][]*balanceWatcher
+	usage              map[string]map[string]*TenantMonthUsage
+	replayWindow       time.Duration
+	replayKey          []byte
+	streamBuffer       int
+	tracer             *TraceRecorder
 }
 
-func NewAccountStore() *AccountStore {
-	return &AccountStore{
-		accounts:          make(map[string]*Account),
-		nextPaymentID:     1,
-		scheduledPayments: make(map[string]*time.Timer),
+type scheduledPayment struct {
+	paymentID string
+	accountID string
+	tenantID  string
+	amount    float64
+	executeAt int
+	timer     Timer
+	executed  bool
+	attempts  []PaymentAttempt
+	retryBase int
+	priority  PaymentPriority
+	seq       int
+	armed     int
+}
+
+func NewAccountStore(opts ...Option) *AccountStore {
+	s := &AccountStore{
+		accountShards:     DefaultAccountShards,
+		inboxes:           make(map[string]*accountInbox),
+		inboxCapacity:     DefaultInboxCapacity,
+		streamBuffer:      DefaultStreamBuffer,
+		authorizer:        AllowAll,
+		quotes:            make(map[string]*TransferQuote),
+		balanceWatchers:   make(map[string][]*balanceWatcher),
+		usage:             make(map[string]map[string]*TenantMonthUsage),
+		scheduledPayments: make(map[string]*scheduledPayment),
+		deadLetters:       make(map[string]*DeadLetter),
+		pendingByAccount:  make(map[string]map[*scheduledPayment]struct{}),
+		tenantQuotas:      make(map[string]Quota),
+		tenantAccounts:    make(map[string]int),
+		tenantPayments:    make(map[string]int),
+		tenantVolume:      make(map[string]map[int]float64),
+		versions:          make(map[string]VectorClock),
+		tombstones:        make(map[string]bool),
+		nextDeadLetterID:  1,
+		nextConflictID:    1,
+		holds:             make(map[string]*Hold),
+		counterparties:    make(map[string]map[string][]counterpartyMovement),
+		paymentBatches:    make(map[int]*paymentBatch),
+		transferGuards:    make(map[string]TransferGuard),
+		accountTags:       make(map[string]map[string]bool),
+		statements:        make(map[statementKey]*StatementDelivery),
+		amountPolicies:    make(map[string]AmountPolicy),
+		tierPolicies:      make(map[string]AmountPolicy),
+		overdrafts:        make(map[string]*overdraftFacility),
+		sequences:         NewMemorySequences(),
+		systemAccounts:    make(map[string]*Account),
+		accountNotes:      make(map[string][]Note),
+		cases:             make(map[string]*Case),
+		clock:             systemClock{},
+		logger:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+		newPaymentID:      defaultPaymentID,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.startTracing()
+	s.accounts = newAccountMap(s.accountShards)
+	return s
 }
 
+// CreateAccount creates, or replaces, an account. It returns nil if the account could not be
+// written to the configured Storage.
 func (s *AccountStore) CreateAccount(timestamp int, accountID string, initialBalance float64) *Account {
+	account, err := s.tryCreateAccount(timestamp, accountID, initialBalance)
+	if err != nil {
+		s.logger.Error("creating account", "accountID", accountID, "error", err)
+		return nil
+	}
+	return account
+}
+
+// tryCreateAccount is CreateAccount returning why the account could not be created, for
+// callers such as the HTTP API that report it.
+func (s *AccountStore) tryCreateAccount(timestamp int, accountID string, initialBalance float64) (*Account, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceCreateAccount, Timestamp: timestamp, AccountID: accountID, Amount: initialBalance})
 
+	return s.createAccount(timestamp, "", accountID, initialBalance)
+}
+
+// GetAccount returns a snapshot of the account's current state.
+func (s *AccountStore) GetAccount(accountID string) (AccountSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists {
+		return AccountSnapshot{}, ErrAccountNotFound
+	}
+	return s.liveSnapshot(account), nil
+}
+
+func (s *AccountStore) createAccount(timestamp int, tenantID, accountID string, initialBalance float64) (*Account, error) {
+	if err := s.checkCreateAccount(tenantID, accountID); err != nil {
+		return nil, err
+	}
 	account := &Account{
 		accountID:        accountID,
+		tenantID:         tenantID,
 		updatedAt:        timestamp,
 		balance:          initialBalance,
 		totalTransferred: 0,
 	}
-	s.accounts[accountID] = account
-	return account
+	if err := s.persist(StorageBatch{Put: []AccountSnapshot{account.snapshot()}}); err != nil {
+		return nil, err
+	}
+	s.dropHolds(accountID)
+	s.putAccount(account)
+	s.record(Event{Timestamp: timestamp, Type: EventAccountCreated, AccountID: accountID, TenantID: tenantID, Amount: initialBalance})
+	return account, nil
 }
 
 func (s *AccountStore) Transfer(timestamp int, fromID, toID string, amount float64) (bool, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceTransfer, Timestamp: timestamp, AccountID: fromID, CounterpartyID: toID, Amount: amount})
+
+	fromAccount, toAccount, err := s.validateTransfer(timestamp, fromID, toID, amount)
+	if err != nil {
+		return false, err
+	}
+	flags, err := s.checkTransferRules(timestamp, fromAccount, toID, amount)
+	if err != nil {
+		return false, err
+	}
+
+	from, to := projectTransfer(timestamp, fromAccount, toAccount, amount)
+	if err := s.persist(StorageBatch{Put: []AccountSnapshot{from, to}}); err != nil {
+		return false, err
+	}
+	fromAccount.restore(from)
+	toAccount.restore(to)
+	s.addTransferVolume(fromAccount.tenantID, timestamp, amount)
+
+	event := Event{Timestamp: timestamp, Type: EventTransfer, AccountID: fromID, CounterpartyID: toID, Amount: amount}
+	if valueDate := s.ValueDate(timestamp); valueDate != timestamp {
+		event.ValueTimestamp = valueDate
+	}
+	s.record(event)
+	if len(flags) > 0 {
+		s.flagTransfer(event, flags)
+	}
+	return true, nil
+}
+
+func (s *AccountStore) validateTransfer(timestamp int, fromID, toID string, amount float64) (*Account, *Account, error) {
+	return s.checkTransfer(timestamp, fromID, toID, amount, false)
+}
 
-	fromAccount, fromExists := s.accounts[fromID]
-	toAccount, toExists := s.accounts[toID]
+// checkTransfer is validateTransfer, optionally between accounts in different currencies.
+func (s *AccountStore) checkTransfer(timestamp int, fromID, toID string, amount float64, acrossCurrencies bool) (*Account, *Account, error) {
+	fromAccount, fromExists := s.accounts.lookup(fromID)
+	toAccount, toExists := s.accounts.lookup(toID)
 
 	if !fromExists || !toExists {
-		return false, errors.New("one or both accounts do not exist")
+		return nil, nil, errAccountsNotFound
 	}
 
-	if fromAccount.balance < amount {
-		return false, errors.New("insufficient balance in the from account")
+	if err := checkOperation(fromAccount, opDebit); err != nil {
+		return nil, nil, err
+	}
+	if err := checkOperation(toAccount, opCredit); err != nil {
+		return nil, nil, err
+	}
+
+	if !acrossCurrencies {
+		if err := checkSameCurrency(fromAccount, toAccount); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	fromAccount.balance -= amount
-	fromAccount.totalTransferred += amount
-	fromAccount.updatedAt = timestamp
+	if err := s.checkLimits(fromAccount, amount); err != nil {
+		return nil, nil, err
+	}
 
-	toAccount.balance += amount
-	toAccount.updatedAt = timestamp
+	if available := s.availableBalance(fromAccount, timestamp, nil); available < amount {
+		return nil, nil, insufficientBalance(fromID, amount, available)
+	}
 
-	return true, nil
+	if err := s.checkTransferQuota(fromAccount.tenantID, timestamp, amount); err != nil {
+		return nil, nil, err
+	}
+
+	return fromAccount, toAccount, nil
+}
+
+func projectTransfer(timestamp int, fromAccount, toAccount *Account, amount float64) (AccountSnapshot, AccountSnapshot) {
+	from := fromAccount.snapshot()
+	from.Balance -= amount
+	from.TotalTransferred += amount
+	from.UpdatedAt = timestamp
+
+	// A self-transfer credits the account it just debited.
+	to := from
+	if toAccount != fromAccount {
+		to = toAccount.snapshot()
+	}
+	to.Balance += amount
+	to.UpdatedAt = timestamp
+
+	return from, to
 }
 
 // Level 3 - Schedule Payment (Completed in the assessment) and Cancel Payment
 func (s *AccountStore) SchedulePayment(timestamp int, accountID string, amount float64, delaySeconds int) (*string, error) {
+	return s.SchedulePaymentWithPriority(timestamp, accountID, amount, delaySeconds, PriorityNormal)
+}
+
+// SchedulePaymentWithPriority schedules a payment like SchedulePayment, with a priority that
+// decides its place among the account's payments due at the same time.
+func (s *AccountStore) SchedulePaymentWithPriority(timestamp int, accountID string, amount float64, delaySeconds int, priority PaymentPriority) (*string, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSchedulePayment, Timestamp: timestamp, AccountID: accountID, Amount: amount, DelaySeconds: delaySeconds, Priority: priority})
 
-	_, exists := s.accounts[accountID]
+	account, exists := s.accounts.lookup(accountID)
 	if !exists {
-		return nil, errors.New("account does not exist")
+		return nil, ErrAccountNotFound
+	}
+	if err := s.checkPaymentAdmission(account, amount); err != nil {
+		return nil, err
+	}
+
+	seq, err := s.nextSequence(SequencePayment)
+	if err != nil {
+		return nil, err
+	}
+	paymentID := s.newPaymentID(accountID, seq)
+	payment := &scheduledPayment{
+		paymentID: paymentID,
+		accountID: accountID,
+		tenantID:  account.tenantID,
+		amount:    amount,
+		executeAt: timestamp + delaySeconds,
+		priority:  priority,
+		seq:       seq,
 	}
 
 	executeAt := time.Unix(int64(timestamp), 0).Add(time.Duration(delaySeconds) * time.Second)
-	delayDuration := time.Until(executeAt)
+	delayDuration := executeAt.Sub(s.clock.Now())
 	if delayDuration <= 0 {
 		delayDuration = 0
 	}
-	timer := time.AfterFunc(delayDuration, func() {
-		s.mu.Lock()
-		defer s.mu.Unlock()
+	s.armPayment(payment, delayDuration)
 
-		acc, exists := s.accounts[accountID]
-		if !exists {
-			return
-		}
-		if acc.balance < amount {
-			return
-		}
-		acc.balance -= amount
-		acc.totalTransferred += amount
+	s.scheduledPayments[paymentID] = payment
+	s.markPending(payment)
+
+	return &paymentID, nil
+}
+
+// checkPaymentAdmission reports whether the account may take on another pending payment of
+// amount. Callers must hold the lock.
+func (s *AccountStore) checkPaymentAdmission(account *Account, amount float64) error {
+	if err := checkOperation(account, opDebit); err != nil {
+		return err
+	}
+	if err := s.checkLimits(account, amount); err != nil {
+		return err
+	}
+	if err := s.checkSchedulingLimits(account.accountID); err != nil {
+		return err
+	}
+	return s.checkPaymentQuota(account.tenantID)
+}
+
+// armPayment sets the payment to execute after delay, or with its batch when batching is
+// enabled. Callers must hold the write lock.
+func (s *AccountStore) armPayment(payment *scheduledPayment, delay time.Duration) {
+	if s.batchWindow > 0 {
+		s.addToBatch(payment)
+		return
+	}
+	payment.armed++
+	armed := payment.armed
+	payment.timer = s.clock.AfterFunc(delay, func() {
+		s.executePayment(payment, armed)
 	})
+}
 
-	paymentID := fmt.Sprintf("payment-%s-%d", accountID, s.nextPaymentID)
-	s.scheduledPayments[paymentID] = timer
+// executePayment runs the payment together with the account's other payments due at the same
+// time, in priority order. armed identifies the timer that fired, so that a payment already
+// run with an earlier one, or re-armed since, is not run again.
+func (s *AccountStore) executePayment(payment *scheduledPayment, armed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return &paymentID, nil
+	if payment.armed != armed || payment.executed {
+		return
+	}
+	if s.schedulingPaused {
+		s.holdPayment(payment)
+		return
+	}
+	due := s.duePayments(payment)
+	// Payments about to run do not count as pending against each other.
+	for _, payment := range due {
+		payment.executed = true
+	}
+	for _, payment := range due {
+		payment.executed = false
+		failureReason := s.applyPayment(payment)
+		s.recordAttempt(payment, failureReason)
+		if failureReason == "" {
+			s.markDone(payment)
+			continue
+		}
+		s.handleFailedPayment(payment, failureReason)
+	}
+}
+
+// applyPayment debits a due payment and returns why it failed, or "" on success. Callers must
+// hold the write lock.
+func (s *AccountStore) applyPayment(payment *scheduledPayment) string {
+	acc, exists := s.accounts.lookup(payment.accountID)
+	if !exists {
+		s.logger.Warn("skipping scheduled payment for missing account", "paymentID", payment.paymentID, "accountID", payment.accountID)
+		return FailureAccountNotFound
+	}
+	if checkOperation(acc, opDebit) != nil {
+		s.logger.Warn("skipping scheduled payment for inactive account", "paymentID", payment.paymentID, "accountID", payment.accountID, "state", acc.State())
+		return FailureAccountInactive
+	}
+	if s.availableBalance(acc, payment.executeAt, payment) < payment.amount {
+		s.logger.Warn("skipping scheduled payment due to insufficient balance", "paymentID", payment.paymentID, "accountID", payment.accountID)
+		return FailureInsufficientFunds
+	}
+
+	projected := acc.snapshot()
+	projected.Balance -= payment.amount
+	projected.TotalTransferred += payment.amount
+	if err := s.persist(StorageBatch{Put: []AccountSnapshot{projected}}); err != nil {
+		s.logger.Error("persisting scheduled payment", "paymentID", payment.paymentID, "error", err)
+		return FailureStorageError
+	}
+	acc.restore(projected)
+	s.record(Event{Timestamp: payment.executeAt, Type: EventPaymentExecuted, AccountID: payment.accountID, Amount: payment.amount})
+	return ""
 }
 
 func (s *AccountStore) CancelScheduledPayment(paymentID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	timer, exists := s.scheduledPayments[paymentID]
+	s.traceCall(TraceEntry{Op: TraceCancelPayment, ID: paymentID})
+	payment, exists := s.scheduledPayments[paymentID]
 	if !exists {
-		return errors.New("payment not found")
+		return ErrPaymentNotFound
 	}
 
 	// Stop the timer if it is still running
-	stopped := timer.Stop()
+	stopped := payment.timer.Stop()
 	if !stopped {
-		return errors.New("payment already executed or cancelled")
+		return ErrPaymentNotCancellable
 	}
 
 	// Remove the payment from the scheduled payments map
+	s.markDone(payment)
 	delete(s.scheduledPayments, paymentID)
 	return nil
 }
@@ -127,18 +461,124 @@ func (s *AccountStore) CancelScheduledPayment(paymentID string) error {
 func (s *AccountStore) MergeAccounts(timestamp int, fromID, toID string) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceMergeAccounts, Timestamp: timestamp, AccountID: fromID, CounterpartyID: toID})
+
+	fromAccount, toAccount, err := s.validateMerge(fromID, toID)
+	if err != nil {
+		return err
+	}
 
-	fromAccount, fromExists := s.accounts[fromID]
-	toAccount, toExists := s.accounts[toID]
+	merged := projectMerge(timestamp, fromAccount, toAccount)
+	if err := s.persist(StorageBatch{Put: []AccountSnapshot{merged}, Delete: []string{fromID}}); err != nil {
+		return err
+	}
+	toAccount.restore(merged)
+	s.moveHolds(fromAccount, toAccount)
+
+	s.removeAccount(fromID)
+	s.record(Event{Timestamp: timestamp, Type: EventAccountsMerged, AccountID: fromID, CounterpartyID: toID, Amount: fromAccount.balance})
+	return nil
+}
+
+func (s *AccountStore) validateMerge(fromID, toID string) (*Account, *Account, error) {
+	fromAccount, fromExists := s.accounts.lookup(fromID)
+	toAccount, toExists := s.accounts.lookup(toID)
 
 	if !fromExists || !toExists {
-		return errors.New("one or both accounts do not exist")
+		return nil, nil, errAccountsNotFound
 	}
 
-	toAccount.balance += fromAccount.balance
-	toAccount.totalTransferred += fromAccount.totalTransferred
-	toAccount.updatedAt = timestamp
+	if err := checkOperation(fromAccount, opMerge); err != nil {
+		return nil, nil, err
+	}
+	if err := checkOperation(toAccount, opMerge); err != nil {
+		return nil, nil, err
+	}
 
-	delete(s.accounts, fromID)
+	if err := checkSameCurrency(fromAccount, toAccount); err != nil {
+		return nil, nil, err
+	}
+
+	return fromAccount, toAccount, nil
+}
+
+// checkCreateAccount reports whether accountID may be created for tenantID, which is empty for
+// accounts outside any tenant. Callers must hold the lock.
+func (s *AccountStore) checkCreateAccount(tenantID, accountID string) error {
+	if isSystemAccount(accountID) {
+		return errReservedAccountID
+	}
+	return s.checkAccountQuota(tenantID, accountID)
+}
+
+// putAccount adds or replaces an account, keeping per-tenant counts in step. Callers must hold
+// the write lock.
+func (s *AccountStore) putAccount(account *Account) {
+	s.removeAccount(account.accountID)
+	s.accounts.put(account)
+	if account.tenantID != "" {
+		s.tenantAccounts[account.tenantID]++
+		s.meterAccounts(account.tenantID)
+	}
+}
+
+// removeAccount deletes an account, with its tags, inbox and balance watches, if it exists.
+// Callers must hold the write lock.
+func (s *AccountStore) removeAccount(accountID string) {
+	existing, exists := s.accounts.lookup(accountID)
+	if !exists {
+		return
+	}
+	s.accounts.delete(accountID)
+	delete(s.accountTags, accountID)
+	delete(s.inboxes, accountID)
+	delete(s.balanceWatchers, accountID)
+	if existing.tenantID != "" {
+		s.tenantAccounts[existing.tenantID]--
+	}
+}
+
+func projectMerge(timestamp int, fromAccount, toAccount *Account) AccountSnapshot {
+	merged := toAccount.snapshot()
+	merged.Balance += fromAccount.balance
+	merged.TotalTransferred += fromAccount.totalTransferred
+	merged.UpdatedAt = timestamp
+	return merged
+}
+
+// persist writes a batch to the configured Storage, if any. Callers persist before changing
+// in-memory state so a failed write leaves the store untouched.
+func (s *AccountStore) persist(batch StorageBatch) error {
+	if err := s.write(batch); err != nil {
+		return err
+	}
+	s.advanceVersions(batch)
+	return nil
+}
+
+// operationContext bounds a call to Storage or another backend by the operation timeout.
+func (s *AccountStore) operationContext() (context.Context, context.CancelFunc) {
+	if s.operationTimeout > 0 {
+		return context.WithTimeout(context.Background(), s.operationTimeout)
+	}
+	return context.WithCancel(context.Background())
+}
+
+// write applies batch to the configured Storage, if any, within the operation timeout.
+func (s *AccountStore) write(batch StorageBatch) error {
+	if s.storage == nil {
+		return nil
+	}
+	ctx, cancel := s.operationContext()
+	defer cancel()
+	if err := s.storage.Apply(ctx, batch); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w after %s: %w", ErrOperationTimeout, s.operationTimeout, err)
+		}
+		return err
+	}
+	if s.migration != nil {
+		s.migration.add(batch)
+	}
 	return nil
 }
@@ -1,4 +1,4 @@
-package main
+package bankingsystem
 
 import (
 	"fmt"
@@ -48,8 +48,8 @@ func TestTransfer(t *testing.T) {
 		assert.NoError(t, err, "unexpected error during transfer")
 		assert.True(t, success, "expected transfer to succeed")
 
-		fromAccount := store.accounts[fromID]
-		toAccount := store.accounts[toID]
+		fromAccount := store.accounts.get(fromID)
+		toAccount := store.accounts.get(toID)
 
 		assert.Equal(t, initialBalance-transferAmount, fromAccount.balance, "fromAccount balance mismatch")
 		assert.Equal(t, initialBalance+transferAmount, toAccount.balance, "toAccount balance mismatch")
@@ -75,8 +75,8 @@ func TestTransfer(t *testing.T) {
 		assert.Error(t, err, "expected error due to insufficient balance")
 		assert.False(t, success, "expected transfer to fail")
 
-		fromAccount := store.accounts[fromID]
-		toAccount := store.accounts[toID]
+		fromAccount := store.accounts.get(fromID)
+		toAccount := store.accounts.get(toID)
 
 		assert.Equal(t, initialBalance, fromAccount.balance, "fromAccount balance mismatch")
 		assert.Equal(t, initialBalance, toAccount.balance, "toAccount balance mismatch")
@@ -125,7 +125,7 @@ func TestSchedulePayment(t *testing.T) {
 		// Wait for the payment to execute
 		time.Sleep(time.Duration(delay+1) * time.Second)
 
-		account := store.accounts[accountID]
+		account := store.accounts.get(accountID)
 		assert.Equal(t, initialBalance-paymentAmount, account.balance, "account balance mismatch after payment")
 		assert.Equal(t, paymentAmount, account.totalTransferred, "total transferred mismatch after payment")
 	})
@@ -149,7 +149,7 @@ func TestSchedulePayment(t *testing.T) {
 		// Wait for the payment to execute
 		time.Sleep(time.Duration(delay+1) * time.Second)
 
-		account := store.accounts[accountID]
+		account := store.accounts.get(accountID)
 		assert.Equal(t, initialBalance, account.balance, "account balance should remain unchanged due to insufficient funds")
 		assert.Equal(t, float64(0), account.totalTransferred, "total transferred should remain unchanged")
 	})
@@ -193,7 +193,7 @@ func TestCancelScheduledPayment(t *testing.T) {
 		assert.NoError(t, err, "unexpected error during cancellation")
 		_, exists := store.scheduledPayments[*paymentID]
 		assert.False(t, exists, "payment should be removed from scheduled payments")
-		account := store.accounts[accountID]
+		account := store.accounts.get(accountID)
 		assert.Equal(t, initialBalance, account.balance, "account balance mismatch")
 	})
 
@@ -256,10 +256,10 @@ func TestMergeAccounts(t *testing.T) {
 
 		// ASSERT
 		assert.NoError(t, err, "unexpected error during merge")
-		_, fromExists := store.accounts[fromID]
+		_, fromExists := store.accounts.lookup(fromID)
 		assert.False(t, fromExists, "from account should be deleted after merge")
 
-		mergedAccount := store.accounts[toID]
+		mergedAccount := store.accounts.get(toID)
 		assert.Equal(t, fromInitialBalance+toInitialBalance, mergedAccount.balance, "merged account balance mismatch")
 		assert.Equal(t, toAccount.totalTransferred, mergedAccount.totalTransferred, "merged account total transferred mismatch")
 		assert.Equal(t, timestamp+1, mergedAccount.updatedAt, "merged account updatedAt mismatch")
@@ -0,0 +1,58 @@
+package banktest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"bankingsystem"
+)
+
+func TestFake(t *testing.T) {
+	t.Run("Runs Payments Without Sleeping", func(t *testing.T) {
+		// ARRANGE
+		f := New(t)
+		f.Accounts(Account("alice", 100), Account("bob", 50))
+		f.History(Transfer("alice", "bob", 25), Payment("bob", 10, time.Hour))
+
+		// ACT
+		before := f.Balance("bob")
+		f.Clock.Advance(time.Hour)
+
+		// ASSERT
+		assert.Equal(t, float64(75), before, "payment should not execute early")
+		assert.Equal(t, float64(65), f.Balance("bob"), "payment should execute when due")
+		assert.Equal(t, 0, f.Clock.Pending(), "expected no pending timers")
+	})
+
+	t.Run("Scripted Storage Failures", func(t *testing.T) {
+		// ARRANGE
+		f := New(t)
+		f.Accounts(Account("alice", 100), Account("bob", 50))
+		f.Storage.FailNext(ErrScripted, nil)
+
+		// ACT
+		_, failed := f.Store.Transfer(f.Now(), "alice", "bob", 10)
+		_, succeeded := f.Store.Transfer(f.Now(), "alice", "bob", 10)
+
+		// ASSERT
+		assert.ErrorIs(t, failed, ErrScripted, "expected the scripted failure")
+		assert.NoError(t, succeeded, "expected the second write to go through")
+		assert.Equal(t, float64(90), f.Balance("alice"), "only the second transfer should apply")
+		assert.Len(t, f.Storage.Batches(), 3, "expected two account writes and one transfer")
+	})
+
+	t.Run("Tenant Fixtures", func(t *testing.T) {
+		// ARRANGE
+		f := New(t)
+
+		// ACT
+		f.Accounts(Account("alice", 100).ForTenant("acme"))
+
+		// ASSERT
+		account, err := f.Store.GetAccount("alice")
+		assert.NoError(t, err, "unexpected error reading account")
+		assert.Equal(t, bankingsystem.AccountSnapshot{AccountID: "alice", TenantID: "acme", UpdatedAt: f.Now(), Balance: 100, AvailableBalance: 100}, account, "account mismatch")
+	})
+}
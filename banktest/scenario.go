@@ -0,0 +1,235 @@
+package banktest
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"bankingsystem"
+)
+
+var updateGolden = flag.Bool("banktest.update", false, "rewrite scenario golden files with the actual output")
+
+// RunScenarios runs every *.scenario file in dir as a subtest against a store backed by a
+// fresh Storage from newStorage, and compares its output with the .golden file next to it.
+// Run the tests with -banktest.update to write the golden files instead.
+//
+// A scenario is one operation per line; blank lines and lines starting with # are ignored.
+// Amounts are numbers, durations are Go durations, and every operation runs at the fake
+// clock's current time:
+//
+//	create <account> <balance> [tenant]
+//	transfer <from> <to> <amount>
+//	schedule <account> <amount> <delay>
+//	cancel <payment>
+//	merge <from> <to>
+//	advance <duration>
+//	balance <account>
+//	state
+//
+// Each operation writes one line of output, and the run ends with the accounts held by the
+// Storage, so the same files check that a backend stores exactly what the store sees.
+func RunScenarios(t *testing.T, dir string, newStorage func() bankingsystem.Storage) {
+	t.Helper()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.scenario"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) == 0 {
+		t.Fatalf("banktest: no scenarios in %s", dir)
+	}
+
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), ".scenario")
+		t.Run(name, func(t *testing.T) {
+			storage := newStorage()
+			actual, err := RunScenario(t, path, storage)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			goldenPath := strings.TrimSuffix(path, ".scenario") + ".golden"
+			if *updateGolden {
+				if err := os.WriteFile(goldenPath, []byte(actual), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			golden, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("banktest: reading golden file: %v (run with -banktest.update to create it)", err)
+			}
+			if actual != string(golden) {
+				t.Errorf("banktest: output of %s differs from %s\n--- want\n%s--- got\n%s", path, goldenPath, golden, actual)
+			}
+		})
+	}
+}
+
+// RunScenario runs the scenario file at path against a store backed by storage and returns
+// its output. Operation failures are part of the output; err reports malformed scenarios.
+func RunScenario(tb testing.TB, path string, storage bankingsystem.Storage) (string, error) {
+	tb.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	f := New(tb, bankingsystem.WithStorage(storage))
+	var output strings.Builder
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		result, err := f.runOperation(strings.Fields(line))
+		if err != nil {
+			return "", fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+		fmt.Fprintf(&output, "%s => %s\n", line, result)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	stored, err := storage.Load(context.Background())
+	if err != nil {
+		return "", err
+	}
+	// Storage does not promise an order, so sort for stable golden files.
+	sort.Slice(stored, func(i, j int) bool {
+		return stored[i].AccountID < stored[j].AccountID
+	})
+	output.WriteString("stored:\n")
+	for _, account := range stored {
+		fmt.Fprintf(&output, "  %s\n", formatAccount(account))
+	}
+	return output.String(), nil
+}
+
+func (f *Fake) runOperation(fields []string) (string, error) {
+	args := fields[1:]
+	switch fields[0] {
+	case "create":
+		if len(args) != 2 && len(args) != 3 {
+			return "", fmt.Errorf("usage: create <account> <balance> [tenant]")
+		}
+		balance, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return "", err
+		}
+		if len(args) == 3 {
+			_, err := f.Store.CreateTenantAccount(f.Now(), args[2], args[0], balance)
+			return outcome(err), nil
+		}
+		if f.Store.CreateAccount(f.Now(), args[0], balance) == nil {
+			return "error: account not created", nil
+		}
+		return "ok", nil
+
+	case "transfer":
+		if len(args) != 3 {
+			return "", fmt.Errorf("usage: transfer <from> <to> <amount>")
+		}
+		amount, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			return "", err
+		}
+		_, err = f.Store.Transfer(f.Now(), args[0], args[1], amount)
+		return outcome(err), nil
+
+	case "schedule":
+		if len(args) != 3 {
+			return "", fmt.Errorf("usage: schedule <account> <amount> <delay>")
+		}
+		amount, err := strconv.ParseFloat(args[1], 64)
+		if err != nil {
+			return "", err
+		}
+		delay, err := time.ParseDuration(args[2])
+		if err != nil {
+			return "", err
+		}
+		paymentID, err := f.Store.SchedulePayment(f.Now(), args[0], amount, int(delay/time.Second))
+		if err != nil {
+			return outcome(err), nil
+		}
+		return *paymentID, nil
+
+	case "cancel":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: cancel <payment>")
+		}
+		return outcome(f.Store.CancelScheduledPayment(args[0])), nil
+
+	case "merge":
+		if len(args) != 2 {
+			return "", fmt.Errorf("usage: merge <from> <to>")
+		}
+		return outcome(f.Store.MergeAccounts(f.Now(), args[0], args[1])), nil
+
+	case "advance":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: advance <duration>")
+		}
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return "", err
+		}
+		f.Clock.Advance(d)
+		return "ok", nil
+
+	case "balance":
+		if len(args) != 1 {
+			return "", fmt.Errorf("usage: balance <account>")
+		}
+		account, err := f.Store.GetAccount(args[0])
+		if err != nil {
+			return outcome(err), nil
+		}
+		return strconv.FormatFloat(account.Balance, 'f', -1, 64), nil
+
+	case "state":
+		view, err := f.Store.StateAt(f.Now())
+		if err != nil {
+			return outcome(err), nil
+		}
+		var accounts []string
+		for _, account := range view.Accounts() {
+			accounts = append(accounts, formatAccount(account))
+		}
+		return "[" + strings.Join(accounts, "; ") + "]", nil
+	}
+	return "", fmt.Errorf("unknown operation %q", fields[0])
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return "error: " + err.Error()
+	}
+	return "ok"
+}
+
+func formatAccount(account bankingsystem.AccountSnapshot) string {
+	formatted := fmt.Sprintf("%s balance=%s transferred=%s updated=%d",
+		account.AccountID,
+		strconv.FormatFloat(account.Balance, 'f', -1, 64),
+		strconv.FormatFloat(account.TotalTransferred, 'f', -1, 64),
+		account.UpdatedAt)
+	if account.TenantID != "" {
+		formatted += " tenant=" + account.TenantID
+	}
+	return formatted
+}
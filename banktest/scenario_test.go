@@ -0,0 +1,33 @@
+package banktest
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"bankingsystem"
+)
+
+func TestScenarios(t *testing.T) {
+	RunScenarios(t, "testdata/scenarios", func() bankingsystem.Storage {
+		return bankingsystem.NewMemoryStorage()
+	})
+}
+
+// reversedStorage loads accounts in the opposite order to MemoryStorage, as a backend that
+// does not sort would.
+type reversedStorage struct {
+	*bankingsystem.MemoryStorage
+}
+
+func (s reversedStorage) Load(ctx context.Context) ([]bankingsystem.AccountSnapshot, error) {
+	accounts, err := s.MemoryStorage.Load(ctx)
+	slices.Reverse(accounts)
+	return accounts, err
+}
+
+func TestScenariosOnUnorderedStorage(t *testing.T) {
+	RunScenarios(t, "testdata/scenarios", func() bankingsystem.Storage {
+		return reversedStorage{bankingsystem.NewMemoryStorage()}
+	})
+}
@@ -0,0 +1,70 @@
+package banktest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"bankingsystem"
+)
+
+// ErrScripted is the default error returned by scripted Storage failures.
+var ErrScripted = errors.New("banktest: scripted storage failure")
+
+// Storage is an in-memory bankingsystem.Storage whose writes can be made to fail on cue.
+type Storage struct {
+	*bankingsystem.MemoryStorage
+
+	mu       sync.Mutex
+	failures []error
+	failAll  error
+	batches  []bankingsystem.StorageBatch
+}
+
+func NewStorage() *Storage {
+	return &Storage{MemoryStorage: bankingsystem.NewMemoryStorage()}
+}
+
+// FailNext makes the next len(errs) writes fail with errs, in order. A nil entry lets that
+// write through.
+func (s *Storage) FailNext(errs ...error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, errs...)
+}
+
+// FailAll makes every write fail with err until it is called again with nil.
+func (s *Storage) FailAll(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failAll = err
+}
+
+// Batches returns every batch written successfully, in order.
+func (s *Storage) Batches() []bankingsystem.StorageBatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]bankingsystem.StorageBatch(nil), s.batches...)
+}
+
+func (s *Storage) Apply(ctx context.Context, batch bankingsystem.StorageBatch) error {
+	s.mu.Lock()
+	var err error
+	if len(s.failures) > 0 {
+		err, s.failures = s.failures[0], s.failures[1:]
+	} else {
+		err = s.failAll
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if err := s.MemoryStorage.Apply(ctx, batch); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.batches = append(s.batches, batch)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,239 @@
+// Package client is a Go client for the HTTP API served by bankingsystem.NewHTTPHandler.
+//
+// Errors returned by the server are mapped back to the bankingsystem sentinels, so callers
+// can test them with errors.Is exactly as they would against an in-process AccountStore.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"bankingsystem"
+)
+
+// Client calls the API at a base URL. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	maxRetries int
+	backoff    time.Duration
+	replay     bool
+	replayKey  []byte
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests through httpClient instead of http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries retries a request up to maxRetries times after a transport error, a server
+// error or a rate limit, waiting backoff before the first retry and doubling it after each.
+// Every attempt of a mutating request carries the same idempotency key, so a retry never
+// applies the operation twice.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.backoff = backoff
+	}
+}
+
+// WithReplayProtection sends every mutating request with the nonce and timestamp that a
+// server built with bankingsystem.WithReplayProtection requires, signed with key unless it is
+// nil. Every attempt of a retried request gets a fresh nonce; the idempotency key still ties
+// the attempts together.
+func WithReplayProtection(key []byte) Option {
+	return func(c *Client) {
+		c.replay = true
+		c.replayKey = key
+	}
+}
+
+// New returns a client for the API at baseURL. By default it retries three times, starting
+// at 100ms.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		maxRetries: 3,
+		backoff:    100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *Client) CreateAccount(ctx context.Context, timestamp int, accountID string, initialBalance float64) (bankingsystem.AccountSnapshot, error) {
+	var account bankingsystem.AccountSnapshot
+	err := c.do(ctx, http.MethodPost, "/accounts", bankingsystem.CreateAccountRequest{
+		Timestamp:      timestamp,
+		AccountID:      accountID,
+		InitialBalance: initialBalance,
+	}, &account)
+	return account, err
+}
+
+func (c *Client) GetAccount(ctx context.Context, accountID string) (bankingsystem.AccountSnapshot, error) {
+	var account bankingsystem.AccountSnapshot
+	err := c.do(ctx, http.MethodGet, "/accounts/"+url.PathEscape(accountID), nil, &account)
+	return account, err
+}
+
+func (c *Client) Transfer(ctx context.Context, timestamp int, fromID, toID string, amount float64) error {
+	return c.do(ctx, http.MethodPost, "/transfers", bankingsystem.TransferRequest{
+		Timestamp: timestamp,
+		FromID:    fromID,
+		ToID:      toID,
+		Amount:    amount,
+	}, nil)
+}
+
+// SchedulePayment returns the ID of the scheduled payment.
+func (c *Client) SchedulePayment(ctx context.Context, timestamp int, accountID string, amount float64, delaySeconds int) (string, error) {
+	return c.SchedulePaymentWithPriority(ctx, timestamp, accountID, amount, delaySeconds, bankingsystem.PriorityNormal)
+}
+
+// SchedulePaymentWithPriority is SchedulePayment with the priority the payment runs at among
+// payments due at the same time.
+func (c *Client) SchedulePaymentWithPriority(ctx context.Context, timestamp int, accountID string, amount float64, delaySeconds int, priority bankingsystem.PaymentPriority) (string, error) {
+	var response bankingsystem.SchedulePaymentResponse
+	err := c.do(ctx, http.MethodPost, "/payments", bankingsystem.SchedulePaymentRequest{
+		Timestamp:    timestamp,
+		AccountID:    accountID,
+		Amount:       amount,
+		DelaySeconds: delaySeconds,
+		Priority:     priority,
+	}, &response)
+	return response.PaymentID, err
+}
+
+func (c *Client) CancelScheduledPayment(ctx context.Context, paymentID string) error {
+	return c.do(ctx, http.MethodDelete, "/payments/"+url.PathEscape(paymentID), nil, nil)
+}
+
+func (c *Client) MergeAccounts(ctx context.Context, timestamp int, fromID, toID string) error {
+	return c.do(ctx, http.MethodPost, "/merges", bankingsystem.MergeAccountsRequest{
+		Timestamp: timestamp,
+		FromID:    fromID,
+		ToID:      toID,
+	}, nil)
+}
+
+// QuoteTransfer returns the terms the server offers for the transfer. Execute them with
+// TransferWithQuote before the quote expires.
+func (c *Client) QuoteTransfer(ctx context.Context, fromID, toID string, amount float64) (bankingsystem.TransferQuote, error) {
+	var quote bankingsystem.TransferQuote
+	err := c.do(ctx, http.MethodPost, "/quotes", bankingsystem.QuoteTransferRequest{
+		FromID: fromID,
+		ToID:   toID,
+		Amount: amount,
+	}, &quote)
+	return quote, err
+}
+
+func (c *Client) TransferWithQuote(ctx context.Context, timestamp int, quoteID string) error {
+	return c.do(ctx, http.MethodPost, "/quotes/"+url.PathEscape(quoteID), bankingsystem.ExecuteQuoteRequest{
+		Timestamp: timestamp,
+	}, nil)
+}
+
+// do sends the request, retrying as configured, and decodes a successful response into out.
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body []byte
+	if in != nil {
+		encoded, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = encoded
+	}
+	idempotencyKey := ""
+	if method != http.MethodGet {
+		idempotencyKey = uuid.NewString()
+	}
+
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := c.attempt(ctx, method, path, idempotencyKey, body, out)
+		if err == nil || !retryable || attempt >= c.maxRetries {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// attempt sends the request once and reports whether a failure is worth retrying.
+func (c *Client) attempt(ctx context.Context, method, path, idempotencyKey string, body []byte, out any) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		request.Header.Set(bankingsystem.IdempotencyKeyHeader, idempotencyKey)
+		if c.replay {
+			nonce := uuid.NewString()
+			timestamp := time.Now().Unix()
+			request.Header.Set(bankingsystem.NonceHeader, nonce)
+			request.Header.Set(bankingsystem.RequestTimestampHeader, strconv.FormatInt(timestamp, 10))
+			if c.replayKey != nil {
+				request.Header.Set(bankingsystem.SignatureHeader, bankingsystem.SignRequest(c.replayKey, method, request.URL.Path, nonce, timestamp, body))
+			}
+		}
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer response.Body.Close()
+
+	payload, err := io.ReadAll(response.Body)
+	if err != nil {
+		return true, err
+	}
+
+	if response.StatusCode >= http.StatusBadRequest {
+		var apiError bankingsystem.APIError
+		if err := json.Unmarshal(payload, &apiError); err != nil || apiError.Code == "" {
+			apiError = bankingsystem.APIError{Message: fmt.Sprintf("%s %s: %s", method, path, response.Status)}
+		}
+		retryable := response.StatusCode >= http.StatusInternalServerError ||
+			response.StatusCode == http.StatusTooManyRequests ||
+			apiError.Code == bankingsystem.CodeRequestInProgress
+		return retryable, apiError.Err()
+	}
+
+	if out == nil || len(payload) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return false, fmt.Errorf("decoding response: %w", err)
+	}
+	return false, nil
+}
@@ -0,0 +1,202 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"bankingsystem"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Mirrors The Store API", func(t *testing.T) {
+		// ARRANGE
+		store := bankingsystem.NewAccountStore()
+		server := httptest.NewServer(bankingsystem.NewHTTPHandler(store))
+		defer server.Close()
+		c := New(server.URL, WithHTTPClient(server.Client()))
+		fromID := uuid.NewString()
+		toID := uuid.NewString()
+		timestamp := int(time.Now().Unix())
+
+		// ACT
+		_, createErr := c.CreateAccount(ctx, timestamp, fromID, 1000)
+		c.CreateAccount(ctx, timestamp, toID, 1000)
+		transferErr := c.Transfer(ctx, timestamp+1, fromID, toID, 200)
+		quote, quoteErr := c.QuoteTransfer(ctx, fromID, toID, 50)
+		quotedErr := c.TransferWithQuote(ctx, timestamp+1, quote.ID)
+		paymentID, scheduleErr := c.SchedulePayment(ctx, timestamp+1, fromID, 100, 60)
+		cancelErr := c.CancelScheduledPayment(ctx, paymentID)
+		payrollID, payrollErr := c.SchedulePaymentWithPriority(ctx, timestamp+1, toID, 100, 3600, bankingsystem.PriorityPayroll)
+		mergeErr := c.MergeAccounts(ctx, timestamp+2, fromID, toID)
+		merged, getErr := c.GetAccount(ctx, toID)
+
+		// ASSERT
+		assert.NoError(t, createErr, "unexpected error creating account")
+		assert.NoError(t, transferErr, "unexpected error during transfer")
+		assert.NoError(t, quoteErr, "unexpected error quoting transfer")
+		assert.Equal(t, float64(50), quote.ConvertedAmount, "quoted amount mismatch")
+		assert.NoError(t, quotedErr, "unexpected error during quoted transfer")
+		assert.NoError(t, scheduleErr, "unexpected error during schedule payment")
+		assert.NotEmpty(t, paymentID, "expected payment ID to be generated")
+		assert.NoError(t, cancelErr, "unexpected error during cancellation")
+		assert.NoError(t, payrollErr, "unexpected error scheduling payroll")
+		calendar, _ := store.GetPaymentCalendar(toID, timestamp, timestamp+7200)
+		assert.Equal(t, []bankingsystem.PaymentObligation{{PaymentID: payrollID, Amount: 100, DueAt: timestamp + 3601, Priority: bankingsystem.PriorityPayroll}}, calendar[0].Obligations, "payroll should keep its priority")
+		assert.NoError(t, mergeErr, "unexpected error during merge")
+		assert.NoError(t, getErr, "unexpected error reading account")
+		assert.Equal(t, float64(2000), merged.Balance, "merged balance mismatch")
+	})
+
+	t.Run("Maps Errors To Sentinels", func(t *testing.T) {
+		// ARRANGE
+		store := bankingsystem.NewAccountStore()
+		server := httptest.NewServer(bankingsystem.NewHTTPHandler(store))
+		defer server.Close()
+		c := New(server.URL, WithHTTPClient(server.Client()))
+
+		// ACT
+		_, getErr := c.GetAccount(ctx, "nonexistent")
+		mergeErr := c.MergeAccounts(ctx, 1, "nonexistent", "other")
+		cancelErr := c.CancelScheduledPayment(ctx, "nonexistent-payment")
+
+		// ASSERT
+		assert.ErrorIs(t, getErr, bankingsystem.ErrAccountNotFound, "expected account not found")
+		assert.ErrorIs(t, mergeErr, bankingsystem.ErrAccountNotFound, "expected account not found")
+		assert.EqualError(t, mergeErr, "one or both accounts do not exist", "unexpected error message")
+		assert.ErrorIs(t, cancelErr, bankingsystem.ErrPaymentNotFound, "expected payment not found")
+	})
+
+	t.Run("Retries With One Idempotency Key", func(t *testing.T) {
+		// ARRANGE
+		store := bankingsystem.NewAccountStore()
+		handler := bankingsystem.NewHTTPHandler(store)
+		var mu sync.Mutex
+		var keys []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			keys = append(keys, r.Header.Get(bankingsystem.IdempotencyKeyHeader))
+			attempt := len(keys)
+			mu.Unlock()
+
+			handler.ServeHTTP(w, r)
+			if attempt == 1 {
+				// Applied, but the connection drops before the client sees the response.
+				panic(http.ErrAbortHandler)
+			}
+		}))
+		defer server.Close()
+		c := New(server.URL, WithHTTPClient(server.Client()), WithRetries(2, time.Millisecond))
+		fromID := uuid.NewString()
+		toID := uuid.NewString()
+		store.CreateAccount(1, fromID, 100)
+		store.CreateAccount(1, toID, 100)
+
+		// ACT
+		err := c.Transfer(ctx, 2, fromID, toID, 10)
+
+		// ASSERT
+		assert.NoError(t, err, "expected the retry to succeed")
+		mu.Lock()
+		assert.Len(t, keys, 2, "expected one retry")
+		assert.Equal(t, keys[0], keys[1], "retries should reuse the idempotency key")
+		mu.Unlock()
+		from, _ := store.GetAccount(fromID)
+		assert.Equal(t, float64(90), from.Balance, "transfer should be applied once")
+	})
+
+	t.Run("Retries After A Rate Limit", func(t *testing.T) {
+		// ARRANGE
+		store := bankingsystem.NewAccountStore(bankingsystem.WithLimits(bankingsystem.Limits{MaxPendingPaymentsPerAccount: 1}))
+		handler := bankingsystem.NewHTTPHandler(store)
+		accountID := uuid.NewString()
+		store.CreateAccount(1, accountID, 100)
+		now := int(time.Now().Unix())
+		blocking, _ := store.SchedulePayment(now, accountID, 10, 3600)
+		var mu sync.Mutex
+		var statuses []int
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, r)
+			mu.Lock()
+			statuses = append(statuses, recorder.Code)
+			mu.Unlock()
+			if recorder.Code == http.StatusTooManyRequests {
+				// The backlog clears before the client retries.
+				store.CancelScheduledPayment(*blocking)
+			}
+			for name, values := range recorder.Header() {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(recorder.Code)
+			w.Write(recorder.Body.Bytes())
+		}))
+		defer server.Close()
+		c := New(server.URL, WithHTTPClient(server.Client()), WithRetries(2, time.Millisecond))
+
+		// ACT
+		paymentID, err := c.SchedulePayment(ctx, now, accountID, 20, 3600)
+
+		// ASSERT
+		assert.NoError(t, err, "expected the retry to succeed")
+		assert.NotEmpty(t, paymentID, "expected a payment ID")
+		mu.Lock()
+		assert.Equal(t, []int{http.StatusTooManyRequests, http.StatusCreated}, statuses, "expected a rate limit, then a success")
+		mu.Unlock()
+	})
+
+	t.Run("Signs Requests For Replay Protection", func(t *testing.T) {
+		// ARRANGE
+		key := []byte("secret")
+		store := bankingsystem.NewAccountStore(bankingsystem.WithReplayProtection(time.Minute, key))
+		handler := bankingsystem.NewHTTPHandler(store)
+		var mu sync.Mutex
+		var capturedHeader http.Header
+		var capturedBody []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			if r.Method == http.MethodPost && capturedHeader == nil {
+				capturedHeader = r.Header.Clone()
+				capturedBody, _ = io.ReadAll(r.Body)
+				r.Body = io.NopCloser(bytes.NewReader(capturedBody))
+			}
+			mu.Unlock()
+			handler.ServeHTTP(w, r)
+		}))
+		defer server.Close()
+		signed := New(server.URL, WithHTTPClient(server.Client()), WithReplayProtection(key))
+		unsigned := New(server.URL, WithHTTPClient(server.Client()))
+		wrongKey := New(server.URL, WithHTTPClient(server.Client()), WithReplayProtection([]byte("guess")))
+		accountID := uuid.NewString()
+
+		// ACT
+		_, createErr := signed.CreateAccount(ctx, 1, accountID, 100)
+		_, unsignedErr := unsigned.CreateAccount(ctx, 1, uuid.NewString(), 100)
+		_, wrongKeyErr := wrongKey.CreateAccount(ctx, 1, uuid.NewString(), 100)
+		_, readErr := unsigned.GetAccount(ctx, accountID)
+		mu.Lock()
+		replay, _ := http.NewRequest(http.MethodPost, server.URL+"/accounts", bytes.NewReader(capturedBody))
+		replay.Header = capturedHeader
+		mu.Unlock()
+		replayed, replayErr := server.Client().Do(replay)
+
+		// ASSERT
+		assert.NoError(t, createErr, "signed requests should pass")
+		assert.EqualError(t, unsignedErr, "missing or malformed X-Request-Timestamp header", "unsigned requests should be rejected")
+		assert.ErrorIs(t, wrongKeyErr, bankingsystem.ErrInvalidSignature, "requests signed with another key should be rejected")
+		assert.NoError(t, readErr, "reads should not need a nonce")
+		assert.NoError(t, replayErr, "unexpected error replaying the request")
+		defer replayed.Body.Close()
+		assert.Equal(t, http.StatusConflict, replayed.StatusCode, "a captured request should not be accepted twice")
+	})
+}
@@ -0,0 +1,148 @@
+package bankingsystem
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReportKind is the filing a compliance report is raised for.
+type ReportKind string
+
+const (
+	// ReportCTR is a currency transaction report: one movement at or over the threshold.
+	ReportCTR ReportKind = "CTR"
+	// ReportSAR is a suspicious activity report: a run of movements each kept just under the
+	// threshold that together reach it.
+	ReportSAR ReportKind = "SAR"
+)
+
+// ComplianceRules are the thresholds reports are raised against.
+type ComplianceRules struct {
+	// ReportThreshold is the amount at or above which a single movement is reported.
+	ReportThreshold float64
+	// StructuringWindow is how many seconds a run of movements may span.
+	StructuringWindow int
+	// StructuringMargin is how far under the threshold, as a fraction of it, a movement must
+	// fall to count towards a run. 0.1 counts movements from 90% of the threshold.
+	StructuringMargin float64
+	// StructuringMinCount is how many movements a run needs to be reported.
+	StructuringMinCount int
+}
+
+// ComplianceReport is one record ready for a filing workflow. Events lists the Seq of every
+// movement that makes up the report.
+type ComplianceReport struct {
+	Kind      ReportKind
+	AccountID string
+	Timestamp int
+	Amount    float64
+	Events    []int
+}
+
+// ComplianceReports scans the history within [from, to] for reportable patterns and returns
+// the reports in timestamp order. Account creations, transfers and executed payments are
+// movements of the account that originated them. No other event is reported: merges move money
+// between accounts of one owner, and the amounts of fees and redenominations are not customer
+// movements.
+func (s *AccountStore) ComplianceReports(from, to int, rules ComplianceRules) ([]ComplianceReport, error) {
+	if rules.ReportThreshold <= 0 {
+		return nil, errors.New("report threshold must be positive")
+	}
+
+	s.mu.RLock()
+	events, err := s.history(from, to)
+	s.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	var reports []ComplianceReport
+	floor := rules.ReportThreshold * (1 - rules.StructuringMargin)
+	runs := make(map[string][]Event)
+	for _, event := range events {
+		switch event.Type {
+		case EventAccountCreated, EventTransfer, EventPaymentExecuted:
+		default:
+			continue
+		}
+
+		if event.Amount >= rules.ReportThreshold {
+			reports = append(reports, ComplianceReport{
+				Kind:      ReportCTR,
+				AccountID: event.AccountID,
+				Timestamp: event.Timestamp,
+				Amount:    event.Amount,
+				Events:    []int{event.Seq},
+			})
+			continue
+		}
+		if rules.StructuringMinCount == 0 || event.Amount < floor {
+			continue
+		}
+
+		run := runs[event.AccountID]
+		for len(run) > 0 && event.Timestamp-run[0].Timestamp > rules.StructuringWindow {
+			run = run[1:]
+		}
+		run = append(run, event)
+
+		total := 0.0
+		for _, movement := range run {
+			total += movement.Amount
+		}
+		if len(run) >= rules.StructuringMinCount && total >= rules.ReportThreshold {
+			report := ComplianceReport{Kind: ReportSAR, AccountID: event.AccountID, Timestamp: event.Timestamp, Amount: total}
+			for _, movement := range run {
+				report.Events = append(report.Events, movement.Seq)
+			}
+			reports = append(reports, report)
+			run = nil
+		}
+		runs[event.AccountID] = run
+	}
+
+	sort.SliceStable(reports, func(i, j int) bool {
+		return reports[i].Timestamp < reports[j].Timestamp
+	})
+	return reports, nil
+}
+
+// WriteComplianceCSV writes reports as CSV with a header row. Event sequence numbers are
+// joined with spaces.
+func WriteComplianceCSV(w io.Writer, reports []ComplianceReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"kind", "account_id", "timestamp", "amount", "events"}); err != nil {
+		return err
+	}
+	for _, report := range reports {
+		events := make([]string, len(report.Events))
+		for i, seq := range report.Events {
+			events[i] = strconv.Itoa(seq)
+		}
+		record := []string{
+			string(report.Kind),
+			report.AccountID,
+			strconv.Itoa(report.Timestamp),
+			strconv.FormatFloat(report.Amount, 'f', -1, 64),
+			strings.Join(events, " "),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteComplianceJSON writes reports as a JSON array.
+func WriteComplianceJSON(w io.Writer, reports []ComplianceReport) error {
+	if reports == nil {
+		reports = []ComplianceReport{}
+	}
+	return json.NewEncoder(w).Encode(reports)
+}
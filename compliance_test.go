@@ -0,0 +1,104 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComplianceReports(t *testing.T) {
+	rules := ComplianceRules{
+		ReportThreshold:     10000,
+		StructuringWindow:   3 * secondsPerDay,
+		StructuringMargin:   0.2,
+		StructuringMinCount: 3,
+	}
+
+	t.Run("Reports Large Movements And Structuring", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 50000)
+		store.CreateAccount(1, "b", 0)
+		store.Transfer(10, "a", "b", 9500)
+		store.Transfer(20, "a", "b", 9000)
+		store.Transfer(30, "a", "b", 100)
+		store.Transfer(40, "a", "b", 8500)
+		store.MergeAccounts(50, "b", "a")
+
+		// ACT
+		reports, err := store.ComplianceReports(historyStart, 100, rules)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error generating reports")
+		assert.Equal(t, []ComplianceReport{
+			{Kind: ReportCTR, AccountID: "a", Timestamp: 1, Amount: 50000, Events: []int{1}},
+			{Kind: ReportSAR, AccountID: "a", Timestamp: 40, Amount: 27000, Events: []int{3, 4, 6}},
+		}, reports, "reports mismatch")
+	})
+
+	t.Run("Runs Outside The Window Are Not Reported", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 9000)
+		store.CreateAccount(1, "b", 0)
+		store.Transfer(4*secondsPerDay, "a", "b", 8500)
+
+		// ACT
+		reports, err := store.ComplianceReports(historyStart, 10*secondsPerDay, rules)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error generating reports")
+		assert.Empty(t, reports, "expected no reports")
+	})
+
+	t.Run("Only Movements Are Reported", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 0)
+		store.RedenominateAccount(2, "a", "JPY", 15000)
+
+		// ACT
+		reports, err := store.ComplianceReports(historyStart, 100, rules)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error generating reports")
+		assert.Empty(t, reports, "the redenomination rate is not a movement")
+	})
+
+	t.Run("Invalid Threshold", func(t *testing.T) {
+		// ACT
+		_, err := NewAccountStore().ComplianceReports(historyStart, 100, ComplianceRules{})
+
+		// ASSERT
+		assert.EqualError(t, err, "report threshold must be positive", "unexpected error message")
+	})
+}
+
+func TestWriteCompliance(t *testing.T) {
+	reports := []ComplianceReport{{Kind: ReportSAR, AccountID: "a", Timestamp: 40, Amount: 27000, Events: []int{3, 4, 6}}}
+
+	t.Run("CSV", func(t *testing.T) {
+		// ARRANGE
+		var buf bytes.Buffer
+
+		// ACT
+		err := WriteComplianceCSV(&buf, reports)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error writing CSV")
+		assert.Equal(t, "kind,account_id,timestamp,amount,events\nSAR,a,40,27000,3 4 6\n", buf.String(), "CSV mismatch")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		// ARRANGE
+		var buf bytes.Buffer
+
+		// ACT
+		err := WriteComplianceJSON(&buf, reports)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error writing JSON")
+		assert.JSONEq(t, `[{"Kind":"SAR","AccountID":"a","Timestamp":40,"Amount":27000,"Events":[3,4,6]}]`, buf.String(), "JSON mismatch")
+	})
+}
@@ -0,0 +1,123 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Config is the policy a running store can change without a restart. A change applies from
+// the next operation on, including the next attempt of payments already scheduled.
+type Config struct {
+	Limits         Limits
+	PaymentRetries RetryPolicy
+	// RetentionDays is used by ArchiveTransactions; it has no effect until archival is enabled.
+	RetentionDays int
+	// TenantQuotas replaces every tenant's quota. Tenants missing from it are unlimited.
+	TenantQuotas map[string]Quota
+}
+
+// Validate reports the first invalid setting.
+func (c Config) Validate() error {
+	switch {
+	case c.Limits.MaxTransferAmount < 0:
+		return errors.New("max transfer amount must not be negative")
+	case c.Limits.MaxPendingPayments < 0:
+		return errors.New("max pending payments must not be negative")
+	case c.Limits.MaxPendingPaymentsPerAccount < 0:
+		return errors.New("max pending payments per account must not be negative")
+	case c.PaymentRetries.MaxRetries < 0:
+		return errors.New("max retries must not be negative")
+	case c.PaymentRetries.Backoff < 0:
+		return errors.New("retry backoff must not be negative")
+	case c.RetentionDays < 0:
+		return errors.New("retention days must not be negative")
+	}
+	for tenantID, quota := range c.TenantQuotas {
+		if quota.MaxAccounts < 0 || quota.MaxScheduledPayments < 0 || quota.DailyTransferVolume < 0 {
+			return fmt.Errorf("quota for tenant %s must not be negative", tenantID)
+		}
+	}
+	return nil
+}
+
+// Config returns the policy currently in force.
+func (s *AccountStore) Config() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	quotas := make(map[string]Quota, len(s.tenantQuotas))
+	for tenantID, quota := range s.tenantQuotas {
+		quotas[tenantID] = quota
+	}
+	return Config{
+		Limits:         s.limits,
+		PaymentRetries: s.paymentRetries,
+		RetentionDays:  s.retentionDays,
+		TenantQuotas:   quotas,
+	}
+}
+
+// ReloadConfig validates cfg and swaps it in as a whole: every operation sees either the old
+// policy or the new one, never a mix. Nothing changes if cfg is invalid.
+func (s *AccountStore) ReloadConfig(cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	quotas := make(map[string]Quota, len(cfg.TenantQuotas))
+	for tenantID, quota := range cfg.TenantQuotas {
+		quotas[tenantID] = quota
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceReloadConfig, Config: &cfg})
+
+	s.limits = cfg.Limits
+	s.paymentRetries = cfg.PaymentRetries
+	s.retentionDays = cfg.RetentionDays
+	s.tenantQuotas = quotas
+	s.logger.Info("reloaded config", "limits", cfg.Limits, "paymentRetries", cfg.PaymentRetries, "retentionDays", cfg.RetentionDays)
+	return nil
+}
+
+// WatchConfigFile reloads the JSON-encoded Config at path every time its contents change,
+// checking every interval until ctx is done. A file that cannot be read, decoded or validated
+// is logged and skipped, leaving the previous config in force.
+func (s *AccountStore) WatchConfigFile(ctx context.Context, path string, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("config watch interval must be positive")
+	}
+
+	var loaded []byte
+	for {
+		contents, err := os.ReadFile(path)
+		if err == nil && !bytes.Equal(contents, loaded) {
+			if err := s.reloadConfigFile(contents); err != nil {
+				s.logger.Error("reloading config", "path", path, "error", err)
+			}
+			loaded = contents
+		} else if err != nil {
+			s.logger.Error("reading config", "path", path, "error", err)
+		}
+
+		if err := sleepContext(ctx, s.clock, interval); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *AccountStore) reloadConfigFile(contents []byte) error {
+	var cfg Config
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&cfg); err != nil {
+		return err
+	}
+	return s.ReloadConfig(cfg)
+}
@@ -0,0 +1,73 @@
+package bankingsystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadConfig(t *testing.T) {
+	t.Run("Swaps Policy At Runtime", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithLimits(Limits{MaxTransferAmount: 100}))
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 0)
+
+		// ACT
+		err := store.ReloadConfig(Config{
+			Limits:       Limits{MaxTransferAmount: 500},
+			TenantQuotas: map[string]Quota{"acme": {MaxAccounts: 1}},
+		})
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error reloading config")
+		_, err = store.Transfer(2, "a", "b", 300)
+		assert.NoError(t, err, "new limit should be in force")
+		assert.Equal(t, Quota{MaxAccounts: 1}, store.Config().TenantQuotas["acme"], "quota mismatch")
+	})
+
+	t.Run("Rejects Invalid Config", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithLimits(Limits{MaxTransferAmount: 100}))
+
+		// ACT
+		err := store.ReloadConfig(Config{Limits: Limits{MaxTransferAmount: 500}, RetentionDays: -1})
+
+		// ASSERT
+		assert.EqualError(t, err, "retention days must not be negative", "unexpected error message")
+		assert.Equal(t, Limits{MaxTransferAmount: 100}, store.Config().Limits, "old config should stay in force")
+	})
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	// ARRANGE
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Limits":{"MaxTransferAmount":100}}`), 0o644), "unexpected error writing config")
+	store := NewAccountStore()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error)
+	go func() { done <- store.WatchConfigFile(ctx, path, 5*time.Millisecond) }()
+
+	// ACT
+	assert.Eventually(t, func() bool {
+		return store.Config().Limits.MaxTransferAmount == 100
+	}, time.Second, time.Millisecond, "expected the initial file to load")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Limits":{"MaxTransferAmount":-5}}`), 0o644), "unexpected error writing config")
+	time.Sleep(20 * time.Millisecond)
+	invalid := store.Config().Limits.MaxTransferAmount
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Limits":{"MaxTransferAmount":250}}`), 0o644), "unexpected error writing config")
+
+	// ASSERT
+	assert.Equal(t, float64(100), invalid, "invalid file should be skipped")
+	assert.Eventually(t, func() bool {
+		return store.Config().Limits.MaxTransferAmount == 250
+	}, time.Second, time.Millisecond, "expected the changed file to load")
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled, "watcher should stop with the context")
+	assert.EqualError(t, store.WatchConfigFile(context.Background(), path, 0), "config watch interval must be positive", "unexpected error message")
+}
@@ -0,0 +1,116 @@
+package bankingsystem
+
+import (
+	"sort"
+	"time"
+)
+
+// CounterpartyStats totals the transfers between an account and one counterparty.
+type CounterpartyStats struct {
+	CounterpartyID string
+	SentCount      int
+	SentVolume     float64
+	ReceivedCount  int
+	ReceivedVolume float64
+}
+
+func (c CounterpartyStats) count() int {
+	return c.SentCount + c.ReceivedCount
+}
+
+func (c CounterpartyStats) volume() float64 {
+	return c.SentVolume + c.ReceivedVolume
+}
+
+// CounterpartyRanking lists an account's top counterparties ranked two ways.
+type CounterpartyRanking struct {
+	ByCount  []CounterpartyStats
+	ByVolume []CounterpartyStats
+}
+
+// counterpartyMovement is one transfer as seen from one side of it.
+type counterpartyMovement struct {
+	timestamp int
+	amount    float64
+	sent      bool
+}
+
+// indexCounterparties adds a transfer to both accounts' counterparty index. Callers must hold
+// the write lock.
+func (s *AccountStore) indexCounterparties(event Event) {
+	if event.Type != EventTransfer {
+		return
+	}
+	s.addCounterpartyMovement(event.AccountID, event.CounterpartyID, counterpartyMovement{timestamp: event.Timestamp, amount: event.Amount, sent: true})
+	s.addCounterpartyMovement(event.CounterpartyID, event.AccountID, counterpartyMovement{timestamp: event.Timestamp, amount: event.Amount})
+}
+
+func (s *AccountStore) addCounterpartyMovement(accountID, counterpartyID string, movement counterpartyMovement) {
+	byCounterparty, exists := s.counterparties[accountID]
+	if !exists {
+		byCounterparty = make(map[string][]counterpartyMovement)
+		s.counterparties[accountID] = byCounterparty
+	}
+	byCounterparty[counterpartyID] = append(byCounterparty[counterpartyID], movement)
+}
+
+// TopCounterparties returns the n accounts this one has transferred to or from most, by number
+// of transfers and by volume, counting transfers within window of the store's clock. A zero
+// window counts every transfer. Ties are broken by counterparty ID.
+func (s *AccountStore) TopCounterparties(accountID string, n int, window time.Duration) (*CounterpartyRanking, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.accounts.lookup(accountID); !exists {
+		return nil, ErrAccountNotFound
+	}
+
+	since := historyStart
+	if window > 0 {
+		since = int(s.clock.Now().Add(-window).Unix())
+	}
+
+	var stats []CounterpartyStats
+	for counterpartyID, movements := range s.counterparties[accountID] {
+		total := CounterpartyStats{CounterpartyID: counterpartyID}
+		for _, movement := range movements {
+			if movement.timestamp < since {
+				continue
+			}
+			if movement.sent {
+				total.SentCount++
+				total.SentVolume += movement.amount
+			} else {
+				total.ReceivedCount++
+				total.ReceivedVolume += movement.amount
+			}
+		}
+		if total.count() > 0 {
+			stats = append(stats, total)
+		}
+	}
+
+	ranking := &CounterpartyRanking{
+		ByCount:  append([]CounterpartyStats(nil), stats...),
+		ByVolume: stats,
+	}
+	sort.Slice(ranking.ByCount, func(i, j int) bool {
+		a, b := ranking.ByCount[i], ranking.ByCount[j]
+		if a.count() != b.count() {
+			return a.count() > b.count()
+		}
+		return a.CounterpartyID < b.CounterpartyID
+	})
+	sort.Slice(ranking.ByVolume, func(i, j int) bool {
+		a, b := ranking.ByVolume[i], ranking.ByVolume[j]
+		if a.volume() != b.volume() {
+			return a.volume() > b.volume()
+		}
+		return a.CounterpartyID < b.CounterpartyID
+	})
+	if len(stats) > n {
+		ranking.ByCount = ranking.ByCount[:n]
+		ranking.ByVolume = ranking.ByVolume[:n]
+	}
+	return ranking, nil
+}
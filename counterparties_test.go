@@ -0,0 +1,53 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopCounterparties(t *testing.T) {
+	// ARRANGE
+	clock := newManualClock(time.Unix(10*secondsPerDay, 0))
+	store := NewAccountStore(WithClock(clock))
+	for _, accountID := range []string{"a", "b", "c", "d"} {
+		store.CreateAccount(1, accountID, 10000)
+	}
+	store.Transfer(1*secondsPerDay, "a", "d", 5000)
+	store.Transfer(9*secondsPerDay, "a", "b", 10)
+	store.Transfer(9*secondsPerDay, "b", "a", 20)
+	store.Transfer(9*secondsPerDay, "a", "b", 30)
+	store.Transfer(9*secondsPerDay, "a", "c", 500)
+
+	t.Run("Ranks By Count And Volume", func(t *testing.T) {
+		// ACT
+		ranking, err := store.TopCounterparties("a", 2, 0)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error ranking counterparties")
+		assert.Equal(t, []CounterpartyStats{
+			{CounterpartyID: "b", SentCount: 2, SentVolume: 40, ReceivedCount: 1, ReceivedVolume: 20},
+			{CounterpartyID: "c", SentCount: 1, SentVolume: 500},
+		}, ranking.ByCount, "ranking by count mismatch")
+		assert.Equal(t, []string{"d", "c"}, []string{ranking.ByVolume[0].CounterpartyID, ranking.ByVolume[1].CounterpartyID}, "ranking by volume mismatch")
+	})
+
+	t.Run("Limits To The Window", func(t *testing.T) {
+		// ACT
+		ranking, err := store.TopCounterparties("a", 5, 7*24*time.Hour)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error ranking counterparties")
+		assert.Len(t, ranking.ByVolume, 2, "transfer outside the window should not count")
+		assert.Equal(t, "c", ranking.ByVolume[0].CounterpartyID, "ranking by volume mismatch")
+	})
+
+	t.Run("Non-Existent Account", func(t *testing.T) {
+		// ACT
+		_, err := store.TopCounterparties("nonexistent", 5, 0)
+
+		// ASSERT
+		assert.ErrorIs(t, err, ErrAccountNotFound, "expected account not found")
+	})
+}
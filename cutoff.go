@@ -0,0 +1,69 @@
+package bankingsystem
+
+import (
+	"time"
+)
+
+// CutOff is the daily deadline for transfers to take value on the day they are entered.
+// Transfers entered at or after the cut-off, or on a day that is not a business day, are
+// value-dated to the start of the next business day. The money still moves when the transfer
+// is entered; the value date is recorded on its event as ValueTimestamp.
+type CutOff struct {
+	// At is the cut-off as a time of day, measured from midnight in Location.
+	At time.Duration
+	// Location is the time zone business days are counted in. Nil means UTC.
+	Location *time.Location
+	// Holidays lists dates, formatted as "2006-01-02", that are not business days. Saturdays
+	// and Sundays never are.
+	Holidays []string
+}
+
+// cutOffCalendar is a CutOff prepared for lookups.
+type cutOffCalendar struct {
+	at       time.Duration
+	location *time.Location
+	holidays map[string]bool
+}
+
+// WithCutOff value-dates transfers entered after the daily cut-off to the next business day.
+// Without a cut-off every transfer takes value when it is entered.
+func WithCutOff(cutOff CutOff) Option {
+	return func(s *AccountStore) {
+		calendar := &cutOffCalendar{at: cutOff.At, location: cutOff.Location, holidays: make(map[string]bool)}
+		if calendar.location == nil {
+			calendar.location = time.UTC
+		}
+		for _, holiday := range cutOff.Holidays {
+			calendar.holidays[holiday] = true
+		}
+		s.cutOff = calendar
+	}
+}
+
+// ValueDate returns when a transfer entered at timestamp takes value.
+func (s *AccountStore) ValueDate(timestamp int) int {
+	if s.cutOff == nil {
+		return timestamp
+	}
+	return s.cutOff.valueDate(timestamp)
+}
+
+func (c *cutOffCalendar) valueDate(timestamp int) int {
+	entered := time.Unix(int64(timestamp), 0).In(c.location)
+	day := time.Date(entered.Year(), entered.Month(), entered.Day(), 0, 0, 0, 0, c.location)
+	if c.businessDay(day) && entered.Before(day.Add(c.at)) {
+		return timestamp
+	}
+	day = day.AddDate(0, 0, 1)
+	for !c.businessDay(day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	return int(day.Unix())
+}
+
+func (c *cutOffCalendar) businessDay(day time.Time) bool {
+	if weekday := day.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return false
+	}
+	return !c.holidays[day.Format(time.DateOnly)]
+}
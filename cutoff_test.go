@@ -0,0 +1,82 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueDate(t *testing.T) {
+	unix := func(value string) int {
+		parsed, _ := time.Parse(time.RFC3339, value)
+		return int(parsed.Unix())
+	}
+	store := NewAccountStore(WithCutOff(CutOff{At: 15 * time.Hour, Holidays: []string{"2024-03-25"}}))
+
+	for _, test := range []struct {
+		name    string
+		entered string
+		value   string
+	}{
+		{"Before The Cut-Off", "2024-03-15T14:59:59Z", "2024-03-15T14:59:59Z"},
+		{"At The Cut-Off", "2024-03-14T15:00:00Z", "2024-03-15T00:00:00Z"},
+		{"Friday After The Cut-Off", "2024-03-15T16:00:00Z", "2024-03-18T00:00:00Z"},
+		{"Weekend", "2024-03-16T09:00:00Z", "2024-03-18T00:00:00Z"},
+		{"Before A Holiday", "2024-03-22T18:00:00Z", "2024-03-26T00:00:00Z"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// ACT
+			value := store.ValueDate(unix(test.entered))
+
+			// ASSERT
+			assert.Equal(t, unix(test.value), value, "value date mismatch")
+		})
+	}
+
+	t.Run("Counts Days In The Configured Location", func(t *testing.T) {
+		// ARRANGE
+		zone := time.FixedZone("UTC-5", -5*60*60)
+		store := NewAccountStore(WithCutOff(CutOff{At: 17 * time.Hour, Location: zone}))
+
+		// ACT
+		value := store.ValueDate(unix("2024-03-15T21:00:00Z"))
+
+		// ASSERT
+		assert.Equal(t, unix("2024-03-15T21:00:00Z"), value, "16:00 local should be before the cut-off")
+	})
+}
+
+func TestTransferValueDating(t *testing.T) {
+	t.Run("Records The Value Date After The Cut-Off", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithCutOff(CutOff{At: 15 * time.Hour}))
+		friday := 1710518400 // 2024-03-15T16:00:00Z
+		store.CreateAccount(friday, "a", 100)
+		store.CreateAccount(friday, "b", 0)
+
+		// ACT
+		_, err := store.Transfer(friday, "a", "b", 40)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during transfer")
+		events, _ := store.Transactions(historyStart, friday)
+		assert.Equal(t, 1710720000, events[len(events)-1].ValueTimestamp, "transfer should take value on monday")
+		b, _ := store.GetAccount("b")
+		assert.Equal(t, float64(40), b.Balance, "money should move when the transfer is entered")
+	})
+
+	t.Run("Leaves The Value Date Empty Without A Cut-Off", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 0)
+
+		// ACT
+		store.Transfer(20, "a", "b", 40)
+
+		// ASSERT
+		events, _ := store.Transactions(historyStart, 20)
+		assert.Zero(t, events[len(events)-1].ValueTimestamp, "value date should be empty")
+	})
+}
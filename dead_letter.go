@@ -0,0 +1,164 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RetryPolicy controls how failed scheduled payments are retried before being dead-lettered.
+type RetryPolicy struct {
+	MaxRetries int
+	Backoff    time.Duration
+}
+
+type DeadLetterKind string
+
+const (
+	DeadLetterPayment DeadLetterKind = "scheduled_payment"
+	DeadLetterWebhook DeadLetterKind = "webhook"
+)
+
+// DeadLetter is work the store gave up on. For scheduled payments PaymentID refers to the
+// payment, whose attempts remain available through GetPaymentAttempts. For webhooks
+// EndpointID and Event describe the delivery that failed.
+type DeadLetter struct {
+	ID         string
+	Kind       DeadLetterKind
+	PaymentID  string
+	AccountID  string
+	Amount     float64
+	EndpointID string
+	Event      Event
+	Reason     string
+	DeadAt     time.Time
+	seq        int
+}
+
+// WithPaymentRetries retries a failed scheduled payment up to maxRetries times, waiting
+// backoff between attempts. Payments that still fail are moved to the dead-letter queue.
+func WithPaymentRetries(maxRetries int, backoff time.Duration) Option {
+	return func(s *AccountStore) {
+		s.paymentRetries = RetryPolicy{MaxRetries: maxRetries, Backoff: backoff}
+	}
+}
+
+// handleFailedPayment retries a failed payment or dead-letters it once retries are exhausted.
+// A payment whose account no longer exists is never retried. Callers must hold the write lock.
+func (s *AccountStore) handleFailedPayment(payment *scheduledPayment, failureReason string) {
+	retryable := failureReason != FailureAccountNotFound
+	if retryable && len(payment.attempts)-payment.retryBase <= s.paymentRetries.MaxRetries {
+		payment.executeAt += int(s.paymentRetries.Backoff / time.Second)
+		s.armPayment(payment, s.paymentRetries.Backoff)
+		return
+	}
+
+	s.markDone(payment)
+	s.addDeadLetter(&DeadLetter{
+		Kind:      DeadLetterPayment,
+		PaymentID: payment.paymentID,
+		AccountID: payment.accountID,
+		Amount:    payment.amount,
+		Reason:    failureReason,
+	})
+}
+
+// addDeadLetter assigns an entry its ID and adds it to the queue. Callers must hold the write
+// lock.
+func (s *AccountStore) addDeadLetter(deadLetter *DeadLetter) {
+	deadLetter.ID = fmt.Sprintf("dead-letter-%d", s.nextDeadLetterID)
+	deadLetter.DeadAt = s.clock.Now()
+	deadLetter.seq = s.nextDeadLetterID
+	s.nextDeadLetterID++
+	s.deadLetters[deadLetter.ID] = deadLetter
+}
+
+// ListDeadLetters returns every dead-lettered entry, oldest first.
+func (s *AccountStore) ListDeadLetters() []DeadLetter {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deadLetters := make([]DeadLetter, 0, len(s.deadLetters))
+	for _, deadLetter := range s.deadLetters {
+		deadLetters = append(deadLetters, *deadLetter)
+	}
+	sort.Slice(deadLetters, func(i, j int) bool {
+		if !deadLetters[i].DeadAt.Equal(deadLetters[j].DeadAt) {
+			return deadLetters[i].DeadAt.Before(deadLetters[j].DeadAt)
+		}
+		return deadLetters[i].seq < deadLetters[j].seq
+	})
+	return deadLetters
+}
+
+func (s *AccountStore) GetDeadLetter(deadLetterID string) (DeadLetter, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	deadLetter, exists := s.deadLetters[deadLetterID]
+	if !exists {
+		return DeadLetter{}, errors.New("dead letter not found")
+	}
+	return *deadLetter, nil
+}
+
+// RequeueDeadLetter removes an entry from the queue and retries it. A payment is scheduled
+// again to run at timestamp, or immediately if timestamp has passed, keeping its ID and attempt
+// history and getting a fresh set of retries, if it passes the checks SchedulePayment makes. A
+// webhook is queued for delivery again.
+func (s *AccountStore) RequeueDeadLetter(timestamp int, deadLetterID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceRequeueDeadLetter, Timestamp: timestamp, ID: deadLetterID})
+
+	deadLetter, exists := s.deadLetters[deadLetterID]
+	if !exists {
+		return errors.New("dead letter not found")
+	}
+	if deadLetter.Kind == DeadLetterWebhook {
+		if s.webhooks == nil {
+			return errors.New("webhooks are not attached")
+		}
+		s.webhooks.enqueue(deadLetter.EndpointID, deadLetter.Event)
+		delete(s.deadLetters, deadLetterID)
+		return nil
+	}
+
+	payment, exists := s.scheduledPayments[deadLetter.PaymentID]
+	if !exists {
+		return ErrPaymentNotFound
+	}
+	account, exists := s.accounts.lookup(payment.accountID)
+	if !exists {
+		return ErrAccountNotFound
+	}
+	if err := s.checkPaymentAdmission(account, payment.amount); err != nil {
+		return err
+	}
+
+	delayDuration := time.Unix(int64(timestamp), 0).Sub(s.clock.Now())
+	if delayDuration <= 0 {
+		delayDuration = 0
+	}
+	s.markPending(payment)
+	payment.executeAt = timestamp
+	payment.retryBase = len(payment.attempts)
+	s.armPayment(payment, delayDuration)
+
+	delete(s.deadLetters, deadLetterID)
+	return nil
+}
+
+// DiscardDeadLetter drops an entry from the queue for good.
+func (s *AccountStore) DiscardDeadLetter(deadLetterID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceDiscardDeadLetter, ID: deadLetterID})
+
+	if _, exists := s.deadLetters[deadLetterID]; !exists {
+		return errors.New("dead letter not found")
+	}
+	delete(s.deadLetters, deadLetterID)
+	return nil
+}
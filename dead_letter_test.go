@@ -0,0 +1,107 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeadLetters(t *testing.T) {
+	t.Run("Dead-Letters After Retries Are Exhausted", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock), WithPaymentRetries(2, time.Minute))
+		accountID := randomAccountID()
+		store.CreateAccount(100, accountID, 100)
+		paymentID, _ := store.SchedulePayment(100, accountID, 200, 10)
+
+		// ACT
+		clock.Advance(10 * time.Second)
+		afterFirstAttempt := store.ListDeadLetters()
+		clock.Advance(time.Minute)
+		clock.Advance(time.Minute)
+
+		// ASSERT
+		assert.Empty(t, afterFirstAttempt, "payment should be retried before being dead-lettered")
+		deadLetters := store.ListDeadLetters()
+		assert.Len(t, deadLetters, 1, "expected one dead letter")
+		assert.Equal(t, DeadLetterPayment, deadLetters[0].Kind, "kind mismatch")
+		assert.Equal(t, *paymentID, deadLetters[0].PaymentID, "payment ID mismatch")
+		assert.Equal(t, FailureInsufficientFunds, deadLetters[0].Reason, "reason mismatch")
+		attempts, _ := store.GetPaymentAttempts(*paymentID)
+		assert.Len(t, attempts, 3, "expected the first attempt plus two retries")
+	})
+
+	t.Run("Retry Succeeds Once Funded", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock), WithPaymentRetries(1, time.Minute))
+		accountID := randomAccountID()
+		store.CreateAccount(100, accountID, 100)
+		store.SchedulePayment(100, accountID, 200, 10)
+		clock.Advance(10 * time.Second)
+
+		// ACT
+		store.CreateAccount(120, "funding", 500)
+		store.Transfer(120, "funding", accountID, 500)
+		clock.Advance(time.Minute)
+
+		// ASSERT
+		assert.Empty(t, store.ListDeadLetters(), "expected no dead letters")
+		assert.Equal(t, float64(400), store.accounts.get(accountID).balance, "balance mismatch after retry")
+	})
+
+	t.Run("Requeue And Discard", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock))
+		accountID := randomAccountID()
+		store.CreateAccount(100, accountID, 100)
+		first, _ := store.SchedulePayment(100, accountID, 200, 10)
+		store.SchedulePayment(100, accountID, 300, 10)
+		clock.Advance(10 * time.Second)
+		deadLetters := store.ListDeadLetters()
+		assert.Len(t, deadLetters, 2, "expected both payments to be dead-lettered without retries")
+
+		// ACT
+		store.CreateAccount(110, "funding", 100)
+		store.Transfer(110, "funding", accountID, 100)
+		requeueErr := store.RequeueDeadLetter(110, deadLetters[0].ID)
+		clock.Advance(0)
+		discardErr := store.DiscardDeadLetter(deadLetters[1].ID)
+
+		// ASSERT
+		assert.NoError(t, requeueErr, "unexpected error requeueing")
+		assert.NoError(t, discardErr, "unexpected error discarding")
+		assert.Empty(t, store.ListDeadLetters(), "expected an empty queue")
+		assert.Equal(t, float64(0), store.accounts.get(accountID).balance, "requeued payment should execute")
+		attempts, _ := store.GetPaymentAttempts(*first)
+		assert.Len(t, attempts, 2, "attempt history should be kept across requeue")
+		_, err := store.GetDeadLetter(deadLetters[1].ID)
+		assert.EqualError(t, err, "dead letter not found", "unexpected error message")
+	})
+
+	t.Run("Requeue Respects Scheduling Limits And Quotas", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock), WithLimits(Limits{MaxPendingPaymentsPerAccount: 1}))
+		store.CreateTenantAccount(100, "acme", "a", 0)
+		store.SchedulePayment(100, "a", 200, 10)
+		clock.Advance(10 * time.Second)
+		deadLetters := store.ListDeadLetters()
+		store.SchedulePayment(110, "a", 50, 600)
+
+		// ACT
+		limitErr := store.RequeueDeadLetter(110, deadLetters[0].ID)
+		store.limits = Limits{}
+		store.SetTenantQuota("acme", Quota{MaxScheduledPayments: 1})
+		quotaErr := store.RequeueDeadLetter(110, deadLetters[0].ID)
+
+		// ASSERT
+		assert.ErrorIs(t, limitErr, ErrSchedulingLimitExceeded, "expected the per-account cap to apply")
+		assert.ErrorIs(t, quotaErr, ErrQuotaExceeded, "expected the tenant quota to apply")
+		assert.Len(t, store.ListDeadLetters(), 1, "refused requeues should stay dead-lettered")
+		assert.Equal(t, 1, store.TenantUsage("acme", 110).ScheduledPayments, "refused requeues should not count as pending")
+	})
+}
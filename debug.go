@@ -0,0 +1,74 @@
+package bankingsystem
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"runtime/metrics"
+)
+
+const mutexWaitMetric = "/sync/mutex/wait/total:seconds"
+
+// DebugStats is a snapshot of runtime and store internals for diagnosing slowdowns.
+type DebugStats struct {
+	Goroutines       int
+	HeapAllocBytes   uint64
+	HeapObjects      uint64
+	MutexWaitSeconds float64
+
+	Accounts          int
+	ScheduledPayments int
+	PendingPayments   int
+	HotEvents         int
+	ArchivedEvents    int
+
+	// Sweeps counts sweeper runs, and ExpiredHolds and ExpiredIdempotencyKeys what they
+	// cleaned up in total.
+	Sweeps                 int
+	ExpiredHolds           int
+	ExpiredIdempotencyKeys int
+}
+
+// RegisterDebugHandlers registers the pprof handlers on mux under /debug/pprof/. Block and
+// mutex profiles stay empty unless enabled with runtime.SetBlockProfileRate and
+// runtime.SetMutexProfileFraction.
+func RegisterDebugHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+}
+
+// DebugStats reports goroutine and heap figures, the cumulative time goroutines have spent
+// blocked on mutexes process-wide, and the sizes of the store's internal maps. Reading heap
+// statistics briefly stops the world, so this is not meant to be polled at high frequency.
+func (s *AccountStore) DebugStats() DebugStats {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	samples := []metrics.Sample{{Name: mutexWaitMetric}}
+	metrics.Read(samples)
+
+	stats := DebugStats{
+		Goroutines:     runtime.NumGoroutine(),
+		HeapAllocBytes: memStats.HeapAlloc,
+		HeapObjects:    memStats.HeapObjects,
+	}
+	if samples[0].Value.Kind() == metrics.KindFloat64 {
+		stats.MutexWaitSeconds = samples[0].Value.Float64()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats.Accounts = s.accounts.len()
+	stats.ScheduledPayments = len(s.scheduledPayments)
+	stats.PendingPayments = s.pendingPayments
+	stats.HotEvents = len(s.events)
+	stats.ArchivedEvents = s.archivedEvents
+	stats.Sweeps = s.sweeps
+	stats.ExpiredHolds = s.expiredHolds
+	stats.ExpiredIdempotencyKeys = s.expiredKeys
+	return stats
+}
@@ -0,0 +1,47 @@
+package bankingsystem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDebugStats(t *testing.T) {
+	// ARRANGE
+	store := NewAccountStore()
+	accountID := randomAccountID()
+	timestamp := int(time.Now().Unix())
+	store.CreateAccount(timestamp, accountID, 1000)
+	paymentID, err := store.SchedulePayment(timestamp, accountID, 100, 60)
+	assert.NoError(t, err, "unexpected error during schedule payment")
+	defer store.CancelScheduledPayment(*paymentID)
+
+	// ACT
+	stats := store.DebugStats()
+
+	// ASSERT
+	assert.Equal(t, 1, stats.Accounts, "accounts count mismatch")
+	assert.Equal(t, 1, stats.ScheduledPayments, "scheduled payments count mismatch")
+	assert.Equal(t, 1, stats.PendingPayments, "pending payments count mismatch")
+	assert.Equal(t, 1, stats.HotEvents, "hot events count mismatch")
+	assert.Positive(t, stats.Goroutines, "expected a goroutine count")
+	assert.Positive(t, stats.HeapAllocBytes, "expected a heap size")
+}
+
+func TestRegisterDebugHandlers(t *testing.T) {
+	// ARRANGE
+	mux := http.NewServeMux()
+	RegisterDebugHandlers(mux)
+	request := httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil)
+	recorder := httptest.NewRecorder()
+
+	// ACT
+	mux.ServeHTTP(recorder, request)
+
+	// ASSERT
+	assert.Equal(t, http.StatusOK, recorder.Code, "expected the goroutine profile to be served")
+	assert.Contains(t, recorder.Body.String(), "goroutine profile", "unexpected profile body")
+}
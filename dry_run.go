@@ -0,0 +1,184 @@
+package bankingsystem
+
+import "errors"
+
+// AccountSnapshot is a point-in-time copy of an account's state.
+type AccountSnapshot struct {
+	AccountID        string
+	TenantID         string
+	UpdatedAt        int
+	Balance          float64
+	TotalTransferred float64
+	// AvailableBalance is what the account can spend: Balance less holds, the minimum
+	// balance and pending payments due soon. It is only set on snapshots read from a live
+	// store, such as by GetAccount; stored and replayed snapshots leave it zero.
+	AvailableBalance float64
+	// Currency is empty for accounts still in the store's original currency.
+	Currency string
+	// State is empty for active accounts.
+	State AccountState
+}
+
+func (a *Account) snapshot() AccountSnapshot {
+	return AccountSnapshot{
+		AccountID:        a.accountID,
+		TenantID:         a.tenantID,
+		UpdatedAt:        a.updatedAt,
+		Balance:          a.balance,
+		TotalTransferred: a.totalTransferred,
+		Currency:         a.currency,
+		State:            a.state,
+	}
+}
+
+// restore overwrites the account's state with a snapshot of the same account. The account's
+// identity, its ID and tenant, is left alone.
+func (a *Account) restore(snapshot AccountSnapshot) {
+	a.updatedAt = snapshot.UpdatedAt
+	a.balance = snapshot.Balance
+	a.totalTransferred = snapshot.TotalTransferred
+	a.currency = snapshot.Currency
+	a.state = snapshot.State
+}
+
+// DryRunResult holds the account states an operation would leave behind if it were committed.
+type DryRunResult struct {
+	Accounts []AccountSnapshot
+	// Usage is the tenant's quota usage the operation would leave behind. It is only set for
+	// operations that count against a tenant's quota.
+	Usage *TenantUsage
+}
+
+// DryRunCreateAccount validates the account CreateAccount would create and projects it,
+// without creating it.
+func (s *AccountStore) DryRunCreateAccount(timestamp int, accountID string, initialBalance float64) (*DryRunResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.dryRunCreateAccount(timestamp, "", accountID, initialBalance)
+}
+
+// DryRunCreateTenantAccount validates the account CreateTenantAccount would create and
+// projects it along with the tenant's usage, without creating it.
+func (s *AccountStore) DryRunCreateTenantAccount(timestamp int, tenantID, accountID string, initialBalance float64) (*DryRunResult, error) {
+	if tenantID == "" {
+		return nil, errors.New("tenant ID is required")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.dryRunCreateAccount(timestamp, tenantID, accountID, initialBalance)
+}
+
+func (s *AccountStore) dryRunCreateAccount(timestamp int, tenantID, accountID string, initialBalance float64) (*DryRunResult, error) {
+	if err := s.checkCreateAccount(tenantID, accountID); err != nil {
+		return nil, err
+	}
+
+	result := &DryRunResult{
+		Accounts: []AccountSnapshot{{
+			AccountID: accountID,
+			TenantID:  tenantID,
+			UpdatedAt: timestamp,
+			Balance:   initialBalance,
+		}},
+	}
+	if tenantID != "" {
+		usage := s.tenantUsage(tenantID, timestamp)
+		if existing, exists := s.accounts.lookup(accountID); !exists || existing.tenantID != tenantID {
+			usage.Accounts++
+		}
+		result.Usage = &usage
+	}
+	return result, nil
+}
+
+// DryRunTransfer validates a transfer and projects both accounts as Transfer would leave them,
+// along with the usage of the from account's tenant.
+func (s *AccountStore) DryRunTransfer(timestamp int, fromID, toID string, amount float64) (*DryRunResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fromAccount, toAccount, err := s.validateTransfer(timestamp, fromID, toID, amount)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkTransferRules(timestamp, fromAccount, toID, amount); err != nil {
+		return nil, err
+	}
+
+	from, to := projectTransfer(timestamp, fromAccount, toAccount, amount)
+	result := &DryRunResult{Accounts: []AccountSnapshot{from, to}}
+	if fromAccount.tenantID != "" {
+		usage := s.tenantUsage(fromAccount.tenantID, timestamp)
+		usage.TransferVolume += amount
+		result.Usage = &usage
+	}
+	return result, nil
+}
+
+// DryRunSchedulePayment validates a scheduled payment and projects the account as it would be
+// after the payment executes against the current balance. As with the real execution, a payment
+// the balance cannot cover leaves the account unchanged. The tenant's usage counts the payment
+// as pending.
+func (s *AccountStore) DryRunSchedulePayment(timestamp int, accountID string, amount float64, delaySeconds int) (*DryRunResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists {
+		return nil, ErrAccountNotFound
+	}
+	if err := s.checkPaymentAdmission(account, amount); err != nil {
+		return nil, err
+	}
+
+	projected := account.snapshot()
+	if s.availableBalance(account, timestamp+delaySeconds, nil) >= amount {
+		projected.Balance -= amount
+		projected.TotalTransferred += amount
+	}
+
+	result := &DryRunResult{Accounts: []AccountSnapshot{projected}}
+	if account.tenantID != "" {
+		usage := s.tenantUsage(account.tenantID, timestamp)
+		usage.ScheduledPayments++
+		result.Usage = &usage
+	}
+	return result, nil
+}
+
+// DryRunCancelScheduledPayment checks that a payment could still be cancelled.
+func (s *AccountStore) DryRunCancelScheduledPayment(paymentID string) (*DryRunResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	payment, exists := s.scheduledPayments[paymentID]
+	if !exists {
+		return nil, ErrPaymentNotFound
+	}
+	if payment.executed {
+		return nil, ErrPaymentNotCancellable
+	}
+
+	result := &DryRunResult{}
+	if account, exists := s.accounts.lookup(payment.accountID); exists {
+		result.Accounts = append(result.Accounts, account.snapshot())
+	}
+	return result, nil
+}
+
+// DryRunMergeAccounts validates a merge and projects the surviving account.
+func (s *AccountStore) DryRunMergeAccounts(timestamp int, fromID, toID string) (*DryRunResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	fromAccount, toAccount, err := s.validateMerge(fromID, toID)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := projectMerge(timestamp, fromAccount, toAccount)
+	return &DryRunResult{Accounts: []AccountSnapshot{merged}}, nil
+}
@@ -0,0 +1,196 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDryRunCreateAccount(t *testing.T) {
+	t.Run("Projects The Account And Tenant Usage", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.SetTenantQuota("acme", Quota{MaxAccounts: 2})
+		store.CreateTenantAccount(1, "acme", "a", 0)
+
+		// ACT
+		result, err := store.DryRunCreateTenantAccount(2, "acme", "b", 100)
+		replaced, replaceErr := store.DryRunCreateTenantAccount(2, "acme", "a", 100)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during dry run")
+		assert.Equal(t, []AccountSnapshot{{AccountID: "b", TenantID: "acme", UpdatedAt: 2, Balance: 100}}, result.Accounts, "projected account mismatch")
+		assert.Equal(t, &TenantUsage{Accounts: 2}, result.Usage, "projected usage mismatch")
+		assert.NoError(t, replaceErr, "unexpected error during dry run")
+		assert.Equal(t, 1, replaced.Usage.Accounts, "replacing an account should not count against the quota")
+		_, getErr := store.GetAccount("b")
+		assert.ErrorIs(t, getErr, ErrAccountNotFound, "dry run should not create the account")
+	})
+
+	t.Run("Rejects What CreateAccount Rejects", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.SetTenantQuota("acme", Quota{MaxAccounts: 1})
+		store.CreateTenantAccount(1, "acme", "a", 0)
+
+		// ACT
+		_, reservedErr := store.DryRunCreateAccount(2, SystemAccountPrefix+"fees", 0)
+		_, quotaErr := store.DryRunCreateTenantAccount(2, "acme", "b", 0)
+		_, tenantErr := store.DryRunCreateTenantAccount(2, "", "b", 0)
+		result, err := store.DryRunCreateAccount(2, "b", 0)
+
+		// ASSERT
+		assert.ErrorIs(t, reservedErr, errReservedAccountID, "expected reserved IDs to be refused")
+		assert.ErrorIs(t, quotaErr, ErrQuotaExceeded, "expected the tenant quota to be enforced")
+		assert.EqualError(t, tenantErr, "tenant ID is required", "unexpected error message")
+		assert.NoError(t, err, "unexpected error during dry run")
+		assert.Nil(t, result.Usage, "accounts outside a tenant should have no usage")
+	})
+}
+
+func TestDryRunTransfer(t *testing.T) {
+	store := NewAccountStore()
+
+	t.Run("Projects Balances Without Committing", func(t *testing.T) {
+		// ARRANGE
+		fromID := randomAccountID()
+		toID := randomAccountID()
+		initialBalance := float64(1000)
+		transferAmount := float64(200)
+		timestamp := 1
+
+		store.CreateAccount(timestamp, fromID, initialBalance)
+		store.CreateAccount(timestamp, toID, initialBalance)
+
+		// ACT
+		result, err := store.DryRunTransfer(timestamp+1, fromID, toID, transferAmount)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during dry run")
+		assert.Len(t, result.Accounts, 2, "expected both accounts to be projected")
+		assert.Equal(t, initialBalance-transferAmount, result.Accounts[0].Balance, "projected fromAccount balance mismatch")
+		assert.Equal(t, initialBalance+transferAmount, result.Accounts[1].Balance, "projected toAccount balance mismatch")
+		assert.Equal(t, timestamp+1, result.Accounts[0].UpdatedAt, "projected updatedAt mismatch")
+
+		assert.Equal(t, initialBalance, store.accounts.get(fromID).balance, "fromAccount balance should be unchanged")
+		assert.Equal(t, initialBalance, store.accounts.get(toID).balance, "toAccount balance should be unchanged")
+		assert.Equal(t, timestamp, store.accounts.get(fromID).updatedAt, "fromAccount updatedAt should be unchanged")
+	})
+
+	t.Run("Projects Tenant Transfer Volume", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateTenantAccount(86400, "acme", "a", 1000)
+		store.CreateAccount(86400, "b", 0)
+		store.Transfer(86400, "a", "b", 100)
+
+		// ACT
+		result, err := store.DryRunTransfer(86401, "a", "b", 50)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during dry run")
+		assert.Equal(t, &TenantUsage{Accounts: 1, TransferVolume: 150}, result.Usage, "projected usage mismatch")
+		assert.Equal(t, float64(100), store.TenantUsage("acme", 86401).TransferVolume, "dry run should not count the transfer")
+	})
+
+	t.Run("Insufficient Balance", func(t *testing.T) {
+		// ARRANGE
+		fromID := randomAccountID()
+		toID := randomAccountID()
+		store.CreateAccount(1, fromID, 100)
+		store.CreateAccount(1, toID, 100)
+
+		// ACT
+		result, err := store.DryRunTransfer(2, fromID, toID, 200)
+
+		// ASSERT
+		assert.Nil(t, result, "expected no projection")
+		assert.Error(t, err, "expected error due to insufficient balance")
+		assert.Equal(t, "insufficient balance in the from account", err.Error(), "unexpected error message")
+	})
+}
+
+func TestDryRunSchedulePayment(t *testing.T) {
+	store := NewAccountStore()
+
+	t.Run("Projects Balance After Execution", func(t *testing.T) {
+		// ARRANGE
+		accountID := randomAccountID()
+		store.CreateAccount(1, accountID, 1000)
+
+		// ACT
+		result, err := store.DryRunSchedulePayment(1, accountID, 200, 10)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during dry run")
+		assert.Equal(t, float64(800), result.Accounts[0].Balance, "projected balance mismatch")
+		assert.Equal(t, float64(200), result.Accounts[0].TotalTransferred, "projected total transferred mismatch")
+		assert.Empty(t, store.scheduledPayments, "no payment should be scheduled")
+	})
+
+	t.Run("Projects Tenant Pending Payments", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		timestamp := int(time.Now().Unix())
+		store.CreateTenantAccount(timestamp, "acme", "a", 1000)
+		store.SchedulePayment(timestamp, "a", 100, 3600)
+
+		// ACT
+		result, err := store.DryRunSchedulePayment(timestamp, "a", 200, 3600)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during dry run")
+		assert.Equal(t, 2, result.Usage.ScheduledPayments, "projected pending payments mismatch")
+		assert.Equal(t, 1, store.TenantUsage("acme", timestamp).ScheduledPayments, "dry run should not count the payment")
+	})
+
+	t.Run("Non-Existent Account", func(t *testing.T) {
+		// ACT
+		result, err := store.DryRunSchedulePayment(1, "nonexistent", 200, 10)
+
+		// ASSERT
+		assert.Nil(t, result, "expected no projection")
+		assert.Error(t, err, "expected error due to non-existent account")
+	})
+}
+
+func TestDryRunCancelScheduledPayment(t *testing.T) {
+	// ARRANGE
+	store := NewAccountStore()
+	accountID := randomAccountID()
+	timestamp := int(time.Now().Unix())
+	store.CreateAccount(timestamp, accountID, 1000)
+	paymentID, err := store.SchedulePayment(timestamp, accountID, 200, 60)
+	assert.NoError(t, err, "unexpected error during schedule payment")
+
+	// ACT
+	_, err = store.DryRunCancelScheduledPayment(*paymentID)
+
+	// ASSERT
+	assert.NoError(t, err, "unexpected error during dry run")
+	_, exists := store.scheduledPayments[*paymentID]
+	assert.True(t, exists, "payment should still be scheduled")
+
+	assert.NoError(t, store.CancelScheduledPayment(*paymentID), "unexpected error during cancellation")
+	_, err = store.DryRunCancelScheduledPayment(*paymentID)
+	assert.Error(t, err, "expected error for cancelled payment")
+}
+
+func TestDryRunMergeAccounts(t *testing.T) {
+	// ARRANGE
+	store := NewAccountStore()
+	fromID := randomAccountID()
+	toID := randomAccountID()
+	store.CreateAccount(1, fromID, 500)
+	store.CreateAccount(1, toID, 1000)
+
+	// ACT
+	result, err := store.DryRunMergeAccounts(2, fromID, toID)
+
+	// ASSERT
+	assert.NoError(t, err, "unexpected error during dry run")
+	assert.Equal(t, float64(1500), result.Accounts[0].Balance, "projected merged balance mismatch")
+	_, fromExists := store.accounts.lookup(fromID)
+	assert.True(t, fromExists, "from account should not be deleted by a dry run")
+}
@@ -0,0 +1,70 @@
+package bankingsystem
+
+import (
+	"errors"
+)
+
+// Errors returned by store operations. Compare against them with errors.Is: some operations
+// return a more specific message that still matches the sentinel.
+var (
+	ErrAccountNotFound       = errors.New("account does not exist")
+	ErrInsufficientBalance   = errors.New("insufficient balance in the from account")
+	ErrPaymentNotFound       = errors.New("payment not found")
+	ErrPaymentNotCancellable = errors.New("payment already executed or cancelled")
+	ErrTransferLimitExceeded = errors.New("amount exceeds the transfer limit")
+	ErrOperationTimeout      = errors.New("operation timed out")
+)
+
+// errAccountsNotFound is returned by operations on a pair of accounts when either is missing.
+var errAccountsNotFound = &detailedError{message: "one or both accounts do not exist", err: ErrAccountNotFound}
+
+// detailedError is an error with its own message that still matches a sentinel.
+type detailedError struct {
+	message string
+	err     error
+}
+
+func (e *detailedError) Error() string {
+	return e.message
+}
+
+func (e *detailedError) Unwrap() error {
+	return e.err
+}
+
+// Parameters carried by Error.
+const (
+	ParamAccountID = "account_id"
+	ParamAmount    = "amount"
+	ParamAvailable = "available"
+	ParamLimit     = "limit"
+	ParamMinimum   = "minimum"
+)
+
+// Error is a store error with the machine-readable code reported in APIError.Code and the
+// values behind it, such as the amount requested and the balance available, so that callers
+// can present it with a MessageCatalog instead of parsing the English message. It still
+// matches its sentinel with errors.Is.
+type Error struct {
+	Code    string
+	Params  map[string]any
+	message string
+	err     error
+}
+
+// newError returns an Error matching sentinel, with message or the sentinel's own message.
+func newError(sentinel error, message string, params map[string]any) *Error {
+	if message == "" {
+		message = sentinel.Error()
+	}
+	code, _ := codeFor(sentinel)
+	return &Error{Code: code, Params: params, message: message, err: sentinel}
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,28 @@
+//go:build !unix
+
+package bankingsystem
+
+import (
+	"context"
+	"errors"
+	"os"
+)
+
+// lockFile creates path exclusively, waiting on clock for whoever holds it, and returns a
+// function that removes it. A lock left behind by a crashed owner is never taken over, since
+// telling it from a live one would race with its owner.
+func lockFile(ctx context.Context, clock Clock, path string) (unlock func(), err error) {
+	for {
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+		if err := sleepContext(ctx, clock, fileLockRetry); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,37 @@
+//go:build unix
+
+package bankingsystem
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive flock on path, creating it if needed, waiting on clock for
+// whoever holds it, and returns a function that releases it. The file itself stays, since
+// removing it would let a waiter lock a file nobody else can see.
+func lockFile(ctx context.Context, clock Clock, path string) (unlock func(), err error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return func() {
+				syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+				file.Close()
+			}, nil
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) && !errors.Is(err, syscall.EINTR) {
+			file.Close()
+			return nil, err
+		}
+		if err := sleepContext(ctx, clock, fileLockRetry); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+}
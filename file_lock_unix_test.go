@@ -0,0 +1,36 @@
+//go:build unix
+
+package bankingsystem
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLock(t *testing.T) {
+	// ARRANGE
+	sequences := FileSequences{Dir: t.TempDir()}
+	os.WriteFile(filepath.Join(sequences.Dir, "payment.seq.lock"), nil, 0o600)
+	unlock, err := lockFile(context.Background(), systemClock{}, filepath.Join(sequences.Dir, "hold.seq.lock"))
+	assert.NoError(t, err, "unexpected error taking the lock")
+
+	// ACT
+	leftover, leftoverErr := sequences.Next(context.Background(), "payment")
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, heldErr := sequences.Next(ctx, "hold")
+	unlock()
+	released, releasedErr := sequences.Next(context.Background(), "hold")
+
+	// ASSERT
+	assert.NoError(t, leftoverErr, "a lock file nobody holds should not block")
+	assert.Equal(t, 1, leftover, "sequence mismatch")
+	assert.ErrorIs(t, heldErr, context.DeadlineExceeded, "a held lock should make Next wait")
+	assert.NoError(t, releasedErr, "a released lock should be taken")
+	assert.Equal(t, 1, released, "sequence mismatch")
+}
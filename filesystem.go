@@ -0,0 +1,250 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FS exposes the store as a read-only file system, for tools that already work with fs.FS,
+// such as http.FileServer or a zip writer walking it with fs.WalkDir:
+//
+//	accounts.csv                           every account, one row each
+//	accounts/{id}.json                     AccountSnapshot
+//	statements/{id}/{start}-{end}.json     Statement
+//	statements/{id}/{start}-{end}.csv      the statement's events with a running balance
+//
+// A statement directory lists the periods with a tracked delivery, but any period can be
+// opened by name. Files are rendered when opened, so two files may reflect different moments.
+// Accounts whose IDs are not valid path elements, such as IDs containing a slash, are left out.
+func (s *AccountStore) FS() fs.FS {
+	return storeFS{store: s}
+}
+
+type storeFS struct {
+	store *AccountStore
+}
+
+// fsNode is a rendered file, or a directory when children is not nil.
+type fsNode struct {
+	name     string
+	data     []byte
+	children []string
+}
+
+func (f storeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	node, err := f.node(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	if node.children == nil {
+		return &fsFile{info: node.info(), reader: bytes.NewReader(node.data)}, nil
+	}
+
+	entries := make([]fs.DirEntry, 0, len(node.children))
+	for _, child := range node.children {
+		childNode, err := f.node(path.Join(name, child))
+		if err != nil {
+			// The child went away since the listing, for example because its account was merged.
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(childNode.info()))
+	}
+	return &fsDir{info: node.info(), entries: entries}, nil
+}
+
+func (f storeFS) node(name string) (*fsNode, error) {
+	parts := strings.Split(name, "/")
+	switch {
+	case name == ".":
+		return &fsNode{name: ".", children: []string{"accounts", "accounts.csv", "statements"}}, nil
+	case name == "accounts.csv":
+		data, err := f.accountsCSV()
+		return &fsNode{name: name, data: data}, err
+	case parts[0] == "accounts" && len(parts) == 1:
+		return &fsNode{name: "accounts", children: withSuffix(f.accountIDs(), ".json")}, nil
+	case parts[0] == "accounts" && len(parts) == 2 && strings.HasSuffix(parts[1], ".json"):
+		account, err := f.store.GetAccount(strings.TrimSuffix(parts[1], ".json"))
+		if err != nil {
+			return nil, fs.ErrNotExist
+		}
+		data, err := json.MarshalIndent(account, "", "  ")
+		return &fsNode{name: parts[1], data: data}, err
+	case parts[0] == "statements" && len(parts) == 1:
+		return &fsNode{name: "statements", children: f.accountIDs()}, nil
+	case parts[0] == "statements" && len(parts) == 2:
+		if _, err := f.store.GetAccount(parts[1]); err != nil {
+			return nil, fs.ErrNotExist
+		}
+		children := []string{}
+		for _, delivery := range f.store.StatementDeliveries(parts[1]) {
+			period := fmt.Sprintf("%d-%d", delivery.PeriodStart, delivery.PeriodEnd)
+			children = append(children, period+".csv", period+".json")
+		}
+		return &fsNode{name: parts[1], children: children}, nil
+	case parts[0] == "statements" && len(parts) == 3:
+		return f.statement(parts[1], parts[2])
+	}
+	return nil, fs.ErrNotExist
+}
+
+// accountIDs returns the IDs of the accounts the file system shows, ordered.
+func (f storeFS) accountIDs() []string {
+	f.store.mu.RLock()
+	defer f.store.mu.RUnlock()
+
+	accountIDs := make([]string, 0, f.store.accounts.len())
+	for accountID := range f.store.accounts.all() {
+		if fs.ValidPath(accountID) && !strings.Contains(accountID, "/") {
+			accountIDs = append(accountIDs, accountID)
+		}
+	}
+	sort.Strings(accountIDs)
+	return accountIDs
+}
+
+func withSuffix(names []string, suffix string) []string {
+	for i := range names {
+		names[i] += suffix
+	}
+	return names
+}
+
+func (f storeFS) accountsCSV() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"account_id", "tenant_id", "balance", "available_balance", "currency", "state", "updated_at"})
+	for _, accountID := range f.accountIDs() {
+		account, err := f.store.GetAccount(accountID)
+		if err != nil {
+			continue
+		}
+		writer.Write([]string{
+			account.AccountID,
+			account.TenantID,
+			strconv.FormatFloat(account.Balance, 'f', -1, 64),
+			strconv.FormatFloat(account.AvailableBalance, 'f', -1, 64),
+			account.Currency,
+			string(account.State),
+			strconv.Itoa(account.UpdatedAt),
+		})
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// statement renders the file named {start}-{end}.json or .csv for the account.
+func (f storeFS) statement(accountID, name string) (*fsNode, error) {
+	extension := path.Ext(name)
+	start, end, found := strings.Cut(strings.TrimSuffix(name, extension), "-")
+	periodStart, startErr := strconv.Atoi(start)
+	periodEnd, endErr := strconv.Atoi(end)
+	if !found || startErr != nil || endErr != nil || periodEnd <= periodStart || (extension != ".json" && extension != ".csv") {
+		return nil, fs.ErrNotExist
+	}
+	statement, err := f.store.GenerateStatement(accountID, periodStart, periodEnd)
+	if err != nil {
+		return nil, fs.ErrNotExist
+	}
+
+	if extension == ".json" {
+		data, err := json.MarshalIndent(statement, "", "  ")
+		return &fsNode{name: name, data: data}, err
+	}
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Write([]string{"seq", "timestamp", "type", "counterparty_id", "amount", "balance"})
+	balance := statement.OpeningBalance
+	for _, event := range statement.Events {
+		balance = balanceAfter(accountID, balance, event)
+		counterpartyID := event.CounterpartyID
+		if counterpartyID == accountID {
+			counterpartyID = event.AccountID
+		}
+		writer.Write([]string{
+			strconv.Itoa(event.Seq),
+			strconv.Itoa(event.Timestamp),
+			string(event.Type),
+			counterpartyID,
+			strconv.FormatFloat(event.Amount, 'f', -1, 64),
+			strconv.FormatFloat(balance, 'f', -1, 64),
+		})
+	}
+	writer.Flush()
+	return &fsNode{name: name, data: buf.Bytes()}, writer.Error()
+}
+
+func (n *fsNode) info() fsInfo {
+	if n.children != nil {
+		return fsInfo{name: n.name, mode: fs.ModeDir | 0o555}
+	}
+	return fsInfo{name: n.name, size: int64(len(n.data)), mode: 0o444}
+}
+
+// fsInfo describes a file or directory. Modification times are zero, as the store does not
+// track them per file.
+type fsInfo struct {
+	name string
+	size int64
+	mode fs.FileMode
+}
+
+func (i fsInfo) Name() string       { return i.name }
+func (i fsInfo) Size() int64        { return i.size }
+func (i fsInfo) Mode() fs.FileMode  { return i.mode }
+func (i fsInfo) ModTime() time.Time { return time.Time{} }
+func (i fsInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i fsInfo) Sys() any           { return nil }
+
+// fsFile is an open file. It implements io.Seeker so that http.FileServer can serve ranges.
+type fsFile struct {
+	info   fsInfo
+	reader *bytes.Reader
+}
+
+func (f *fsFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *fsFile) Read(p []byte) (int, error) { return f.reader.Read(p) }
+func (f *fsFile) Close() error               { return nil }
+
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	return f.reader.Seek(offset, whence)
+}
+
+// fsDir is an open directory.
+type fsDir struct {
+	info    fsInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *fsDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *fsDir) Close() error               { return nil }
+
+func (d *fsDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *fsDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	n = min(n, len(remaining))
+	d.offset += n
+	return remaining[:n], nil
+}
@@ -0,0 +1,96 @@
+package bankingsystem
+
+import (
+	"context"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFS(t *testing.T) {
+	newStore := func() *AccountStore {
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 1000)
+		store.CreateAccount(10, "b", 0)
+		store.Transfer(20, "a", "b", 300)
+		store.DeliverStatements(context.Background(), 0, 100, "", &recordingDeliverer{})
+		return store
+	}
+
+	t.Run("Passes The File System Checks", func(t *testing.T) {
+		// ARRANGE
+		store := newStore()
+
+		// ACT
+		err := fstest.TestFS(store.FS(), "accounts.csv", "accounts/a.json", "accounts/b.json", "statements/a/0-100.csv", "statements/b/0-100.json")
+
+		// ASSERT
+		assert.NoError(t, err, "file system should behave like any fs.FS")
+	})
+
+	t.Run("Renders Accounts And Statements", func(t *testing.T) {
+		// ARRANGE
+		fsys := newStore().FS()
+
+		// ACT
+		accounts, accountsErr := fs.ReadFile(fsys, "accounts.csv")
+		account, accountErr := fs.ReadFile(fsys, "accounts/b.json")
+		statement, statementErr := fs.ReadFile(fsys, "statements/a/0-50.csv")
+
+		// ASSERT
+		assert.NoError(t, accountsErr, "unexpected error reading accounts")
+		assert.NoError(t, accountErr, "unexpected error reading the account")
+		assert.NoError(t, statementErr, "unexpected error reading an unlisted period")
+		assert.Equal(t, "account_id,tenant_id,balance,available_balance,currency,state,updated_at\na,,700,700,,,20\nb,,300,300,,,20\n", string(accounts), "accounts mismatch")
+		assert.Contains(t, string(account), `"Balance": 300`, "account should be rendered as JSON")
+		assert.Equal(t, "seq,timestamp,type,counterparty_id,amount,balance\n1,10,account_created,,1000,1000\n3,20,transfer,b,300,700\n", string(statement), "statement mismatch")
+	})
+
+	t.Run("Statements Count Incoming Merges", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 50)
+		store.MergeAccounts(20, "b", "a")
+		want, _ := store.GenerateStatement("a", 0, 100)
+
+		// ACT
+		statement, err := fs.ReadFile(store.FS(), "statements/a/0-100.csv")
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error reading the statement")
+		assert.Equal(t, "seq,timestamp,type,counterparty_id,amount,balance\n1,10,account_created,,100,100\n3,20,accounts_merged,b,50,150\n", string(statement), "statement mismatch")
+		assert.Equal(t, float64(150), want.ClosingBalance, "the last balance should match the closing balance")
+	})
+
+	t.Run("Reports Missing Files", func(t *testing.T) {
+		// ARRANGE
+		fsys := newStore().FS()
+
+		// ACT
+		_, missingAccount := fs.ReadFile(fsys, "accounts/c.json")
+		_, badPeriod := fs.ReadFile(fsys, "statements/a/100-0.csv")
+
+		// ASSERT
+		assert.ErrorIs(t, missingAccount, fs.ErrNotExist, "unknown accounts should not exist")
+		assert.ErrorIs(t, badPeriod, fs.ErrNotExist, "invalid periods should not exist")
+	})
+
+	t.Run("Serves Over HTTP", func(t *testing.T) {
+		// ARRANGE
+		server := httptest.NewServer(http.FileServer(http.FS(newStore().FS())))
+		defer server.Close()
+
+		// ACT
+		response, err := http.Get(server.URL + "/statements/a/0-100.json")
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error fetching the statement")
+		defer response.Body.Close()
+		assert.Equal(t, http.StatusOK, response.StatusCode, "statement should be served")
+	})
+}
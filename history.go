@@ -0,0 +1,238 @@
+package bankingsystem
+
+import (
+	"sort"
+)
+
+type EventType string
+
+const (
+	EventAccountCreated  EventType = "account_created"
+	EventTransfer        EventType = "transfer"
+	EventPaymentExecuted EventType = "payment_executed"
+	EventAccountsMerged  EventType = "accounts_merged"
+	// EventAccountsMergedMany merges every account in SourceIDs into CounterpartyID.
+	EventAccountsMergedMany EventType = "accounts_merged_many"
+	// EventAccountStateChanged moves AccountID to State for Reason, on behalf of Actor.
+	EventAccountStateChanged EventType = "account_state_changed"
+	// EventOverdraftFee moves a penalty fee of Amount from overdrawn AccountID to the
+	// CounterpartyID system account.
+	EventOverdraftFee EventType = "overdraft_fee"
+	// EventTransferFee moves the fee of a quoted transfer, Amount, from AccountID to the
+	// CounterpartyID system account.
+	EventTransferFee EventType = "transfer_fee"
+	// EventAccountRedenominated converts an account to Currency at the rate in Amount.
+	EventAccountRedenominated EventType = "account_redenominated"
+)
+
+// Event is one committed change to the store. For transfers and merges AccountID is the
+// source and CounterpartyID the destination. TenantID is only set on account creation,
+// Currency only on redenomination, SourceIDs only on bulk merges, and State, Reason and Actor
+// only on state changes. ValueTimestamp is only set on transfers value-dated after a cut-off,
+// to when they take value. CreditedAmount is only set on transfers across currencies, to what
+// CounterpartyID was credited in its currency for the Amount debited.
+type Event struct {
+	Seq            int
+	Timestamp      int
+	Type           EventType
+	AccountID      string
+	CounterpartyID string
+	TenantID       string
+	Amount         float64
+	Currency       string
+	SourceIDs      []string
+	State          AccountState
+	Reason         ReasonCode
+	Actor          string
+	ValueTimestamp int
+	CreditedAmount float64
+}
+
+// credited returns what a transfer credited to CounterpartyID.
+func (e Event) credited() float64 {
+	if e.CreditedAmount != 0 {
+		return e.CreditedAmount
+	}
+	return e.Amount
+}
+
+// involves reports whether the event touches accountID.
+func (e Event) involves(accountID string) bool {
+	if e.AccountID == accountID || e.CounterpartyID == accountID {
+		return true
+	}
+	for _, sourceID := range e.SourceIDs {
+		if sourceID == accountID {
+			return true
+		}
+	}
+	return false
+}
+
+// record appends an event to the history, indexes it and notifies subscribers. Callers must
+// hold the write lock.
+func (s *AccountStore) record(event Event) {
+	s.lastSeq++
+	event.Seq = s.lastSeq
+	s.events = append(s.events, event)
+	s.indexCounterparties(event)
+	s.trackOverdrafts(event)
+	s.deliverToInboxes(event)
+	s.checkBalanceWatchers(event)
+	for _, subscriber := range s.subscribers {
+		subscriber.fn(event)
+	}
+}
+
+type subscriber struct {
+	id int
+	fn func(Event)
+}
+
+// Subscribe registers fn to be called with every event as it is committed, in commit order,
+// and returns a function that removes the subscription. fn runs while the store's write lock
+// is held, so it must not block or call back into the store.
+func (s *AccountStore) Subscribe(fn func(Event)) (unsubscribe func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.subscribe(fn)
+}
+
+// subscribe is Subscribe for callers that already hold the write lock.
+func (s *AccountStore) subscribe(fn func(Event)) (unsubscribe func()) {
+	id := s.nextSubscriberID
+	s.nextSubscriberID++
+	s.subscribers = append(s.subscribers, subscriber{id: id, fn: fn})
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for i, subscriber := range s.subscribers {
+			if subscriber.id == id {
+				s.subscribers = append(s.subscribers[:i:i], s.subscribers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// applyEvent replays a single event onto a set of account snapshots.
+func applyEvent(accounts map[string]AccountSnapshot, event Event) {
+	switch event.Type {
+	case EventAccountCreated:
+		accounts[event.AccountID] = AccountSnapshot{
+			AccountID: event.AccountID,
+			TenantID:  event.TenantID,
+			UpdatedAt: event.Timestamp,
+			Balance:   event.Amount,
+		}
+	case EventTransfer:
+		from := accounts[event.AccountID]
+		from.Balance -= event.Amount
+		from.TotalTransferred += event.Amount
+		from.UpdatedAt = event.Timestamp
+		accounts[event.AccountID] = from
+
+		to := accounts[event.CounterpartyID]
+		to.Balance += event.credited()
+		to.UpdatedAt = event.Timestamp
+		accounts[event.CounterpartyID] = to
+	case EventPaymentExecuted:
+		account := accounts[event.AccountID]
+		account.Balance -= event.Amount
+		account.TotalTransferred += event.Amount
+		accounts[event.AccountID] = account
+	case EventAccountsMerged:
+		from := accounts[event.AccountID]
+		to := accounts[event.CounterpartyID]
+		to.Balance += from.Balance
+		to.TotalTransferred += from.TotalTransferred
+		to.UpdatedAt = event.Timestamp
+		accounts[event.CounterpartyID] = to
+		delete(accounts, event.AccountID)
+	case EventAccountsMergedMany:
+		to := accounts[event.CounterpartyID]
+		for _, sourceID := range event.SourceIDs {
+			from := accounts[sourceID]
+			to.Balance += from.Balance
+			to.TotalTransferred += from.TotalTransferred
+			delete(accounts, sourceID)
+		}
+		to.UpdatedAt = event.Timestamp
+		accounts[event.CounterpartyID] = to
+	case EventOverdraftFee, EventTransferFee:
+		account := accounts[event.AccountID]
+		account.Balance -= event.Amount
+		account.UpdatedAt = event.Timestamp
+		accounts[event.AccountID] = account
+
+		income := accounts[event.CounterpartyID]
+		income.AccountID = event.CounterpartyID
+		income.Balance += event.Amount
+		income.UpdatedAt = event.Timestamp
+		accounts[event.CounterpartyID] = income
+	case EventAccountStateChanged:
+		account := accounts[event.AccountID]
+		account.State = event.State
+		if event.State == StateActive {
+			account.State = ""
+		}
+		account.UpdatedAt = event.Timestamp
+		accounts[event.AccountID] = account
+	case EventAccountRedenominated:
+		account := accounts[event.AccountID]
+		account.Currency = event.Currency
+		account.Balance *= event.Amount
+		account.TotalTransferred *= event.Amount
+		account.UpdatedAt = event.Timestamp
+		accounts[event.AccountID] = account
+	}
+}
+
+// StoreView is a read-only materialization of the store as of a point in time.
+type StoreView struct {
+	asOf     int
+	accounts map[string]AccountSnapshot
+}
+
+// StateAt replays the event history up to and including timestamp, archived events included,
+// and returns the resulting view. Events are replayed in commit order, so an event committed
+// with an earlier timestamp than its predecessor is still applied after it.
+func (s *AccountStore) StateAt(timestamp int) (*StoreView, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events, err := s.history(historyStart, timestamp)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &StoreView{asOf: timestamp, accounts: make(map[string]AccountSnapshot)}
+	for _, event := range events {
+		applyEvent(view.accounts, event)
+	}
+	return view, nil
+}
+
+func (v *StoreView) AsOf() int {
+	return v.asOf
+}
+
+func (v *StoreView) Account(accountID string) (AccountSnapshot, bool) {
+	account, exists := v.accounts[accountID]
+	return account, exists
+}
+
+// Accounts returns every account in the view ordered by account ID.
+func (v *StoreView) Accounts() []AccountSnapshot {
+	accounts := make([]AccountSnapshot, 0, len(v.accounts))
+	for _, account := range v.accounts {
+		accounts = append(accounts, account)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].AccountID < accounts[j].AccountID
+	})
+	return accounts
+}
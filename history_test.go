@@ -0,0 +1,58 @@
+package bankingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateAt(t *testing.T) {
+	// ARRANGE
+	store := NewAccountStore()
+	fromID := randomAccountID()
+	toID := randomAccountID()
+	store.CreateAccount(1, fromID, 1000)
+	store.CreateAccount(2, toID, 500)
+	_, err := store.Transfer(5, fromID, toID, 200)
+	assert.NoError(t, err, "unexpected error during transfer")
+	err = store.MergeAccounts(10, fromID, toID)
+	assert.NoError(t, err, "unexpected error during merge")
+
+	t.Run("Before Any Account", func(t *testing.T) {
+		// ACT
+		view, err := store.StateAt(0)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error materializing view")
+		assert.Empty(t, view.Accounts(), "expected no accounts")
+	})
+
+	t.Run("After Transfer", func(t *testing.T) {
+		// ACT
+		view, err := store.StateAt(7)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error materializing view")
+		from, fromExists := view.Account(fromID)
+		to, toExists := view.Account(toID)
+		assert.True(t, fromExists, "from account should exist")
+		assert.True(t, toExists, "to account should exist")
+		assert.Equal(t, float64(800), from.Balance, "fromAccount balance mismatch")
+		assert.Equal(t, float64(200), from.TotalTransferred, "fromAccount total transferred mismatch")
+		assert.Equal(t, 5, from.UpdatedAt, "fromAccount updatedAt mismatch")
+		assert.Equal(t, float64(700), to.Balance, "toAccount balance mismatch")
+		assert.Equal(t, 7, view.AsOf(), "view timestamp mismatch")
+	})
+
+	t.Run("After Merge", func(t *testing.T) {
+		// ACT
+		view, err := store.StateAt(10)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error materializing view")
+		_, fromExists := view.Account(fromID)
+		assert.False(t, fromExists, "from account should be gone after merge")
+		to, _ := view.Account(toID)
+		assert.Equal(t, store.accounts.get(toID).snapshot(), to, "view should match the live store")
+	})
+}
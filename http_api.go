@@ -0,0 +1,528 @@
+package bankingsystem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// IdempotencyKeyHeader lets a client retry a mutating request without applying it twice: the
+// first response for a key is stored and replayed to every retry carrying the same key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// DefaultIdempotencyTTL is how long a response is replayed for its idempotency key.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// Request and response bodies of the HTTP API. Accounts are returned as AccountSnapshot.
+type (
+	CreateAccountRequest struct {
+		Timestamp      int
+		AccountID      string
+		InitialBalance float64
+	}
+
+	TransferRequest struct {
+		Timestamp int
+		FromID    string
+		ToID      string
+		Amount    float64
+	}
+
+	SchedulePaymentRequest struct {
+		Timestamp    int
+		AccountID    string
+		Amount       float64
+		DelaySeconds int
+		Priority     PaymentPriority
+	}
+
+	SchedulePaymentResponse struct {
+		PaymentID string
+	}
+
+	MergeAccountsRequest struct {
+		Timestamp int
+		FromID    string
+		ToID      string
+	}
+
+	QuoteTransferRequest struct {
+		FromID string
+		ToID   string
+		Amount float64
+	}
+
+	ExecuteQuoteRequest struct {
+		Timestamp int
+	}
+
+	// APIError is the body of every non-2xx response. Params carries the parameters of an
+	// Error, for presenting it with a MessageCatalog.
+	APIError struct {
+		Code    string
+		Message string
+		Params  map[string]any `json:",omitempty"`
+	}
+)
+
+// Error codes reported in APIError.Code.
+const (
+	CodeAccountNotFound         = "account_not_found"
+	CodeInsufficientBalance     = "insufficient_balance"
+	CodePaymentNotFound         = "payment_not_found"
+	CodePaymentNotCancellable   = "payment_not_cancellable"
+	CodeTransferLimitExceeded   = "transfer_limit_exceeded"
+	CodeAmountBelowMinimum      = "amount_below_minimum"
+	CodeSchedulingLimitExceeded = "scheduling_limit_exceeded"
+	CodeQuotaExceeded           = "quota_exceeded"
+	CodeCurrencyMismatch        = "currency_mismatch"
+	CodeSelfTransfer            = "self_transfer"
+	CodeRoundTrip               = "round_trip"
+	CodeRequestInProgress       = "request_in_progress"
+	CodeOperationTimeout        = "operation_timeout"
+	CodeOperationNotPermitted   = "operation_not_permitted"
+	CodeInvalidTransition       = "invalid_transition"
+	CodeCaseNotFound            = "case_not_found"
+	CodeForbidden               = "forbidden"
+	CodeQuoteNotFound           = "quote_not_found"
+	CodeQuoteExpired            = "quote_expired"
+	CodeReplayedRequest         = "replayed_request"
+	CodeStaleRequest            = "stale_request"
+	CodeInvalidSignature        = "invalid_signature"
+	CodeReservedAccountID       = "reserved_account_id"
+	CodeInvalidRequest          = "invalid_request"
+	CodeInternal                = "internal"
+)
+
+var errRequestInProgress = errors.New("a request with this idempotency key is still in progress")
+
+var errorCodes = []struct {
+	code   string
+	err    error
+	status int
+}{
+	{CodeAccountNotFound, ErrAccountNotFound, http.StatusNotFound},
+	{CodeInsufficientBalance, ErrInsufficientBalance, http.StatusUnprocessableEntity},
+	{CodePaymentNotFound, ErrPaymentNotFound, http.StatusNotFound},
+	{CodePaymentNotCancellable, ErrPaymentNotCancellable, http.StatusConflict},
+	{CodeTransferLimitExceeded, ErrTransferLimitExceeded, http.StatusUnprocessableEntity},
+	{CodeAmountBelowMinimum, ErrAmountBelowMinimum, http.StatusUnprocessableEntity},
+	{CodeSchedulingLimitExceeded, ErrSchedulingLimitExceeded, http.StatusTooManyRequests},
+	{CodeQuotaExceeded, ErrQuotaExceeded, http.StatusForbidden},
+	{CodeCurrencyMismatch, ErrCurrencyMismatch, http.StatusUnprocessableEntity},
+	{CodeSelfTransfer, ErrSelfTransfer, http.StatusUnprocessableEntity},
+	{CodeRoundTrip, ErrRoundTrip, http.StatusUnprocessableEntity},
+	{CodeRequestInProgress, errRequestInProgress, http.StatusConflict},
+	{CodeOperationTimeout, ErrOperationTimeout, http.StatusGatewayTimeout},
+	{CodeOperationNotPermitted, ErrOperationNotPermitted, http.StatusConflict},
+	{CodeInvalidTransition, ErrInvalidTransition, http.StatusConflict},
+	{CodeCaseNotFound, ErrCaseNotFound, http.StatusNotFound},
+	{CodeForbidden, ErrForbidden, http.StatusForbidden},
+	{CodeQuoteNotFound, ErrQuoteNotFound, http.StatusNotFound},
+	{CodeQuoteExpired, ErrQuoteExpired, http.StatusGone},
+	{CodeReplayedRequest, ErrReplayedRequest, http.StatusConflict},
+	{CodeStaleRequest, ErrStaleRequest, http.StatusBadRequest},
+	{CodeInvalidSignature, ErrInvalidSignature, http.StatusUnauthorized},
+	{CodeReservedAccountID, errReservedAccountID, http.StatusUnprocessableEntity},
+}
+
+// ErrorForCode turns an APIError back into the error the store returned, so that errors.Is
+// works against the exported sentinels on the client side. Unknown codes become plain errors.
+func ErrorForCode(code, message string) error {
+	for _, entry := range errorCodes {
+		if entry.code != code {
+			continue
+		}
+		if message == entry.err.Error() {
+			return entry.err
+		}
+		return &detailedError{message: message, err: entry.err}
+	}
+	return errors.New(message)
+}
+
+// Err turns the APIError back into the error the store returned, as ErrorForCode does, keeping
+// its parameters as an Error.
+func (e APIError) Err() error {
+	err := ErrorForCode(e.Code, e.Message)
+	if len(e.Params) == 0 {
+		return err
+	}
+	var detailed *detailedError
+	if errors.As(err, &detailed) {
+		err = detailed.err
+	}
+	return &Error{Code: e.Code, Params: e.Params, message: e.Message, err: err}
+}
+
+// codeFor returns the code and HTTP status err is reported with.
+func codeFor(err error) (string, int) {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.err) {
+			return entry.code, entry.status
+		}
+	}
+	return CodeInternal, http.StatusInternalServerError
+}
+
+func apiErrorFor(err error) (int, APIError) {
+	code, status := codeFor(err)
+	apiError := APIError{Code: code, Message: err.Error()}
+	var detailed *Error
+	if errors.As(err, &detailed) {
+		apiError.Params = detailed.Params
+	}
+	return status, apiError
+}
+
+// apiHandler handles one route and returns the status and body to encode as JSON.
+type apiHandler func(r *http.Request) (int, any)
+
+type httpAPI struct {
+	store       *AccountStore
+	idempotency *idempotencyCache
+}
+
+// NewHTTPHandler exposes the store over JSON HTTP:
+//
+//	POST   /accounts         CreateAccountRequest   -> AccountSnapshot
+//	GET    /accounts/{id}                           -> AccountSnapshot
+//	POST   /transfers        TransferRequest        -> 204
+//	POST   /payments         SchedulePaymentRequest -> SchedulePaymentResponse
+//	DELETE /payments/{id}                           -> 204
+//	POST   /merges           MergeAccountsRequest   -> 204
+//	POST   /quotes           QuoteTransferRequest   -> TransferQuote
+//	POST   /quotes/{id}      ExecuteQuoteRequest    -> 204
+//
+// Mutating requests may carry an IdempotencyKeyHeader, remembered for DefaultIdempotencyTTL
+// by the store's clock. The store's sweeper forgets expired keys. With WithReplayProtection,
+// mutating requests must also pass ReplayProtection.
+//
+// Every request, replays of idempotent responses included, is checked with the store's
+// Authorizer on behalf of the principal in the request's context, which authentication
+// middleware sets with ContextWithPrincipal. Requests that pass are metered against the tenant
+// of the account they act on.
+func NewHTTPHandler(store *AccountStore) http.Handler {
+	api := &httpAPI{
+		store:       store,
+		idempotency: newIdempotencyCache(DefaultIdempotencyTTL, store.clock),
+	}
+	store.mu.Lock()
+	store.idempotencyCaches = append(store.idempotencyCaches, api.idempotency)
+	store.mu.Unlock()
+
+	mux := http.NewServeMux()
+	mux.Handle("POST /accounts", api.mutating(api.createAccount))
+	mux.Handle("GET /accounts/{id}", api.reading(api.getAccount))
+	mux.Handle("POST /transfers", api.mutating(api.transfer))
+	mux.Handle("POST /payments", api.mutating(api.schedulePayment))
+	mux.Handle("DELETE /payments/{id}", api.mutating(api.cancelScheduledPayment))
+	mux.Handle("POST /merges", api.mutating(api.mergeAccounts))
+	mux.Handle("POST /quotes", api.mutating(api.quoteTransfer))
+	mux.Handle("POST /quotes/{id}", api.mutating(api.executeQuote))
+	if store.replayWindow > 0 {
+		return ReplayProtection(NewReplayGuard(store.replayWindow, store.clock, store.replayKey), mux)
+	}
+	return mux
+}
+
+// admission is what a mutating request was authorized for, remembered with its idempotent
+// response so that replaying the response is authorized again.
+type admission struct {
+	operation Operation
+	resource  Resource
+	admitted  bool
+}
+
+type admissionKey struct{}
+
+// admit authorizes the request and meters it.
+func (api *httpAPI) admit(r *http.Request, operation Operation, resource Resource) error {
+	if err := api.store.Authorize(r.Context(), operation, resource); err != nil {
+		return err
+	}
+	if admitted, ok := r.Context().Value(admissionKey{}).(*admission); ok {
+		*admitted = admission{operation: operation, resource: resource, admitted: true}
+	}
+	api.store.meterAPICall(resource.AccountID)
+	return nil
+}
+
+func (api *httpAPI) reading(handle apiHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status, body := handle(r)
+		writeJSON(w, status, encodeBody(body))
+	})
+}
+
+func (api *httpAPI) mutating(handle apiHandler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			status, body := handle(r)
+			writeJSON(w, status, encodeBody(body))
+			return
+		}
+
+		// Keys are scoped to the principal, so that nobody can replay another's response.
+		key = r.Method + " " + r.URL.Path + " " + strconv.Quote(PrincipalFromContext(r.Context()).ID) + " " + key
+		if response, found := api.idempotency.begin(key); found {
+			if response == nil {
+				status, body := apiErrorFor(errRequestInProgress)
+				writeJSON(w, status, encodeBody(body))
+				return
+			}
+			if response.admission.admitted {
+				if err := api.store.Authorize(r.Context(), response.admission.operation, response.admission.resource); err != nil {
+					status, body := apiErrorFor(err)
+					writeJSON(w, status, encodeBody(body))
+					return
+				}
+			}
+			writeJSON(w, response.status, response.body)
+			return
+		}
+
+		admitted := &admission{}
+		status, body := handle(r.WithContext(context.WithValue(r.Context(), admissionKey{}, admitted)))
+		encoded := encodeBody(body)
+		api.idempotency.finish(key, status, encoded, *admitted)
+		writeJSON(w, status, encoded)
+	})
+}
+
+func (api *httpAPI) createAccount(r *http.Request) (int, any) {
+	var request CreateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return invalidRequest(err)
+	}
+	if request.AccountID == "" {
+		return invalidRequest(errors.New("account ID is required"))
+	}
+	if err := api.admit(r, OperationCreateAccount, Resource{AccountID: request.AccountID, Amount: request.InitialBalance}); err != nil {
+		return apiErrorFor(err)
+	}
+
+	if _, err := api.store.tryCreateAccount(request.Timestamp, request.AccountID, request.InitialBalance); err != nil {
+		return apiErrorFor(err)
+	}
+	account, err := api.store.GetAccount(request.AccountID)
+	if err != nil {
+		return apiErrorFor(err)
+	}
+	return http.StatusCreated, account
+}
+
+func (api *httpAPI) getAccount(r *http.Request) (int, any) {
+	if err := api.admit(r, OperationReadAccount, Resource{AccountID: r.PathValue("id")}); err != nil {
+		return apiErrorFor(err)
+	}
+	account, err := api.store.GetAccount(r.PathValue("id"))
+	if err != nil {
+		return apiErrorFor(err)
+	}
+	return http.StatusOK, account
+}
+
+func (api *httpAPI) transfer(r *http.Request) (int, any) {
+	var request TransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return invalidRequest(err)
+	}
+	if err := api.admit(r, OperationTransfer, Resource{AccountID: request.FromID, CounterpartyID: request.ToID, Amount: request.Amount}); err != nil {
+		return apiErrorFor(err)
+	}
+
+	if _, err := api.store.Transfer(request.Timestamp, request.FromID, request.ToID, request.Amount); err != nil {
+		return apiErrorFor(err)
+	}
+	return http.StatusNoContent, nil
+}
+
+func (api *httpAPI) schedulePayment(r *http.Request) (int, any) {
+	var request SchedulePaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return invalidRequest(err)
+	}
+	if err := api.admit(r, OperationSchedulePayment, Resource{AccountID: request.AccountID, Amount: request.Amount}); err != nil {
+		return apiErrorFor(err)
+	}
+
+	paymentID, err := api.store.SchedulePaymentWithPriority(request.Timestamp, request.AccountID, request.Amount, request.DelaySeconds, request.Priority)
+	if err != nil {
+		return apiErrorFor(err)
+	}
+	return http.StatusCreated, SchedulePaymentResponse{PaymentID: *paymentID}
+}
+
+func (api *httpAPI) cancelScheduledPayment(r *http.Request) (int, any) {
+	paymentID := r.PathValue("id")
+	if err := api.admit(r, OperationCancelPayment, Resource{AccountID: api.store.paymentAccount(paymentID), PaymentID: paymentID}); err != nil {
+		return apiErrorFor(err)
+	}
+	if err := api.store.CancelScheduledPayment(paymentID); err != nil {
+		return apiErrorFor(err)
+	}
+	return http.StatusNoContent, nil
+}
+
+func (api *httpAPI) mergeAccounts(r *http.Request) (int, any) {
+	var request MergeAccountsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return invalidRequest(err)
+	}
+	if err := api.admit(r, OperationMergeAccounts, Resource{AccountID: request.FromID, CounterpartyID: request.ToID}); err != nil {
+		return apiErrorFor(err)
+	}
+
+	if err := api.store.MergeAccounts(request.Timestamp, request.FromID, request.ToID); err != nil {
+		return apiErrorFor(err)
+	}
+	return http.StatusNoContent, nil
+}
+
+func (api *httpAPI) quoteTransfer(r *http.Request) (int, any) {
+	var request QuoteTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return invalidRequest(err)
+	}
+	if err := api.admit(r, OperationQuoteTransfer, Resource{AccountID: request.FromID, CounterpartyID: request.ToID, Amount: request.Amount}); err != nil {
+		return apiErrorFor(err)
+	}
+
+	quote, err := api.store.QuoteTransfer(request.FromID, request.ToID, request.Amount)
+	if err != nil {
+		return apiErrorFor(err)
+	}
+	return http.StatusCreated, quote
+}
+
+func (api *httpAPI) executeQuote(r *http.Request) (int, any) {
+	var request ExecuteQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return invalidRequest(err)
+	}
+	quoteID := r.PathValue("id")
+	if err := api.admit(r, OperationTransfer, api.store.quoteResource(quoteID)); err != nil {
+		return apiErrorFor(err)
+	}
+
+	if err := api.store.TransferWithQuote(request.Timestamp, quoteID); err != nil {
+		return apiErrorFor(err)
+	}
+	return http.StatusNoContent, nil
+}
+
+func invalidRequest(err error) (int, any) {
+	return http.StatusBadRequest, APIError{Code: CodeInvalidRequest, Message: err.Error()}
+}
+
+func encodeBody(body any) []byte {
+	if body == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		encoded, _ = json.Marshal(APIError{Code: CodeInternal, Message: err.Error()})
+	}
+	return encoded
+}
+
+func writeJSON(w http.ResponseWriter, status int, body []byte) {
+	if body != nil {
+		w.Header().Set("Content-Type", "application/json")
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// idempotencyCache remembers the response to each idempotency key for ttl from when the
+// response was stored. A key whose request is still being handled has no response yet, and
+// does not expire until it has one.
+type idempotencyCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	clock   Clock
+	entries map[string]*idempotencyEntry
+	order   []*idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	key      string
+	storedAt time.Time
+	response *idempotentResponse
+}
+
+type idempotentResponse struct {
+	status    int
+	body      []byte
+	admission admission
+}
+
+func newIdempotencyCache(ttl time.Duration, clock Clock) *idempotencyCache {
+	return &idempotencyCache{
+		ttl:     ttl,
+		clock:   clock,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+// begin reports the stored response for key, or reserves key for the caller if it is new.
+func (c *idempotencyCache) begin(key string) (*idempotentResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	c.forgetBefore(now.Add(-c.ttl))
+
+	if entry, found := c.entries[key]; found {
+		return entry.response, true
+	}
+	c.entries[key] = &idempotencyEntry{key: key}
+	return nil, false
+}
+
+// finish stores the response for a key reserved by begin. Server errors and rate limits release
+// the key instead, so that a retry gets another chance.
+func (c *idempotencyCache) finish(key string, status int, body []byte, admitted admission) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, reserved := c.entries[key]
+	if !reserved || entry.response != nil {
+		return
+	}
+	if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+		delete(c.entries, key)
+		return
+	}
+	entry.response = &idempotentResponse{status: status, body: body, admission: admitted}
+	entry.storedAt = c.clock.Now()
+	c.order = append(c.order, entry)
+}
+
+// sweep forgets expired keys and returns how many it forgot.
+func (c *idempotencyCache) sweep() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.forgetBefore(c.clock.Now().Add(-c.ttl))
+}
+
+func (c *idempotencyCache) forgetBefore(cutoff time.Time) int {
+	expired, forgotten := 0, 0
+	for expired < len(c.order) && c.order[expired].storedAt.Before(cutoff) {
+		entry := c.order[expired]
+		if c.entries[entry.key] == entry {
+			delete(c.entries, entry.key)
+			forgotten++
+		}
+		expired++
+	}
+	c.order = c.order[expired:]
+	return forgotten
+}
@@ -0,0 +1,131 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	send := func(handler http.Handler, method, path, idempotencyKey string, body any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		request := httptest.NewRequest(method, path, bytes.NewReader(encoded))
+		if idempotencyKey != "" {
+			request.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	t.Run("Creates And Reads Accounts", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		handler := NewHTTPHandler(store)
+		accountID := randomAccountID()
+
+		// ACT
+		created := send(handler, http.MethodPost, "/accounts", "", CreateAccountRequest{Timestamp: 1, AccountID: accountID, InitialBalance: 100})
+		read := send(handler, http.MethodGet, "/accounts/"+accountID, "", nil)
+
+		// ASSERT
+		assert.Equal(t, http.StatusCreated, created.Code, "status mismatch")
+		assert.Equal(t, http.StatusOK, read.Code, "status mismatch")
+		var account AccountSnapshot
+		assert.NoError(t, json.Unmarshal(read.Body.Bytes(), &account), "unexpected error decoding account")
+		assert.Equal(t, AccountSnapshot{AccountID: accountID, UpdatedAt: 1, Balance: 100, AvailableBalance: 100}, account, "account mismatch")
+	})
+
+	t.Run("Reports Error Codes", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		handler := NewHTTPHandler(store)
+		fromID := randomAccountID()
+		toID := randomAccountID()
+		store.CreateAccount(1, fromID, 100)
+		store.CreateAccount(1, toID, 100)
+
+		// ACT
+		response := send(handler, http.MethodPost, "/transfers", "", TransferRequest{Timestamp: 2, FromID: fromID, ToID: toID, Amount: 500})
+
+		// ASSERT
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code, "status mismatch")
+		var apiError APIError
+		assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &apiError), "unexpected error decoding error")
+		assert.Equal(t, APIError{
+			Code:    CodeInsufficientBalance,
+			Message: "insufficient balance in the from account",
+			Params:  map[string]any{ParamAccountID: fromID, ParamAmount: float64(500), ParamAvailable: float64(100)},
+		}, apiError, "error mismatch")
+		assert.ErrorIs(t, ErrorForCode(apiError.Code, apiError.Message), ErrInsufficientBalance, "expected the sentinel back")
+		var detailed *Error
+		assert.ErrorAs(t, apiError.Err(), &detailed, "expected the parameters back")
+		assert.ErrorIs(t, detailed, ErrInsufficientBalance, "expected the sentinel back")
+	})
+
+	t.Run("Rejects Reserved Account IDs", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		handler := NewHTTPHandler(store)
+
+		// ACT
+		response := send(handler, http.MethodPost, "/accounts", "", CreateAccountRequest{Timestamp: 1, AccountID: SystemFeeIncome, InitialBalance: 100})
+
+		// ASSERT
+		assert.Equal(t, http.StatusUnprocessableEntity, response.Code, "status mismatch")
+		var apiError APIError
+		assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &apiError), "unexpected error decoding error")
+		assert.Equal(t, CodeReservedAccountID, apiError.Code, "code mismatch")
+	})
+
+	t.Run("Replays Idempotent Requests", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1, 0))
+		store := NewAccountStore(WithClock(clock))
+		handler := NewHTTPHandler(store)
+		fromID := randomAccountID()
+		toID := randomAccountID()
+		store.CreateAccount(1, fromID, 100)
+		store.CreateAccount(1, toID, 100)
+		transfer := TransferRequest{Timestamp: 2, FromID: fromID, ToID: toID, Amount: 10}
+
+		// ACT
+		first := send(handler, http.MethodPost, "/transfers", "key", transfer)
+		retry := send(handler, http.MethodPost, "/transfers", "key", transfer)
+		clock.Advance(DefaultIdempotencyTTL + time.Second)
+		afterExpiry := send(handler, http.MethodPost, "/transfers", "key", transfer)
+
+		// ASSERT
+		assert.Equal(t, http.StatusNoContent, first.Code, "status mismatch")
+		assert.Equal(t, http.StatusNoContent, retry.Code, "retry should replay the first response")
+		assert.Equal(t, http.StatusNoContent, afterExpiry.Code, "status mismatch")
+		account, _ := store.GetAccount(fromID)
+		assert.Equal(t, float64(80), account.Balance, "expected the retry to be applied once and the expired key again")
+	})
+
+	t.Run("Keeps In-Flight Keys Through Expiry", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1, 0))
+		cache := newIdempotencyCache(time.Minute, clock)
+		cache.begin("key")
+
+		// ACT
+		clock.Advance(time.Hour)
+		swept := cache.sweep()
+		_, inProgress := cache.begin("key")
+		cache.finish("key", http.StatusNoContent, nil, admission{})
+		cache.finish("unknown", http.StatusNoContent, nil, admission{})
+		response, found := cache.begin("key")
+
+		// ASSERT
+		assert.Equal(t, 0, swept, "in-flight keys should not expire")
+		assert.True(t, inProgress, "expected the key to still be reserved")
+		assert.True(t, found, "expected the finished key to be remembered")
+		assert.Equal(t, http.StatusNoContent, response.status, "status mismatch")
+	})
+}
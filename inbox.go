@@ -0,0 +1,111 @@
+package bankingsystem
+
+import (
+	"errors"
+)
+
+// DefaultInboxCapacity is how many unacknowledged events an account's inbox holds unless
+// WithInboxCapacity says otherwise.
+const DefaultInboxCapacity = 100
+
+// ErrInboxOverflowed is returned by FetchEvents when events after sinceSeq were dropped from
+// the inbox before they were acknowledged. The client should reload the account, which
+// reflects every event, and acknowledge the events returned with the error.
+var ErrInboxOverflowed = errors.New("inbox dropped unacknowledged events")
+
+// accountInbox holds an account's most recent unacknowledged events, oldest first.
+type accountInbox struct {
+	events []Event
+	// dropped is the sequence number of the latest event dropped without being acknowledged.
+	dropped int
+}
+
+// WithInboxCapacity bounds every account's inbox to capacity events. Once an inbox is full
+// the oldest unacknowledged event makes room for the new one.
+func WithInboxCapacity(capacity int) Option {
+	return func(s *AccountStore) {
+		if capacity > 0 {
+			s.inboxCapacity = capacity
+		}
+	}
+}
+
+// deliverToInboxes adds event to the inbox of every live account it involves, leaving out
+// system accounts and accounts the event removed. Callers must hold the write lock.
+func (s *AccountStore) deliverToInboxes(event Event) {
+	accountIDs := append([]string{event.AccountID, event.CounterpartyID}, event.SourceIDs...)
+	seen := make(map[string]bool, len(accountIDs))
+	for _, accountID := range accountIDs {
+		if accountID == "" || seen[accountID] {
+			continue
+		}
+		seen[accountID] = true
+		if _, exists := s.accounts.lookup(accountID); !exists {
+			continue
+		}
+
+		inbox, exists := s.inboxes[accountID]
+		if !exists {
+			inbox = &accountInbox{}
+			s.inboxes[accountID] = inbox
+		}
+		if len(inbox.events) >= s.inboxCapacity {
+			inbox.dropped = inbox.events[0].Seq
+			inbox.events = inbox.events[1:]
+		}
+		inbox.events = append(inbox.events, event)
+	}
+}
+
+// FetchEvents returns the events in the account's inbox with a sequence number above sinceSeq,
+// oldest first, along with ErrInboxOverflowed if some were dropped. Events stay in the inbox
+// until AcknowledgeEvents removes them, so a client that loses a response can fetch the same
+// events again. The inbox is kept in memory, starts empty when the store is restored and goes
+// away with the account, as when it is merged into another.
+func (s *AccountStore) FetchEvents(accountID string, sinceSeq int) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	inbox, exists := s.inboxes[accountID]
+	if !exists {
+		if _, exists := s.accounts.lookup(accountID); !exists {
+			return nil, ErrAccountNotFound
+		}
+		return nil, nil
+	}
+	var events []Event
+	for _, event := range inbox.events {
+		if event.Seq > sinceSeq {
+			events = append(events, event)
+		}
+	}
+	if sinceSeq < inbox.dropped {
+		return events, ErrInboxOverflowed
+	}
+	return events, nil
+}
+
+// AcknowledgeEvents removes the events up to and including seq from the account's inbox, once
+// the client has applied them.
+func (s *AccountStore) AcknowledgeEvents(accountID string, seq int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceAcknowledgeEvents, AccountID: accountID, Value: seq})
+
+	inbox, exists := s.inboxes[accountID]
+	if !exists {
+		if _, exists := s.accounts.lookup(accountID); !exists {
+			return ErrAccountNotFound
+		}
+		return nil
+	}
+	acknowledged := 0
+	for acknowledged < len(inbox.events) && inbox.events[acknowledged].Seq <= seq {
+		acknowledged++
+	}
+	inbox.events = append([]Event(nil), inbox.events[acknowledged:]...)
+	if seq >= inbox.dropped {
+		inbox.dropped = 0
+	}
+	return nil
+}
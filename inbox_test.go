@@ -0,0 +1,76 @@
+package bankingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInbox(t *testing.T) {
+	t.Run("Fetches And Acknowledges Events", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 100)
+		store.CreateAccount(2, "b", 0)
+		store.Transfer(3, "a", "b", 40)
+
+		// ACT
+		all, err := store.FetchEvents("b", 0)
+		assert.NoError(t, err, "unexpected error fetching events")
+		assert.NoError(t, store.AcknowledgeEvents("b", all[0].Seq), "unexpected error acknowledging events")
+		remaining, _ := store.FetchEvents("b", 0)
+
+		// ASSERT
+		assert.Len(t, all, 2, "expected the creation and the transfer")
+		assert.Equal(t, EventAccountCreated, all[0].Type, "event type mismatch")
+		assert.Equal(t, EventTransfer, all[1].Type, "event type mismatch")
+		assert.Equal(t, all[1:], remaining, "acknowledged events should be removed")
+		since, _ := store.FetchEvents("a", all[1].Seq-1)
+		assert.Len(t, since, 1, "expected only events after sinceSeq")
+		_, err = store.FetchEvents("missing", 0)
+		assert.ErrorIs(t, err, ErrAccountNotFound, "expected unknown account to fail")
+	})
+
+	t.Run("Reports Dropped Events", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithInboxCapacity(2))
+		store.CreateAccount(1, "a", 100)
+		store.CreateAccount(1, "b", 0)
+		for i := 0; i < 3; i++ {
+			store.Transfer(2+i, "a", "b", 10)
+		}
+
+		// ACT
+		_, overflowed := store.FetchEvents("b", 0)
+		latest, err := store.FetchEvents("a", 0)
+		store.AcknowledgeEvents("a", latest[len(latest)-1].Seq)
+		afterResync, resyncErr := store.FetchEvents("a", 0)
+
+		// ASSERT
+		assert.ErrorIs(t, overflowed, ErrInboxOverflowed, "expected dropped events to be reported")
+		assert.ErrorIs(t, err, ErrInboxOverflowed, "expected dropped events to be reported")
+		assert.Len(t, latest, 2, "expected the events still in the inbox")
+		assert.NoError(t, resyncErr, "acknowledging past the dropped events should clear the overflow")
+		assert.Empty(t, afterResync, "expected an empty inbox")
+	})
+
+	t.Run("Drops The Inboxes Of Merged Accounts", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 100)
+		store.CreateAccount(1, "b", 0)
+		store.CreateAccount(1, "c", 0)
+
+		// ACT
+		store.MergeAccounts(2, "a", "b")
+		store.MergeAccountsMany(3, []string{"c"}, "b")
+
+		// ASSERT
+		assert.NotContains(t, store.inboxes, "a", "expected the merged account's inbox to be gone")
+		assert.NotContains(t, store.inboxes, "c", "expected the merged account's inbox to be gone")
+		_, err := store.FetchEvents("a", 0)
+		assert.ErrorIs(t, err, ErrAccountNotFound, "expected the merged account to be unknown")
+		events, _ := store.FetchEvents("b", 0)
+		assert.Len(t, events, 3, "expected the creation and both merges in the surviving inbox")
+	})
+}
@@ -0,0 +1,73 @@
+package bankingsystem
+
+import (
+	"math"
+	"sort"
+)
+
+// ForEachAccount calls fn with every account, ordered by ID, until fn returns false. The
+// accounts are copied under the read lock in one step, so fn sees them all as of the same
+// instant, and it runs without any lock held: fn may call back into the store, and writes
+// made meanwhile are not seen.
+func (s *AccountStore) ForEachAccount(fn func(AccountSnapshot) bool) {
+	s.mu.RLock()
+	accounts := make([]AccountSnapshot, 0, s.accounts.len())
+	for _, account := range s.accounts.all() {
+		accounts = append(accounts, s.liveSnapshot(account))
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].AccountID < accounts[j].AccountID
+	})
+	for _, account := range accounts {
+		if !fn(account) {
+			return
+		}
+	}
+}
+
+// ForEachTransaction calls fn with every event in the history, archived events included, in
+// sequence order, until fn returns false. The traversal covers exactly the events committed
+// before the call: the read lock is held only to take the position of the end of the history,
+// not while events are read or fn runs, so fn may call back into the store and events
+// committed meanwhile are not seen. Archived events are read from the archive once the lock is
+// released.
+func (s *AccountStore) ForEachTransaction(fn func(Event) bool) error {
+	s.mu.RLock()
+	// Committed events are never changed in place, and archival replaces the slice rather than
+	// rewriting it, so the slice header alone is a stable view of the hot events.
+	hot := s.events[:len(s.events):len(s.events)]
+	archive, archived := s.archive, s.archivedEvents
+	// Archived events from end onwards were archived after the call, and are among the hot
+	// events or were not committed before it.
+	end := s.lastSeq + 1
+	if len(hot) > 0 {
+		end = hot[0].Seq
+	}
+	s.mu.RUnlock()
+
+	if archive != nil && archived > 0 {
+		events, err := archive.Range(historyStart, math.MaxInt)
+		if err != nil {
+			return err
+		}
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].Seq < events[j].Seq
+		})
+		for _, event := range events {
+			if event.Seq >= end {
+				break
+			}
+			if !fn(event) {
+				return nil
+			}
+		}
+	}
+	for _, event := range hot {
+		if !fn(event) {
+			return nil
+		}
+	}
+	return nil
+}
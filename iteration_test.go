@@ -0,0 +1,93 @@
+package bankingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachAccount(t *testing.T) {
+	t.Run("Visits Accounts In Order", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "c", 30)
+		store.CreateAccount(10, "a", 10)
+		store.CreateAccount(10, "b", 20)
+		var visited []string
+
+		// ACT
+		store.ForEachAccount(func(account AccountSnapshot) bool {
+			visited = append(visited, account.AccountID)
+			return account.AccountID != "b"
+		})
+
+		// ASSERT
+		assert.Equal(t, []string{"a", "b"}, visited, "iteration should be ordered and stop when asked")
+	})
+
+	t.Run("Ignores Writes During Iteration", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 0)
+		balances := map[string]float64{}
+
+		// ACT
+		store.ForEachAccount(func(account AccountSnapshot) bool {
+			if account.AccountID == "a" {
+				store.Transfer(20, "a", "b", 100)
+				store.CreateAccount(20, "c", 0)
+			}
+			balances[account.AccountID] = account.Balance
+			return true
+		})
+
+		// ASSERT
+		assert.Equal(t, map[string]float64{"a": 100, "b": 0}, balances, "iteration should see the accounts as of the call")
+	})
+}
+
+func TestForEachTransaction(t *testing.T) {
+	t.Run("Visits Archived And Hot Events Once", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		assert.NoError(t, store.EnableArchival(NewMemoryArchive(), 1), "unexpected error enabling archival")
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 0)
+		store.ArchiveTransactions(10 + 2*24*60*60)
+		store.Transfer(200000, "a", "b", 10)
+		var seqs []int
+
+		// ACT
+		err := store.ForEachTransaction(func(event Event) bool {
+			seqs = append(seqs, event.Seq)
+			if event.Seq == 1 {
+				store.ArchiveTransactions(400000)
+				store.Transfer(400000, "a", "b", 10)
+			}
+			return true
+		})
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during iteration")
+		assert.Equal(t, []int{1, 2, 3}, seqs, "iteration should cover the history as of the call")
+	})
+
+	t.Run("Stops When Asked", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 0)
+		visited := 0
+
+		// ACT
+		err := store.ForEachTransaction(func(event Event) bool {
+			visited++
+			return false
+		})
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during iteration")
+		assert.Equal(t, 1, visited, "iteration should stop after the first event")
+	})
+}
@@ -0,0 +1,187 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// AccountState is where an account is in its lifecycle.
+type AccountState string
+
+const (
+	StateActive   AccountState = "ACTIVE"
+	StateFrozen   AccountState = "FROZEN"
+	StateClosed   AccountState = "CLOSED"
+	StateMerged   AccountState = "MERGED"
+	StateArchived AccountState = "ARCHIVED"
+)
+
+// ReasonCode says why an account changed state.
+type ReasonCode string
+
+const (
+	ReasonCustomerRequest ReasonCode = "customer_request"
+	ReasonFraudSuspected  ReasonCode = "fraud_suspected"
+	ReasonComplianceHold  ReasonCode = "compliance_hold"
+	ReasonResolved        ReasonCode = "resolved"
+	ReasonDormant         ReasonCode = "dormant"
+	ReasonRetention       ReasonCode = "retention"
+	// ReasonMerged is used for the MERGED transitions recorded by MergeAccounts and
+	// MergeAccountsMany.
+	ReasonMerged ReasonCode = "merged"
+)
+
+var (
+	ErrInvalidTransition     = errors.New("invalid account state transition")
+	ErrOperationNotPermitted = errors.New("operation not permitted in the account's state")
+)
+
+// operation is a kind of change checked against an account's state.
+type operation string
+
+const (
+	opDebit  operation = "debit"
+	opCredit operation = "credit"
+	opMerge  operation = "merge"
+	opModify operation = "modify"
+)
+
+// permitted is the state/operation matrix. Anything missing is rejected.
+//
+//	state     debit  credit  merge  modify
+//	ACTIVE    yes    yes     yes    yes
+//	FROZEN    no     yes     no     yes
+//	CLOSED    no     no      no     no
+//	ARCHIVED  no     no      no     no
+//
+// Debits are transfers out, scheduled payments and holds; credits are transfers in; merges
+// cover both sides of a merge; modifications are redenomination. MERGED accounts no longer
+// exist, so every operation on them fails with ErrAccountNotFound.
+var permitted = map[AccountState]map[operation]bool{
+	StateActive: {opDebit: true, opCredit: true, opMerge: true, opModify: true},
+	StateFrozen: {opCredit: true, opModify: true},
+}
+
+// transitions lists the states each state may move to with TransitionAccount.
+var transitions = map[AccountState][]AccountState{
+	StateActive: {StateFrozen, StateClosed},
+	StateFrozen: {StateActive, StateClosed},
+	StateClosed: {StateArchived},
+}
+
+// LifecycleTransition is one change of an account's state.
+type LifecycleTransition struct {
+	Seq       int
+	Timestamp int
+	From      AccountState
+	To        AccountState
+	Reason    ReasonCode
+	Actor     string
+}
+
+// State returns the account's lifecycle state.
+func (a *Account) State() AccountState {
+	if a.state == "" {
+		return StateActive
+	}
+	return a.state
+}
+
+// checkOperation rejects op if the account's state does not permit it.
+func checkOperation(account *Account, op operation) error {
+	if !permitted[account.State()][op] {
+		return fmt.Errorf("%w: %s on %s account %s", ErrOperationNotPermitted, op, account.State(), account.accountID)
+	}
+	return nil
+}
+
+// TransitionAccount moves the account to state for reason, on behalf of actor, and records an
+// EventAccountStateChanged event. Closing requires a zero balance and no pending payments.
+// MERGED is only reached through MergeAccounts and MergeAccountsMany.
+func (s *AccountStore) TransitionAccount(timestamp int, accountID string, to AccountState, reason ReasonCode, actor string) error {
+	if reason == "" {
+		return errors.New("reason code is required")
+	}
+	if actor == "" {
+		return errors.New("actor is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceTransitionAccount, Timestamp: timestamp, AccountID: accountID, State: to, Reason: reason, Actor: actor})
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists {
+		return ErrAccountNotFound
+	}
+	from := account.State()
+	if !canTransition(from, to) {
+		return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, from, to)
+	}
+	if to == StateClosed {
+		if account.balance != 0 {
+			return fmt.Errorf("cannot close account %s with a balance of %v", accountID, account.balance)
+		}
+		if pending := len(s.pendingByAccount[accountID]); pending > 0 {
+			return fmt.Errorf("cannot close account %s with %d pending payments", accountID, pending)
+		}
+	}
+
+	updated := account.snapshot()
+	updated.State = to
+	if to == StateActive {
+		updated.State = ""
+	}
+	updated.UpdatedAt = timestamp
+	if err := s.persist(StorageBatch{Put: []AccountSnapshot{updated}}); err != nil {
+		return err
+	}
+	account.restore(updated)
+	s.record(Event{Timestamp: timestamp, Type: EventAccountStateChanged, AccountID: accountID, State: to, Reason: reason, Actor: actor})
+	return nil
+}
+
+func canTransition(from, to AccountState) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountLifecycle returns every state the account has been in, oldest first, read from the
+// event history. It covers merged-away accounts as well as live ones.
+func (s *AccountStore) AccountLifecycle(accountID string) ([]LifecycleTransition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events, err := s.history(historyStart, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+
+	var lifecycle []LifecycleTransition
+	state := AccountState("")
+	add := func(event Event, to AccountState, reason ReasonCode, actor string) {
+		lifecycle = append(lifecycle, LifecycleTransition{Seq: event.Seq, Timestamp: event.Timestamp, From: state, To: to, Reason: reason, Actor: actor})
+		state = to
+	}
+	for _, event := range events {
+		switch {
+		case event.Type == EventAccountCreated && event.AccountID == accountID:
+			state = ""
+			add(event, StateActive, "", "")
+		case event.Type == EventAccountStateChanged && event.AccountID == accountID:
+			add(event, event.State, event.Reason, event.Actor)
+		case event.Type == EventAccountsMerged && event.AccountID == accountID,
+			event.Type == EventAccountsMergedMany && event.involves(accountID) && event.CounterpartyID != accountID:
+			add(event, StateMerged, ReasonMerged, "")
+		}
+	}
+	if len(lifecycle) == 0 {
+		return nil, ErrAccountNotFound
+	}
+	return lifecycle, nil
+}
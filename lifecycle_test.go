@@ -0,0 +1,100 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitionAccount(t *testing.T) {
+	t.Run("Frozen Accounts Receive But Do Not Send", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 1000)
+
+		// ACT
+		err := store.TransitionAccount(2, "a", StateFrozen, ReasonFraudSuspected, "analyst-1")
+		_, sendErr := store.Transfer(3, "a", "b", 10)
+		_, receiveErr := store.Transfer(3, "b", "a", 10)
+		_, scheduleErr := store.SchedulePayment(3, "a", 10, 60)
+		_, holdErr := store.PlaceHold(3, "a", 10)
+		mergeErr := store.MergeAccounts(3, "a", "b")
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error freezing account")
+		assert.EqualError(t, sendErr, "operation not permitted in the account's state: debit on FROZEN account a", "unexpected error message")
+		assert.NoError(t, receiveErr, "frozen accounts should still receive transfers")
+		assert.ErrorIs(t, scheduleErr, ErrOperationNotPermitted, "scheduling should be rejected")
+		assert.ErrorIs(t, holdErr, ErrOperationNotPermitted, "holds should be rejected")
+		assert.ErrorIs(t, mergeErr, ErrOperationNotPermitted, "merges should be rejected")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, StateFrozen, account.State, "state mismatch")
+	})
+
+	t.Run("Pending Payments Fail Once Frozen", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(100, "a", 1000)
+		paymentID, _ := store.SchedulePayment(100, "a", 10, 60)
+		store.TransitionAccount(110, "a", StateFrozen, ReasonComplianceHold, "analyst-1")
+
+		// ACT
+		clock.Advance(time.Minute)
+
+		// ASSERT
+		attempts, _ := store.GetPaymentAttempts(*paymentID)
+		assert.Equal(t, FailureAccountInactive, attempts[0].FailureReason, "failure reason mismatch")
+	})
+
+	t.Run("Invalid Transitions", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "empty", 0)
+
+		// ACT
+		archiveErr := store.TransitionAccount(2, "a", StateArchived, ReasonRetention, "ops")
+		mergedErr := store.TransitionAccount(2, "a", StateMerged, ReasonMerged, "ops")
+		closeErr := store.TransitionAccount(2, "a", StateClosed, ReasonCustomerRequest, "ops")
+		reasonErr := store.TransitionAccount(2, "a", StateFrozen, "", "ops")
+		store.TransitionAccount(2, "empty", StateClosed, ReasonCustomerRequest, "ops")
+		reopenErr := store.TransitionAccount(3, "empty", StateActive, ReasonResolved, "ops")
+		_, creditErr := store.Transfer(3, "a", "empty", 10)
+
+		// ASSERT
+		assert.EqualError(t, archiveErr, "invalid account state transition from ACTIVE to ARCHIVED", "unexpected error message")
+		assert.ErrorIs(t, mergedErr, ErrInvalidTransition, "merged is only reached by merging")
+		assert.EqualError(t, closeErr, "cannot close account a with a balance of 1000", "unexpected error message")
+		assert.EqualError(t, reasonErr, "reason code is required", "unexpected error message")
+		assert.ErrorIs(t, reopenErr, ErrInvalidTransition, "closed accounts cannot reopen")
+		assert.ErrorIs(t, creditErr, ErrOperationNotPermitted, "closed accounts cannot receive")
+	})
+}
+
+func TestAccountLifecycle(t *testing.T) {
+	// ARRANGE
+	store := NewAccountStore()
+	store.CreateAccount(1, "a", 0)
+	store.CreateAccount(1, "b", 0)
+	store.TransitionAccount(2, "a", StateFrozen, ReasonFraudSuspected, "analyst-1")
+	store.TransitionAccount(3, "a", StateActive, ReasonResolved, "analyst-2")
+	store.MergeAccounts(4, "a", "b")
+
+	// ACT
+	lifecycle, err := store.AccountLifecycle("a")
+
+	// ASSERT
+	assert.NoError(t, err, "unexpected error reading lifecycle")
+	assert.Equal(t, []LifecycleTransition{
+		{Seq: 1, Timestamp: 1, To: StateActive},
+		{Seq: 3, Timestamp: 2, From: StateActive, To: StateFrozen, Reason: ReasonFraudSuspected, Actor: "analyst-1"},
+		{Seq: 4, Timestamp: 3, From: StateFrozen, To: StateActive, Reason: ReasonResolved, Actor: "analyst-2"},
+		{Seq: 5, Timestamp: 4, From: StateActive, To: StateMerged, Reason: ReasonMerged},
+	}, lifecycle, "lifecycle mismatch")
+	view, _ := store.StateAt(2)
+	frozen, _ := view.Account("a")
+	assert.Equal(t, StateFrozen, frozen.State, "history should replay the state change")
+}
@@ -0,0 +1,66 @@
+package bankingsystem
+
+// PauseScheduling stops scheduled payments from executing, for example during a maintenance
+// window. Payments falling due while scheduling is paused are held in a queue, where they can
+// still be cancelled, until ResumeScheduling. Everything else, including transfers, keeps
+// working.
+func (s *AccountStore) PauseScheduling() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TracePauseScheduling})
+
+	s.schedulingPaused = true
+}
+
+// ResumeScheduling executes the payments held while scheduling was paused, as one batch in due
+// and then priority order, and lets later payments execute as they fall due. Held payments that
+// fail are retried as usual.
+func (s *AccountStore) ResumeScheduling() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceResumeScheduling})
+
+	if !s.schedulingPaused {
+		return
+	}
+	s.schedulingPaused = false
+	held := s.heldPayments
+	s.heldPayments = nil
+	if len(held) > 0 {
+		s.runPayments(int(s.clock.Now().Unix()), held)
+	}
+}
+
+// SchedulingPaused reports whether PauseScheduling is in effect.
+func (s *AccountStore) SchedulingPaused() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.schedulingPaused
+}
+
+// holdPayment queues a due payment until scheduling resumes. Callers must hold the write lock.
+func (s *AccountStore) holdPayment(payment *scheduledPayment) {
+	// Outstanding timer callbacks for the payment are stale from now on.
+	payment.armed++
+	payment.timer = &heldTimer{store: s, payment: payment}
+	s.heldPayments = append(s.heldPayments, payment)
+}
+
+// heldTimer is the handle of a payment held by PauseScheduling. Stopping it takes the payment
+// out of the queue.
+type heldTimer struct {
+	store   *AccountStore
+	payment *scheduledPayment
+}
+
+// Stop is called with the store's write lock held.
+func (t *heldTimer) Stop() bool {
+	for i, payment := range t.store.heldPayments {
+		if payment == t.payment {
+			t.store.heldPayments = append(t.store.heldPayments[:i], t.store.heldPayments[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPauseScheduling(t *testing.T) {
+	t.Run("Holds Due Payments Until Resumed", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(8000, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(8000, "a", 100)
+		late, _ := store.SchedulePayment(8000, "a", 70, 20)
+		early, _ := store.SchedulePayment(8000, "a", 70, 10)
+		store.PauseScheduling()
+
+		// ACT
+		clock.Advance(time.Minute)
+		paused, _ := store.GetAccount("a")
+		store.ResumeScheduling()
+
+		// ASSERT
+		assert.Equal(t, float64(100), paused.Balance, "no payment should execute while paused")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(30), account.Balance, "one payment should execute on resume")
+		earlyAttempts, _ := store.GetPaymentAttempts(*early)
+		lateAttempts, _ := store.GetPaymentAttempts(*late)
+		assert.Equal(t, PaymentExecuted, earlyAttempts[0].Outcome, "the earliest due payment should execute first")
+		assert.Equal(t, FailureInsufficientFunds, lateAttempts[0].FailureReason, "the later payment should fail")
+		assert.False(t, store.SchedulingPaused(), "scheduling should be resumed")
+	})
+
+	t.Run("Holds Batched Payments", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(8040, 0))
+		store := NewAccountStore(WithClock(clock), WithPaymentBatching(time.Minute))
+		store.CreateAccount(8040, "a", 100)
+		paymentID, _ := store.SchedulePayment(8040, "a", 40, 10)
+		store.PauseScheduling()
+
+		// ACT
+		clock.Advance(2 * time.Minute)
+		attemptsWhilePaused, _ := store.GetPaymentAttempts(*paymentID)
+		store.ResumeScheduling()
+
+		// ASSERT
+		assert.Empty(t, attemptsWhilePaused, "batch should not run while paused")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(60), account.Balance, "payment should execute on resume")
+	})
+
+	t.Run("Cancels Held Payments", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(8000, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(8000, "a", 100)
+		paymentID, _ := store.SchedulePayment(8000, "a", 40, 10)
+		store.PauseScheduling()
+		clock.Advance(time.Minute)
+
+		// ACT
+		err := store.CancelScheduledPayment(*paymentID)
+		store.ResumeScheduling()
+
+		// ASSERT
+		assert.NoError(t, err, "held payments should be cancellable")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(100), account.Balance, "cancelled payment should not execute")
+	})
+
+	t.Run("Runs Later Payments Normally After Resume", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(8000, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(8000, "a", 100)
+		store.PauseScheduling()
+		store.ResumeScheduling()
+		paymentID, _ := store.SchedulePayment(8000, "a", 40, 10)
+
+		// ACT
+		clock.Advance(10 * time.Second)
+
+		// ASSERT
+		attempts, _ := store.GetPaymentAttempts(*paymentID)
+		assert.Len(t, attempts, 1, "payment should execute when due")
+		assert.ErrorIs(t, store.CancelScheduledPayment(*paymentID), ErrPaymentNotCancellable, "executed payments should not be cancellable")
+	})
+}
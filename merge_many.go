@@ -0,0 +1,112 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MergeAccountsMany merges every account in fromIDs into toID as one operation, for bulk
+// consolidation after an acquisition or import. Either every source merges or none does: all
+// accounts are checked first, written as a single Storage batch and recorded as one
+// EventAccountsMergedMany event. Unlike MergeAccounts it also moves each source's pending
+// payments and counterparty history to toID, so payments scheduled on a source still run.
+func (s *AccountStore) MergeAccountsMany(timestamp int, fromIDs []string, toID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceMergeAccountsMany, Timestamp: timestamp, IDs: fromIDs, CounterpartyID: toID})
+
+	sources, toAccount, err := s.validateMergeMany(fromIDs, toID)
+	if err != nil {
+		return err
+	}
+
+	merged := toAccount.snapshot()
+	moved := float64(0)
+	for _, fromAccount := range sources {
+		merged.Balance += fromAccount.balance
+		merged.TotalTransferred += fromAccount.totalTransferred
+		moved += fromAccount.balance
+	}
+	merged.UpdatedAt = timestamp
+
+	deleted := append([]string(nil), fromIDs...)
+	if err := s.persist(StorageBatch{Put: []AccountSnapshot{merged}, Delete: deleted}); err != nil {
+		return err
+	}
+	toAccount.restore(merged)
+	for _, fromAccount := range sources {
+		s.moveHolds(fromAccount, toAccount)
+		s.movePayments(fromAccount, toAccount)
+		s.moveCounterparties(fromAccount.accountID, toID)
+		s.removeAccount(fromAccount.accountID)
+	}
+
+	s.record(Event{Timestamp: timestamp, Type: EventAccountsMergedMany, CounterpartyID: toID, SourceIDs: deleted, Amount: moved})
+	return nil
+}
+
+func (s *AccountStore) validateMergeMany(fromIDs []string, toID string) ([]*Account, *Account, error) {
+	if len(fromIDs) == 0 {
+		return nil, nil, errors.New("no accounts to merge")
+	}
+
+	sources := make([]*Account, 0, len(fromIDs))
+	seen := make(map[string]bool, len(fromIDs))
+	var toAccount *Account
+	for _, fromID := range fromIDs {
+		if fromID == toID {
+			return nil, nil, fmt.Errorf("cannot merge account %s into itself", toID)
+		}
+		if seen[fromID] {
+			return nil, nil, fmt.Errorf("account %s is listed more than once", fromID)
+		}
+		seen[fromID] = true
+
+		fromAccount, to, err := s.validateMerge(fromID, toID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("merging %s: %w", fromID, err)
+		}
+		sources = append(sources, fromAccount)
+		toAccount = to
+	}
+	return sources, toAccount, nil
+}
+
+// movePayments reassigns the pending payments of fromAccount to toAccount, keeping the pending
+// counters in step. Callers must hold the write lock.
+func (s *AccountStore) movePayments(fromAccount, toAccount *Account) {
+	for _, payment := range s.scheduledPayments {
+		if payment.accountID != fromAccount.accountID || payment.executed {
+			continue
+		}
+		s.markDone(payment)
+		payment.accountID = toAccount.accountID
+		payment.tenantID = toAccount.tenantID
+		s.markPending(payment)
+	}
+}
+
+// moveCounterparties folds fromID's counterparty history into toID's and points every other
+// account's history of fromID at toID. Transfers between the two are dropped, since they are
+// now internal to one account. Callers must hold the write lock.
+func (s *AccountStore) moveCounterparties(fromID, toID string) {
+	for counterpartyID, movements := range s.counterparties[fromID] {
+		if counterpartyID == toID {
+			continue
+		}
+		for _, movement := range movements {
+			s.addCounterpartyMovement(toID, counterpartyID, movement)
+		}
+	}
+	delete(s.counterparties, fromID)
+	delete(s.counterparties[toID], fromID)
+
+	for accountID, byCounterparty := range s.counterparties {
+		movements, exists := byCounterparty[fromID]
+		if !exists || accountID == toID {
+			continue
+		}
+		byCounterparty[toID] = append(byCounterparty[toID], movements...)
+		delete(byCounterparty, fromID)
+	}
+}
@@ -0,0 +1,86 @@
+package bankingsystem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeAccountsMany(t *testing.T) {
+	t.Run("Merges Balances, Payments And History In One Event", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(100, "a", 100)
+		store.CreateAccount(100, "b", 200)
+		store.CreateAccount(100, "c", 300)
+		store.CreateAccount(100, "d", 0)
+		store.Transfer(101, "a", "d", 50)
+		store.Transfer(102, "b", "c", 20)
+		paymentID, _ := store.SchedulePayment(103, "b", 30, 60)
+
+		// ACT
+		err := store.MergeAccountsMany(110, []string{"a", "b"}, "c")
+		clock.Advance(2 * time.Minute)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during merge")
+		account, _ := store.GetAccount("c")
+		assert.Equal(t, float64(520), account.Balance, "merged balance should be debited by the moved payment")
+		assert.Equal(t, float64(100), account.TotalTransferred, "merged total transferred mismatch")
+		_, aErr := store.GetAccount("a")
+		assert.ErrorIs(t, aErr, ErrAccountNotFound, "sources should be removed")
+		attempts, _ := store.GetPaymentAttempts(*paymentID)
+		assert.Equal(t, PaymentExecuted, attempts[0].Outcome, "moved payment should execute")
+
+		events, _ := store.Transactions(110, 110)
+		assert.Equal(t, []Event{{Seq: 7, Timestamp: 110, Type: EventAccountsMergedMany, CounterpartyID: "c", SourceIDs: []string{"a", "b"}, Amount: 230}}, events, "expected a single merge event")
+		view, _ := store.StateAt(110)
+		replayed, _ := view.Account("c")
+		assert.Equal(t, AccountSnapshot{AccountID: "c", UpdatedAt: 110, Balance: 550, TotalTransferred: 70}, replayed, "history should replay the merge")
+
+		ranking, _ := store.TopCounterparties("c", 5, 0)
+		assert.Equal(t, []CounterpartyStats{{CounterpartyID: "d", SentCount: 1, SentVolume: 50}}, ranking.ByCount, "counterparties should follow the merge")
+	})
+
+	t.Run("Rejects The Whole Merge If Any Source Is Invalid", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 100)
+		store.CreateAccount(1, "c", 300)
+
+		// ACT
+		missing := store.MergeAccountsMany(2, []string{"a", "missing"}, "c")
+		duplicate := store.MergeAccountsMany(2, []string{"a", "a"}, "c")
+		self := store.MergeAccountsMany(2, []string{"a", "c"}, "c")
+		empty := store.MergeAccountsMany(2, nil, "c")
+
+		// ASSERT
+		assert.ErrorIs(t, missing, ErrAccountNotFound, "expected missing account error")
+		assert.EqualError(t, duplicate, "account a is listed more than once", "unexpected error message")
+		assert.EqualError(t, self, "cannot merge account c into itself", "unexpected error message")
+		assert.EqualError(t, empty, "no accounts to merge", "unexpected error message")
+		assert.Equal(t, float64(100), store.accounts.get("a").balance, "source should be untouched")
+		assert.Equal(t, float64(300), store.accounts.get("c").balance, "destination should be untouched")
+		assert.Len(t, store.events, 2, "no event should be recorded")
+	})
+
+	t.Run("Writes One Storage Batch", func(t *testing.T) {
+		// ARRANGE
+		storage := NewMemoryStorage()
+		store := NewAccountStore(WithStorage(storage))
+		store.CreateAccount(1, "a", 100)
+		store.CreateAccount(1, "b", 200)
+		store.CreateAccount(1, "c", 300)
+
+		// ACT
+		err := store.MergeAccountsMany(2, []string{"a", "b"}, "c")
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during merge")
+		stored, _ := storage.Load(context.Background())
+		assert.Equal(t, []AccountSnapshot{{AccountID: "c", UpdatedAt: 2, Balance: 600}}, stored, "storage should hold only the merged account")
+	})
+}
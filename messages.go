@@ -0,0 +1,112 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MessageCatalog maps a locale, such as "en" or "es-MX", to a message template for each
+// error code. Templates refer to an Error's parameters as {name}; parameters an error does not
+// carry are left as written.
+type MessageCatalog map[string]map[string]string
+
+// DefaultLocale is the locale Localize falls back to.
+const DefaultLocale = "en"
+
+// DefaultMessages covers every error code in English and Spanish.
+var DefaultMessages = MessageCatalog{
+	"en": {
+		CodeAccountNotFound:         "The account does not exist.",
+		CodeInsufficientBalance:     "The account has {available} available, which does not cover {amount}.",
+		CodePaymentNotFound:         "The payment does not exist.",
+		CodePaymentNotCancellable:   "The payment was already executed or cancelled.",
+		CodeTransferLimitExceeded:   "The amount of {amount} exceeds the limit of {limit}.",
+		CodeAmountBelowMinimum:      "The amount of {amount} is below the minimum of {minimum}.",
+		CodeSchedulingLimitExceeded: "Too many payments are pending. Try again later.",
+		CodeQuotaExceeded:           "The tenant's quota is exhausted.",
+		CodeCurrencyMismatch:        "The accounts hold different currencies.",
+		CodeSelfTransfer:            "An account cannot transfer to itself.",
+		CodeRoundTrip:               "The transfer would send money straight back to where it came from.",
+		CodeRequestInProgress:       "A request with this idempotency key is still in progress.",
+		CodeOperationTimeout:        "The operation timed out.",
+		CodeOperationNotPermitted:   "The account's state does not permit this operation.",
+		CodeInvalidTransition:       "The account cannot move to that state.",
+		CodeCaseNotFound:            "The case does not exist.",
+		CodeForbidden:               "You are not allowed to do this.",
+		CodeQuoteNotFound:           "The quote does not exist or was already used.",
+		CodeQuoteExpired:            "The quote has expired. Request a new one.",
+		CodeReplayedRequest:         "This request was already received.",
+		CodeStaleRequest:            "The request is too old or its clock is wrong.",
+		CodeInvalidSignature:        "The request signature is not valid.",
+		CodeReservedAccountID:       "That account ID is reserved.",
+		CodeInvalidRequest:          "The request is invalid.",
+		CodeInternal:                "Something went wrong.",
+	},
+	"es": {
+		CodeAccountNotFound:         "La cuenta no existe.",
+		CodeInsufficientBalance:     "La cuenta tiene {available} disponible, que no cubre {amount}.",
+		CodePaymentNotFound:         "El pago no existe.",
+		CodePaymentNotCancellable:   "El pago ya se ejecutó o se canceló.",
+		CodeTransferLimitExceeded:   "El importe de {amount} supera el límite de {limit}.",
+		CodeAmountBelowMinimum:      "El importe de {amount} es inferior al mínimo de {minimum}.",
+		CodeSchedulingLimitExceeded: "Hay demasiados pagos pendientes. Inténtelo más tarde.",
+		CodeQuotaExceeded:           "La cuota del cliente está agotada.",
+		CodeCurrencyMismatch:        "Las cuentas están en monedas distintas.",
+		CodeSelfTransfer:            "Una cuenta no puede transferirse a sí misma.",
+		CodeRoundTrip:               "La transferencia devolvería el dinero directamente a su origen.",
+		CodeRequestInProgress:       "Una solicitud con esta clave de idempotencia sigue en curso.",
+		CodeOperationTimeout:        "La operación superó el tiempo de espera.",
+		CodeOperationNotPermitted:   "El estado de la cuenta no permite esta operación.",
+		CodeInvalidTransition:       "La cuenta no puede pasar a ese estado.",
+		CodeCaseNotFound:            "El caso no existe.",
+		CodeForbidden:               "No tiene permiso para hacer esto.",
+		CodeQuoteNotFound:           "La cotización no existe o ya se utilizó.",
+		CodeQuoteExpired:            "La cotización ha vencido. Solicite una nueva.",
+		CodeReplayedRequest:         "Esta solicitud ya se recibió.",
+		CodeStaleRequest:            "La solicitud es demasiado antigua o su reloj es incorrecto.",
+		CodeInvalidSignature:        "La firma de la solicitud no es válida.",
+		CodeReservedAccountID:       "Ese identificador de cuenta está reservado.",
+		CodeInvalidRequest:          "La solicitud no es válida.",
+		CodeInternal:                "Algo salió mal.",
+	},
+}
+
+// Localize returns the message for err in locale. A locale missing from the catalog falls back
+// to its language, as "es-MX" does to "es", then to DefaultLocale. Errors without a code, or
+// whose code the catalog lacks, keep their own message.
+func (c MessageCatalog) Localize(err error, locale string) string {
+	code, params := errorDetails(err)
+	for _, candidate := range []string{locale, strings.SplitN(locale, "-", 2)[0], DefaultLocale} {
+		template, found := c[candidate][code]
+		if !found {
+			continue
+		}
+		replacements := make([]string, 0, 2*len(params))
+		for name, value := range params {
+			replacements = append(replacements, "{"+name+"}", formatParam(value))
+		}
+		return strings.NewReplacer(replacements...).Replace(template)
+	}
+	return err.Error()
+}
+
+// errorDetails returns err's code, empty for errors without one, and parameters.
+func errorDetails(err error) (string, map[string]any) {
+	var detailed *Error
+	if errors.As(err, &detailed) {
+		return detailed.Code, detailed.Params
+	}
+	if code, _ := codeFor(err); code != CodeInternal {
+		return code, nil
+	}
+	return "", nil
+}
+
+func formatParam(value any) string {
+	if number, ok := value.(float64); ok {
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
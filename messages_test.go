@@ -0,0 +1,59 @@
+package bankingsystem
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalize(t *testing.T) {
+	store := NewAccountStore(WithLimits(Limits{MaxTransferAmount: 500}))
+	store.CreateAccount(1, "a", 100)
+	store.CreateAccount(1, "b", 0)
+	_, insufficient := store.Transfer(2, "a", "b", 250.5)
+	_, overLimit := store.Transfer(2, "a", "b", 600)
+
+	for _, test := range []struct {
+		name    string
+		err     error
+		locale  string
+		message string
+	}{
+		{"Fills In Parameters", insufficient, "en", "The account has 100 available, which does not cover 250.5."},
+		{"Translates", overLimit, "es", "El importe de 600 supera el límite de 500."},
+		{"Falls Back To The Language", insufficient, "es-MX", "La cuenta tiene 100 disponible, que no cubre 250.5."},
+		{"Falls Back To The Default Locale", ErrPaymentNotFound, "fr", "The payment does not exist."},
+		{"Keeps Unknown Errors", errors.New("disk on fire"), "en", "disk on fire"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			// ACT
+			message := DefaultMessages.Localize(test.err, test.locale)
+
+			// ASSERT
+			assert.Equal(t, test.message, message, "message mismatch")
+		})
+	}
+
+	t.Run("Carries Parameters On Store Errors", func(t *testing.T) {
+		// ACT
+		var detailed *Error
+		found := errors.As(insufficient, &detailed)
+
+		// ASSERT
+		assert.True(t, found, "expected a structured error")
+		assert.ErrorIs(t, insufficient, ErrInsufficientBalance, "expected the sentinel to match")
+		assert.Equal(t, CodeInsufficientBalance, detailed.Code, "code mismatch")
+		assert.Equal(t, map[string]any{ParamAccountID: "a", ParamAmount: 250.5, ParamAvailable: float64(100)}, detailed.Params, "params mismatch")
+		assert.Equal(t, "insufficient balance in the from account", insufficient.Error(), "message should not change")
+	})
+
+	t.Run("Covers Every Code", func(t *testing.T) {
+		// ASSERT
+		for locale, messages := range DefaultMessages {
+			for _, entry := range errorCodes {
+				assert.Contains(t, messages, entry.code, "locale %s lacks a message", locale)
+			}
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package bankingsystem
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TenantMonthUsage is what a tenant used in one calendar month, UTC, for billing.
+type TenantMonthUsage struct {
+	TenantID string
+	// Month is formatted as "2006-01".
+	Month string
+	// APICalls counts HTTP API requests on the tenant's accounts that passed authorization.
+	APICalls int
+	// PeakAccounts is the most accounts the tenant held at once during the month.
+	PeakAccounts int
+	// Transfers and TransferVolume count the transfers sent from the tenant's accounts, by the
+	// month of the transfer timestamp.
+	Transfers      int
+	TransferVolume float64
+}
+
+// monthOf returns the UTC month containing timestamp, formatted as in TenantMonthUsage.
+func monthOf(timestamp int) string {
+	return time.Unix(int64(timestamp), 0).UTC().Format("2006-01")
+}
+
+// meteredUsage returns the tenant's usage for month, starting it if needed. A new month starts
+// from the accounts the tenant holds. Callers must hold the write lock.
+func (s *AccountStore) meteredUsage(tenantID, month string) *TenantMonthUsage {
+	months := s.usage[tenantID]
+	if months == nil {
+		months = make(map[string]*TenantMonthUsage)
+		s.usage[tenantID] = months
+	}
+	usage, exists := months[month]
+	if !exists {
+		usage = &TenantMonthUsage{TenantID: tenantID, Month: month, PeakAccounts: s.tenantAccounts[tenantID]}
+		months[month] = usage
+	}
+	return usage
+}
+
+// meterAccounts records the tenant's account count towards this month's peak. Callers must
+// hold the write lock.
+func (s *AccountStore) meterAccounts(tenantID string) {
+	usage := s.meteredUsage(tenantID, monthOf(int(s.clock.Now().Unix())))
+	usage.PeakAccounts = max(usage.PeakAccounts, s.tenantAccounts[tenantID])
+}
+
+// meterTransfer records a committed transfer of amount. Callers must hold the write lock.
+func (s *AccountStore) meterTransfer(tenantID string, timestamp int, amount float64) {
+	usage := s.meteredUsage(tenantID, monthOf(timestamp))
+	usage.Transfers++
+	usage.TransferVolume += amount
+}
+
+// meterAPICall records an API request on the account against its tenant, if it has one.
+func (s *AccountStore) meterAPICall(accountID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists || account.tenantID == "" {
+		return
+	}
+	s.meteredUsage(account.tenantID, monthOf(int(s.clock.Now().Unix()))).APICalls++
+}
+
+// MonthlyUsage returns every tenant's usage in month, formatted as "2006-01", ordered by tenant.
+// The current month is still accruing.
+func (s *AccountStore) MonthlyUsage(month string) []TenantMonthUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var usages []TenantMonthUsage
+	for tenantID, months := range s.usage {
+		if usage, exists := months[month]; exists {
+			usages = append(usages, *usage)
+		} else if month == monthOf(int(s.clock.Now().Unix())) && s.tenantAccounts[tenantID] > 0 {
+			usages = append(usages, TenantMonthUsage{TenantID: tenantID, Month: month, PeakAccounts: s.tenantAccounts[tenantID]})
+		}
+	}
+	sort.Slice(usages, func(i, j int) bool {
+		return usages[i].TenantID < usages[j].TenantID
+	})
+	return usages
+}
+
+// usageMetrics are the metrics WriteUsageMetrics exports, in order.
+var usageMetrics = []struct {
+	name  string
+	kind  string
+	help  string
+	value func(TenantMonthUsage) float64
+}{
+	{"bankingsystem_tenant_api_calls_total", "counter", "HTTP API requests on the tenant's accounts.", func(u TenantMonthUsage) float64 { return float64(u.APICalls) }},
+	{"bankingsystem_tenant_peak_accounts", "gauge", "Most accounts the tenant held at once in the month.", func(u TenantMonthUsage) float64 { return float64(u.PeakAccounts) }},
+	{"bankingsystem_tenant_transfers_total", "counter", "Transfers sent from the tenant's accounts.", func(u TenantMonthUsage) float64 { return float64(u.Transfers) }},
+	{"bankingsystem_tenant_transfer_volume_total", "counter", "Amount transferred from the tenant's accounts.", func(u TenantMonthUsage) float64 { return u.TransferVolume }},
+}
+
+// WriteUsageMetrics writes every tenant's monthly usage in the Prometheus text exposition
+// format, one series per tenant and month, labelled tenant and month.
+func (s *AccountStore) WriteUsageMetrics(w io.Writer) error {
+	s.mu.RLock()
+	var usages []TenantMonthUsage
+	for _, months := range s.usage {
+		for _, usage := range months {
+			usages = append(usages, *usage)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(usages, func(i, j int) bool {
+		if usages[i].TenantID != usages[j].TenantID {
+			return usages[i].TenantID < usages[j].TenantID
+		}
+		return usages[i].Month < usages[j].Month
+	})
+
+	var out strings.Builder
+	for _, metric := range usageMetrics {
+		fmt.Fprintf(&out, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		for _, usage := range usages {
+			fmt.Fprintf(&out, "%s{tenant=\"%s\",month=\"%s\"} %v\n", metric.name, escapeLabel(usage.TenantID), usage.Month, metric.value(usage))
+		}
+	}
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// NewUsageMetricsHandler serves WriteUsageMetrics for Prometheus to scrape.
+func NewUsageMetricsHandler(store *AccountStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		store.WriteUsageMetrics(w)
+	})
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value for the text exposition format.
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,62 @@
+package bankingsystem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetering(t *testing.T) {
+	t.Run("Rolls Usage Up By Month", func(t *testing.T) {
+		// ARRANGE
+		january := time.Date(2026, 1, 20, 0, 0, 0, 0, time.UTC)
+		february := int(time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC).Unix())
+		clock := newManualClock(january)
+		store := NewAccountStore(WithClock(clock))
+		now := int(january.Unix())
+		store.CreateTenantAccount(now, "acme", "a", 500)
+		store.CreateTenantAccount(now, "acme", "b", 0)
+		store.CreateTenantAccount(now, "globex", "c", 0)
+		handler := NewHTTPHandler(store)
+
+		// ACT
+		store.Transfer(now, "a", "b", 100)
+		store.Transfer(now, "a", "c", 50)
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/accounts/a", nil))
+		store.Transfer(february, "a", "b", 25)
+
+		// ASSERT
+		assert.Equal(t, []TenantMonthUsage{
+			{TenantID: "acme", Month: "2026-01", APICalls: 1, PeakAccounts: 2, Transfers: 2, TransferVolume: 150},
+			{TenantID: "globex", Month: "2026-01", PeakAccounts: 1},
+		}, store.MonthlyUsage("2026-01"), "january usage mismatch")
+		assert.Equal(t, []TenantMonthUsage{
+			{TenantID: "acme", Month: "2026-02", PeakAccounts: 2, Transfers: 1, TransferVolume: 25},
+		}, store.MonthlyUsage("2026-02"), "february usage mismatch")
+	})
+
+	t.Run("Exports Prometheus Metrics", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+		store := NewAccountStore(WithClock(clock))
+		now := int(clock.Now().Unix())
+		store.CreateTenantAccount(now, `quo"te`, "a", 100)
+		store.CreateAccount(now, "b", 0)
+		store.Transfer(now, "a", "b", 12.5)
+		recorder := httptest.NewRecorder()
+
+		// ACT
+		NewUsageMetricsHandler(store).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		// ASSERT
+		body := recorder.Body.String()
+		assert.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), "text/plain"), "content type mismatch")
+		assert.Contains(t, body, "# TYPE bankingsystem_tenant_transfer_volume_total counter\n", "expected metric type")
+		assert.Contains(t, body, `bankingsystem_tenant_transfer_volume_total{tenant="quo\"te",month="2026-03"} 12.5`+"\n", "expected escaped series")
+		assert.Contains(t, body, `bankingsystem_tenant_peak_accounts{tenant="quo\"te",month="2026-03"} 1`+"\n", "expected account gauge")
+	})
+}
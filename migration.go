@@ -0,0 +1,171 @@
+package bankingsystem
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// MigrationReport describes a completed storage migration.
+type MigrationReport struct {
+	// Copied is the number of accounts in the initial bulk copy.
+	Copied int
+	// Tailed is the number of batches written to the source during the copy and replayed
+	// onto the target.
+	Tailed int
+	// Verified is the number of accounts whose checksums matched at cutover.
+	Verified int
+}
+
+// maxTailRounds bounds how often MigrateStorage replays captured batches without holding the
+// write lock before it stops the world for cutover.
+const maxTailRounds = 8
+
+// changeCapture records every batch written to the store's Storage while a migration runs.
+type changeCapture struct {
+	mu      sync.Mutex
+	batches []StorageBatch
+}
+
+func (c *changeCapture) add(batch StorageBatch) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.batches = append(c.batches, batch)
+}
+
+func (c *changeCapture) drain() []StorageBatch {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	batches := c.batches
+	c.batches = nil
+	return batches
+}
+
+// MigrateStorage moves the store from its current Storage to target while it keeps serving
+// writes. It starts capturing every batch written to the source, bulk-copies the source onto
+// target, and replays captured batches until few are left. It then blocks writers, replays the
+// rest, compares a checksum of every account in source and target, and switches the store to
+// target. On any error the store keeps using its source, and target may hold a partial copy.
+func (s *AccountStore) MigrateStorage(ctx context.Context, target Storage) (*MigrationReport, error) {
+	capture := &changeCapture{}
+
+	s.mu.Lock()
+	source := s.storage
+	switch {
+	case source == nil:
+		s.mu.Unlock()
+		return nil, errors.New("store has no storage to migrate from")
+	case s.migration != nil:
+		s.mu.Unlock()
+		return nil, errors.New("a storage migration is already running")
+	}
+	s.migration = capture
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.migration = nil
+		s.mu.Unlock()
+	}()
+
+	report := &MigrationReport{}
+	snapshots, err := source.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := target.Apply(ctx, StorageBatch{Put: snapshots}); err != nil {
+		return nil, err
+	}
+	report.Copied = len(snapshots)
+
+	for round := 0; round < maxTailRounds; round++ {
+		batches := capture.drain()
+		if len(batches) == 0 {
+			break
+		}
+		if err := replay(ctx, target, batches); err != nil {
+			return nil, err
+		}
+		report.Tailed += len(batches)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batches := capture.drain()
+	if err := replay(ctx, target, batches); err != nil {
+		return nil, err
+	}
+	report.Tailed += len(batches)
+
+	verified, err := compareStorage(ctx, source, target)
+	if err != nil {
+		return nil, err
+	}
+	report.Verified = verified
+
+	s.storage = target
+	return report, nil
+}
+
+func replay(ctx context.Context, target Storage, batches []StorageBatch) error {
+	for _, batch := range batches {
+		if err := target.Apply(ctx, batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compareStorage checks that source and target hold the same accounts with the same
+// checksums and returns how many accounts it compared.
+func compareStorage(ctx context.Context, source, target Storage) (int, error) {
+	want, err := storageChecksums(ctx, source)
+	if err != nil {
+		return 0, err
+	}
+	got, err := storageChecksums(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+
+	var mismatched []string
+	for accountID, checksum := range want {
+		if got[accountID] != checksum {
+			mismatched = append(mismatched, accountID)
+		}
+	}
+	for accountID := range got {
+		if _, exists := want[accountID]; !exists {
+			mismatched = append(mismatched, accountID)
+		}
+	}
+	if len(mismatched) > 0 {
+		sort.Strings(mismatched)
+		return 0, fmt.Errorf("checksum mismatch for %d accounts, first %s", len(mismatched), mismatched[0])
+	}
+	return len(want), nil
+}
+
+func storageChecksums(ctx context.Context, storage Storage) (map[string][sha256.Size]byte, error) {
+	snapshots, err := storage.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	checksums := make(map[string][sha256.Size]byte, len(snapshots))
+	for _, snapshot := range snapshots {
+		checksums[snapshot.AccountID] = accountChecksum(snapshot)
+	}
+	return checksums, nil
+}
+
+func accountChecksum(snapshot AccountSnapshot) [sha256.Size]byte {
+	return sha256.Sum256([]byte(snapshot.AccountID + "|" + snapshot.TenantID + "|" + snapshot.Currency + "|" + string(snapshot.State) + "|" +
+		strconv.Itoa(snapshot.UpdatedAt) + "|" +
+		strconv.FormatFloat(snapshot.Balance, 'g', -1, 64) + "|" +
+		strconv.FormatFloat(snapshot.TotalTransferred, 'g', -1, 64)))
+}
@@ -0,0 +1,91 @@
+package bankingsystem
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateStorage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Copies While Writes Continue", func(t *testing.T) {
+		// ARRANGE
+		source := NewMemoryStorage()
+		store := NewAccountStore(WithStorage(source))
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 500)
+		store.CreateAccount(1, "c", 10)
+
+		target := &blockingStorage{Storage: NewMemoryStorage(), started: make(chan struct{}), release: make(chan struct{})}
+		done := make(chan *MigrationReport)
+		go func() {
+			report, err := store.MigrateStorage(ctx, target)
+			assert.NoError(t, err, "unexpected error during migration")
+			done <- report
+		}()
+		<-target.started
+
+		// ACT
+		store.Transfer(2, "a", "b", 100)
+		store.MergeAccounts(3, "c", "a")
+		close(target.release)
+		report := <-done
+		store.Transfer(4, "b", "a", 50)
+
+		// ASSERT
+		assert.Equal(t, &MigrationReport{Copied: 3, Tailed: 2, Verified: 2}, report, "report mismatch")
+		migrated, _ := target.Load(ctx)
+		assert.Equal(t, []AccountSnapshot{
+			{AccountID: "a", UpdatedAt: 4, Balance: 960, TotalTransferred: 100},
+			{AccountID: "b", UpdatedAt: 4, Balance: 550, TotalTransferred: 50},
+		}, migrated, "target should hold the live state")
+		stale, _ := source.Load(ctx)
+		assert.Equal(t, float64(910), stale[0].Balance, "source should stop receiving writes after cutover")
+	})
+
+	t.Run("Checksum Mismatch Keeps The Source", func(t *testing.T) {
+		// ARRANGE
+		source := NewMemoryStorage()
+		store := NewAccountStore(WithStorage(source))
+		store.CreateAccount(1, "a", 1000)
+		target := NewMemoryStorage()
+		target.Apply(ctx, StorageBatch{Put: []AccountSnapshot{{AccountID: "stray"}}})
+
+		// ACT
+		report, err := store.MigrateStorage(ctx, target)
+
+		// ASSERT
+		assert.Nil(t, report, "expected no report")
+		assert.EqualError(t, err, "checksum mismatch for 1 accounts, first stray", "unexpected error message")
+		store.Transfer(2, "a", "a", 0)
+		stored, _ := source.Load(ctx)
+		assert.Equal(t, 2, stored[0].UpdatedAt, "store should keep writing to the source")
+	})
+
+	t.Run("No Source Storage", func(t *testing.T) {
+		// ACT
+		_, err := NewAccountStore().MigrateStorage(ctx, NewMemoryStorage())
+
+		// ASSERT
+		assert.EqualError(t, err, "store has no storage to migrate from", "unexpected error message")
+	})
+}
+
+// blockingStorage blocks its first Apply until release is closed.
+type blockingStorage struct {
+	Storage
+	started chan struct{}
+	release chan struct{}
+	blocked bool
+}
+
+func (b *blockingStorage) Apply(ctx context.Context, batch StorageBatch) error {
+	if !b.blocked {
+		b.blocked = true
+		close(b.started)
+		<-b.release
+	}
+	return b.Storage.Apply(ctx, batch)
+}
@@ -0,0 +1,207 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrCaseNotFound is returned for a case ID the store never opened.
+var ErrCaseNotFound = errors.New("case not found")
+
+// Note is a remark left by an operator on an account or case.
+type Note struct {
+	Timestamp int
+	Author    string
+	Text      string
+}
+
+// CaseKind is what a case investigates.
+type CaseKind string
+
+const (
+	CaseFraud   CaseKind = "fraud"
+	CaseDispute CaseKind = "dispute"
+)
+
+// CaseStatus is where a case stands.
+type CaseStatus string
+
+const (
+	CaseOpen   CaseStatus = "open"
+	CaseClosed CaseStatus = "closed"
+)
+
+// Case is a fraud investigation or dispute about an account, optionally about one of its
+// transfers. Notes hold its history, oldest first, including who opened and closed it.
+type Case struct {
+	ID        string
+	Kind      CaseKind
+	AccountID string
+	// TransferSeq is the sequence number of the disputed transfer, or zero.
+	TransferSeq int
+	Status      CaseStatus
+	OpenedAt    int
+	ClosedAt    int
+	Notes       []Note
+}
+
+func (c *Case) copy() Case {
+	copied := *c
+	copied.Notes = append([]Note(nil), c.Notes...)
+	return copied
+}
+
+func validateNote(author, text string) error {
+	switch {
+	case author == "":
+		return errors.New("note author is required")
+	case text == "":
+		return errors.New("note text is required")
+	}
+	return nil
+}
+
+// AddAccountNote appends a note to the account. Notes are kept in memory, apart from the
+// event history, and outlive the account, so they can still be read after it is merged away.
+func (s *AccountStore) AddAccountNote(timestamp int, accountID, author, text string) error {
+	if err := validateNote(author, text); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceAddAccountNote, Timestamp: timestamp, AccountID: accountID, Actor: author, Text: text})
+
+	if _, exists := s.accounts.lookup(accountID); !exists {
+		return ErrAccountNotFound
+	}
+	s.accountNotes[accountID] = append(s.accountNotes[accountID], Note{Timestamp: timestamp, Author: author, Text: text})
+	return nil
+}
+
+// AccountNotes returns the account's notes, oldest first.
+func (s *AccountStore) AccountNotes(accountID string) []Note {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Note(nil), s.accountNotes[accountID]...)
+}
+
+// OpenCase opens a case about the account and returns its ID. transferSeq names the transfer
+// in question by sequence number, or is zero for a case about the account as a whole.
+func (s *AccountStore) OpenCase(timestamp int, kind CaseKind, accountID string, transferSeq int, author, text string) (string, error) {
+	if kind != CaseFraud && kind != CaseDispute {
+		return "", fmt.Errorf("unknown case kind %q", kind)
+	}
+	if err := validateNote(author, text); err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceOpenCase, Timestamp: timestamp, Kind: kind, AccountID: accountID, Value: transferSeq, Actor: author, Text: text})
+
+	if _, exists := s.accounts.lookup(accountID); !exists {
+		return "", ErrAccountNotFound
+	}
+	if transferSeq != 0 {
+		events, err := s.history(historyStart, timestamp)
+		if err != nil {
+			return "", err
+		}
+		found := false
+		for _, event := range events {
+			if event.Seq == transferSeq && event.Type == EventTransfer && event.involves(accountID) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", fmt.Errorf("no transfer %d involving account %s", transferSeq, accountID)
+		}
+	}
+
+	seq, err := s.nextSequence(SequenceCase)
+	if err != nil {
+		return "", err
+	}
+	c := &Case{
+		ID:          fmt.Sprintf("case-%d", seq),
+		Kind:        kind,
+		AccountID:   accountID,
+		TransferSeq: transferSeq,
+		Status:      CaseOpen,
+		OpenedAt:    timestamp,
+		Notes:       []Note{{Timestamp: timestamp, Author: author, Text: text}},
+	}
+	s.cases[c.ID] = c
+	s.caseOrder = append(s.caseOrder, c)
+	return c.ID, nil
+}
+
+// AddCaseNote appends a note to the case, open or closed.
+func (s *AccountStore) AddCaseNote(timestamp int, caseID, author, text string) error {
+	if err := validateNote(author, text); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceAddCaseNote, Timestamp: timestamp, ID: caseID, Actor: author, Text: text})
+
+	c, exists := s.cases[caseID]
+	if !exists {
+		return ErrCaseNotFound
+	}
+	c.Notes = append(c.Notes, Note{Timestamp: timestamp, Author: author, Text: text})
+	return nil
+}
+
+// CloseCase closes the case with a final note.
+func (s *AccountStore) CloseCase(timestamp int, caseID, author, text string) error {
+	if err := validateNote(author, text); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceCloseCase, Timestamp: timestamp, ID: caseID, Actor: author, Text: text})
+
+	c, exists := s.cases[caseID]
+	if !exists {
+		return ErrCaseNotFound
+	}
+	if c.Status == CaseClosed {
+		return errors.New("case is already closed")
+	}
+	c.Status = CaseClosed
+	c.ClosedAt = timestamp
+	c.Notes = append(c.Notes, Note{Timestamp: timestamp, Author: author, Text: text})
+	return nil
+}
+
+// GetCase returns the case with its notes.
+func (s *AccountStore) GetCase(caseID string) (Case, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, exists := s.cases[caseID]
+	if !exists {
+		return Case{}, ErrCaseNotFound
+	}
+	return c.copy(), nil
+}
+
+// AccountCases returns every case about the account in the order they were opened.
+func (s *AccountStore) AccountCases(accountID string) []Case {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var cases []Case
+	for _, c := range s.caseOrder {
+		if c.AccountID == accountID {
+			cases = append(cases, c.copy())
+		}
+	}
+	return cases
+}
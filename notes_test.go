@@ -0,0 +1,133 @@
+package bankingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountNotes(t *testing.T) {
+	t.Run("Lists Notes Oldest First", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+
+		// ACT
+		firstErr := store.AddAccountNote(20, "a", "agent-1", "customer called about a missing payment")
+		secondErr := store.AddAccountNote(30, "a", "agent-2", "payment found, customer informed")
+
+		// ASSERT
+		assert.NoError(t, firstErr, "unexpected error adding a note")
+		assert.NoError(t, secondErr, "unexpected error adding a note")
+		assert.Equal(t, []Note{
+			{Timestamp: 20, Author: "agent-1", Text: "customer called about a missing payment"},
+			{Timestamp: 30, Author: "agent-2", Text: "payment found, customer informed"},
+		}, store.AccountNotes("a"), "notes mismatch")
+	})
+
+	t.Run("Keeps Notes After A Merge", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 100)
+		store.AddAccountNote(20, "a", "agent-1", "duplicate of b")
+
+		// ACT
+		store.MergeAccounts(30, "a", "b")
+
+		// ASSERT
+		assert.Len(t, store.AccountNotes("a"), 1, "notes should outlive the account")
+		assert.ErrorIs(t, store.AddAccountNote(40, "a", "agent-1", "too late"), ErrAccountNotFound, "merged accounts should not take new notes")
+	})
+
+	t.Run("Requires An Author And Text", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+
+		// ACT
+		noAuthor := store.AddAccountNote(20, "a", "", "text")
+		noText := store.AddAccountNote(20, "a", "agent-1", "")
+
+		// ASSERT
+		assert.Error(t, noAuthor, "notes without an author should be rejected")
+		assert.Error(t, noText, "notes without text should be rejected")
+	})
+}
+
+func TestCases(t *testing.T) {
+	t.Run("Records The Case History", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 0)
+		store.Transfer(20, "a", "b", 50)
+
+		// ACT
+		caseID, openErr := store.OpenCase(30, CaseDispute, "b", 3, "agent-1", "customer does not recognise the transfer")
+		noteErr := store.AddCaseNote(40, caseID, "agent-2", "asked sender for proof")
+		closeErr := store.CloseCase(50, caseID, "agent-2", "sender confirmed the transfer")
+
+		// ASSERT
+		assert.NoError(t, openErr, "unexpected error opening the case")
+		assert.NoError(t, noteErr, "unexpected error adding a case note")
+		assert.NoError(t, closeErr, "unexpected error closing the case")
+		c, err := store.GetCase(caseID)
+		assert.NoError(t, err, "unexpected error reading the case")
+		assert.Equal(t, Case{
+			ID:          "case-1",
+			Kind:        CaseDispute,
+			AccountID:   "b",
+			TransferSeq: 3,
+			Status:      CaseClosed,
+			OpenedAt:    30,
+			ClosedAt:    50,
+			Notes: []Note{
+				{Timestamp: 30, Author: "agent-1", Text: "customer does not recognise the transfer"},
+				{Timestamp: 40, Author: "agent-2", Text: "asked sender for proof"},
+				{Timestamp: 50, Author: "agent-2", Text: "sender confirmed the transfer"},
+			},
+		}, c, "case mismatch")
+		assert.Error(t, store.CloseCase(60, caseID, "agent-2", "again"), "closed cases should not close twice")
+	})
+
+	t.Run("Rejects Transfers Of Other Accounts", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 0)
+		store.CreateAccount(10, "c", 0)
+		store.Transfer(20, "a", "b", 50)
+
+		// ACT
+		_, err := store.OpenCase(30, CaseFraud, "c", 4, "agent-1", "suspicious")
+
+		// ASSERT
+		assert.Error(t, err, "cases should only name the account's own transfers")
+	})
+
+	t.Run("Lists Cases In Opening Order", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 100)
+		store.CreateAccount(10, "b", 100)
+		var opened []string
+		for i := 0; i < 11; i++ {
+			caseID, _ := store.OpenCase(20, CaseFraud, "a", 0, "agent-1", "review")
+			opened = append(opened, caseID)
+		}
+		store.OpenCase(20, CaseFraud, "b", 0, "agent-1", "review")
+
+		// ACT
+		cases := store.AccountCases("a")
+
+		// ASSERT
+		var listed []string
+		for _, c := range cases {
+			listed = append(listed, c.ID)
+		}
+		assert.Equal(t, opened, listed, "cases should be listed in opening order")
+		_, err := store.GetCase("case-99")
+		assert.ErrorIs(t, err, ErrCaseNotFound, "unknown cases should not be found")
+	})
+}
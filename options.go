@@ -0,0 +1,90 @@
+package bankingsystem
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Option configures an AccountStore at construction time.
+type Option func(*AccountStore)
+
+// IDGenerator returns the ID for the seq-th payment scheduled by the store.
+type IDGenerator func(accountID string, seq int) string
+
+func defaultPaymentID(accountID string, seq int) string {
+	return fmt.Sprintf("payment-%s-%d", accountID, seq)
+}
+
+// Limits caps the amounts and pending work the store accepts. A zero field means no limit.
+type Limits struct {
+	MaxTransferAmount            float64
+	MaxPendingPayments           int
+	MaxPendingPaymentsPerAccount int
+}
+
+// WithClock sets the clock used to time scheduled payments.
+func WithClock(clock Clock) Option {
+	return func(s *AccountStore) {
+		s.clock = clock
+	}
+}
+
+// WithLogger sets the logger used to report background failures, such as skipped payments.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *AccountStore) {
+		s.logger = logger
+	}
+}
+
+// WithStorage persists every committed account change to storage.
+func WithStorage(storage Storage) Option {
+	return func(s *AccountStore) {
+		s.storage = storage
+	}
+}
+
+// WithOperationTimeout bounds how long each write to Storage may take. A write that runs past
+// the timeout fails the operation with ErrOperationTimeout, and since Storage applies a batch
+// whole or not at all and the store only changes memory after a successful write, nothing
+// of the operation is applied. Storage implementations must stop when their context is done
+// for the timeout to take effect.
+func WithOperationTimeout(timeout time.Duration) Option {
+	return func(s *AccountStore) {
+		s.operationTimeout = timeout
+	}
+}
+
+// WithIDGenerator sets how scheduled payment IDs are generated.
+func WithIDGenerator(generator IDGenerator) Option {
+	return func(s *AccountStore) {
+		s.newPaymentID = generator
+	}
+}
+
+// WithLimits sets the limits enforced on transfers and scheduled payments.
+func WithLimits(limits Limits) Option {
+	return func(s *AccountStore) {
+		s.limits = limits
+	}
+}
+
+// WithArchive enables archival, as EnableArchival does. A nil archive or negative
+// retentionDays, which EnableArchival rejects, leaves archival disabled.
+func WithArchive(archive Archive, retentionDays int) Option {
+	return func(s *AccountStore) {
+		if checkArchival(archive, retentionDays) == nil {
+			s.archive = archive
+			s.retentionDays = retentionDays
+		}
+	}
+}
+
+// checkLimits applies the store-wide limits and the account's AmountPolicy. Callers must hold
+// the lock.
+func (s *AccountStore) checkLimits(account *Account, amount float64) error {
+	if s.limits.MaxTransferAmount > 0 && amount > s.limits.MaxTransferAmount {
+		return newError(ErrTransferLimitExceeded, "", map[string]any{ParamAmount: amount, ParamLimit: s.limits.MaxTransferAmount})
+	}
+	return s.checkAmountPolicy(account, amount)
+}
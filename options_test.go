@@ -0,0 +1,235 @@
+package bankingsystem
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithClock(t *testing.T) {
+	// ARRANGE
+	clock := newManualClock(time.Unix(100, 0))
+	store := NewAccountStore(WithClock(clock))
+	accountID := randomAccountID()
+	store.CreateAccount(100, accountID, 1000)
+
+	_, err := store.SchedulePayment(100, accountID, 200, 60)
+	assert.NoError(t, err, "unexpected error during schedule payment")
+
+	// ACT
+	clock.Advance(59 * time.Second)
+	balanceBefore := store.accounts.get(accountID).balance
+	clock.Advance(time.Second)
+
+	// ASSERT
+	assert.Equal(t, float64(1000), balanceBefore, "payment should not execute before it is due")
+	assert.Equal(t, float64(800), store.accounts.get(accountID).balance, "payment should execute when due")
+}
+
+func TestWithIDGenerator(t *testing.T) {
+	// ARRANGE
+	store := NewAccountStore(WithIDGenerator(func(accountID string, seq int) string {
+		return "custom-" + accountID
+	}))
+	store.CreateAccount(1, "acc", 1000)
+
+	// ACT
+	paymentID, err := store.SchedulePayment(int(time.Now().Unix()), "acc", 100, 60)
+
+	// ASSERT
+	assert.NoError(t, err, "unexpected error during schedule payment")
+	assert.Equal(t, "custom-acc", *paymentID, "payment ID mismatch")
+	store.CancelScheduledPayment(*paymentID)
+}
+
+func TestWithLimits(t *testing.T) {
+	// ARRANGE
+	store := NewAccountStore(WithLimits(Limits{MaxTransferAmount: 500}))
+	fromID := randomAccountID()
+	toID := randomAccountID()
+	store.CreateAccount(1, fromID, 1000)
+	store.CreateAccount(1, toID, 1000)
+
+	// ACT
+	success, err := store.Transfer(2, fromID, toID, 600)
+	_, scheduleErr := store.SchedulePayment(2, fromID, 600, 60)
+
+	// ASSERT
+	assert.False(t, success, "expected transfer to fail")
+	assert.EqualError(t, err, "amount exceeds the transfer limit", "unexpected error message")
+	assert.EqualError(t, scheduleErr, "amount exceeds the transfer limit", "unexpected error message")
+	assert.Equal(t, float64(1000), store.accounts.get(fromID).balance, "fromAccount balance mismatch")
+}
+
+func TestWithStorage(t *testing.T) {
+	t.Run("Writes Through And Restores", func(t *testing.T) {
+		// ARRANGE
+		storage := NewMemoryStorage()
+		store := NewAccountStore(WithStorage(storage))
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 500)
+		store.CreateAccount(1, "c", 0)
+		store.Transfer(2, "a", "b", 100)
+		store.MergeAccounts(3, "c", "b")
+
+		// ACT
+		restored, err := OpenAccountStore(context.Background(), WithStorage(storage))
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error restoring store")
+		assert.Equal(t, 2, restored.accounts.len(), "expected merged account to be deleted from storage")
+		assert.Equal(t, store.accounts.get("a").snapshot(), restored.accounts.get("a").snapshot(), "account a mismatch")
+		assert.Equal(t, store.accounts.get("b").snapshot(), restored.accounts.get("b").snapshot(), "account b mismatch")
+	})
+
+	t.Run("Failed Write Leaves State Untouched", func(t *testing.T) {
+		// ARRANGE
+		storage := &failingStorage{Storage: NewMemoryStorage()}
+		store := NewAccountStore(WithStorage(storage))
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 500)
+		storage.fail = true
+
+		// ACT
+		success, err := store.Transfer(2, "a", "b", 100)
+		account := store.CreateAccount(2, "c", 10)
+
+		// ASSERT
+		assert.False(t, success, "expected transfer to fail")
+		assert.Error(t, err, "expected storage error")
+		assert.Nil(t, account, "expected account creation to fail")
+		assert.Equal(t, float64(1000), store.accounts.get("a").balance, "fromAccount balance mismatch")
+		assert.Equal(t, float64(500), store.accounts.get("b").balance, "toAccount balance mismatch")
+		assert.Len(t, store.events, 2, "no event should be recorded for failed writes")
+	})
+}
+
+func TestWithOperationTimeout(t *testing.T) {
+	t.Run("Slow Write Times Out Without Applying", func(t *testing.T) {
+		// ARRANGE
+		storage := &slowStorage{Storage: NewMemoryStorage()}
+		store := NewAccountStore(WithStorage(storage), WithOperationTimeout(10*time.Millisecond))
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 500)
+		storage.slow = true
+
+		// ACT
+		success, err := store.Transfer(2, "a", "b", 100)
+
+		// ASSERT
+		assert.False(t, success, "expected transfer to fail")
+		assert.ErrorIs(t, err, ErrOperationTimeout, "expected timeout error")
+		assert.Equal(t, float64(1000), store.accounts.get("a").balance, "fromAccount balance mismatch")
+		assert.Equal(t, float64(500), store.accounts.get("b").balance, "toAccount balance mismatch")
+		stored, _ := storage.Load(context.Background())
+		assert.Equal(t, float64(1000), stored[0].Balance, "storage should not hold the transfer")
+	})
+
+	t.Run("Fast Write Succeeds", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithStorage(NewMemoryStorage()), WithOperationTimeout(time.Second))
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 500)
+
+		// ACT
+		success, err := store.Transfer(2, "a", "b", 100)
+
+		// ASSERT
+		assert.True(t, success, "expected transfer to succeed")
+		assert.NoError(t, err, "unexpected error")
+	})
+}
+
+type failingStorage struct {
+	Storage
+	fail bool
+}
+
+func (f *failingStorage) Apply(ctx context.Context, batch StorageBatch) error {
+	if f.fail {
+		return errors.New("storage unavailable")
+	}
+	return f.Storage.Apply(ctx, batch)
+}
+
+// manualClock is a Clock that only moves when advanced, running due timers synchronously.
+type manualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*manualTimer
+}
+
+type manualTimer struct {
+	clock   *manualClock
+	at      time.Time
+	f       func()
+	stopped bool
+	fired   bool
+}
+
+func newManualClock(now time.Time) *manualClock {
+	return &manualClock{now: now}
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &manualTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+func (c *manualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	var due []*manualTimer
+	for _, timer := range c.timers {
+		if !timer.stopped && !timer.fired && !timer.at.After(c.now) {
+			timer.fired = true
+			due = append(due, timer)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].at.Before(due[j].at)
+	})
+	for _, timer := range due {
+		timer.f()
+	}
+}
+
+func (t *manualTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	if t.stopped || t.fired {
+		return false
+	}
+	t.stopped = true
+	return true
+}
+
+// slowStorage waits for its context to be done before every write while slow is set.
+type slowStorage struct {
+	Storage
+	slow bool
+}
+
+func (s *slowStorage) Apply(ctx context.Context, batch StorageBatch) error {
+	if s.slow {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	return s.Storage.Apply(ctx, batch)
+}
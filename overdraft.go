@@ -0,0 +1,183 @@
+package bankingsystem
+
+import (
+	"errors"
+	"time"
+)
+
+// OverdraftPolicy lets an account's balance go negative by up to Limit. An overdraft cured,
+// that is brought back to zero or above, within GracePeriod costs nothing. After that the
+// store charges Fees[0], then Fees[1] after each further FeeInterval, repeating the last fee
+// until the overdraft is cured. A zero FeeInterval charges only the first fee.
+type OverdraftPolicy struct {
+	Limit       float64
+	GracePeriod time.Duration
+	FeeInterval time.Duration
+	Fees        []float64
+}
+
+// OverdraftFee is one penalty fee charged during an overdraft.
+type OverdraftFee struct {
+	Timestamp int
+	Amount    float64
+}
+
+// OverdraftEpisode is one stretch of time an account spent below zero. CuredAt is zero while
+// the account is still overdrawn.
+type OverdraftEpisode struct {
+	StartedAt int
+	CuredAt   int
+	// Deepest is the lowest balance reached, fees included.
+	Deepest float64
+	Fees    []OverdraftFee
+}
+
+// overdraftFacility is an account's overdraft policy and history.
+type overdraftFacility struct {
+	policy   OverdraftPolicy
+	episodes []*OverdraftEpisode
+	open     *OverdraftEpisode
+	timer    Timer
+}
+
+// SetOverdraft grants the account an overdraft facility, or replaces its policy. The new
+// policy's fees apply from the next fee charged.
+func (s *AccountStore) SetOverdraft(accountID string, policy OverdraftPolicy) error {
+	switch {
+	case policy.Limit < 0:
+		return errors.New("overdraft limit must not be negative")
+	case policy.GracePeriod < 0 || policy.FeeInterval < 0:
+		return errors.New("overdraft periods must not be negative")
+	}
+	for _, fee := range policy.Fees {
+		if fee < 0 {
+			return errors.New("overdraft fees must not be negative")
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSetOverdraft, AccountID: accountID, Overdraft: &policy})
+
+	if _, exists := s.accounts.lookup(accountID); !exists {
+		return ErrAccountNotFound
+	}
+	facility, exists := s.overdrafts[accountID]
+	if !exists {
+		facility = &overdraftFacility{}
+		s.overdrafts[accountID] = facility
+	}
+	facility.policy = policy
+	return nil
+}
+
+// OverdraftHistory returns the account's overdraft episodes, oldest first.
+func (s *AccountStore) OverdraftHistory(accountID string) ([]OverdraftEpisode, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	facility, exists := s.overdrafts[accountID]
+	if !exists {
+		if _, exists := s.accounts.lookup(accountID); !exists {
+			return nil, ErrAccountNotFound
+		}
+		return nil, nil
+	}
+	episodes := make([]OverdraftEpisode, len(facility.episodes))
+	for i, episode := range facility.episodes {
+		episodes[i] = *episode
+		episodes[i].Fees = append([]OverdraftFee(nil), episode.Fees...)
+	}
+	return episodes, nil
+}
+
+// overdraftLimit is how far below zero the account may go. Callers must hold the lock.
+func (s *AccountStore) overdraftLimit(accountID string) float64 {
+	if facility, exists := s.overdrafts[accountID]; exists {
+		return facility.policy.Limit
+	}
+	return 0
+}
+
+// trackOverdrafts opens or cures overdraft episodes for the accounts an event touched.
+// Callers must hold the write lock.
+func (s *AccountStore) trackOverdrafts(event Event) {
+	accountIDs := append([]string{event.AccountID, event.CounterpartyID}, event.SourceIDs...)
+	for _, accountID := range accountIDs {
+		facility, exists := s.overdrafts[accountID]
+		if !exists {
+			continue
+		}
+		account, exists := s.accounts.lookup(accountID)
+		switch {
+		case !exists:
+			s.closeOverdraft(facility, event.Timestamp)
+			delete(s.overdrafts, accountID)
+		case account.balance >= 0:
+			s.closeOverdraft(facility, event.Timestamp)
+		case facility.open == nil:
+			facility.open = &OverdraftEpisode{StartedAt: event.Timestamp, Deepest: account.balance}
+			facility.episodes = append(facility.episodes, facility.open)
+			s.armOverdraftFee(accountID, facility, facility.policy.GracePeriod)
+		default:
+			facility.open.Deepest = min(facility.open.Deepest, account.balance)
+		}
+	}
+}
+
+func (s *AccountStore) closeOverdraft(facility *overdraftFacility, timestamp int) {
+	if facility.open == nil {
+		return
+	}
+	facility.open.CuredAt = timestamp
+	facility.open = nil
+	if facility.timer != nil {
+		facility.timer.Stop()
+		facility.timer = nil
+	}
+}
+
+func (s *AccountStore) armOverdraftFee(accountID string, facility *overdraftFacility, delay time.Duration) {
+	episode := facility.open
+	facility.timer = s.clock.AfterFunc(delay, func() {
+		s.chargeOverdraftFee(accountID, facility, episode)
+	})
+}
+
+// chargeOverdraftFee moves the next penalty fee to SystemFeeIncome if the episode is still open,
+// and arms the one after it.
+func (s *AccountStore) chargeOverdraftFee(accountID string, facility *overdraftFacility, episode *OverdraftEpisode) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists || facility.open != episode || len(facility.policy.Fees) == 0 {
+		return
+	}
+	fees := facility.policy.Fees
+	fee := fees[min(len(episode.Fees), len(fees)-1)]
+	now := int(s.clock.Now().Unix())
+
+	income := s.systemAccount(SystemFeeIncome)
+	charged := account.snapshot()
+	charged.Balance -= fee
+	charged.UpdatedAt = now
+	collected := income.snapshot()
+	collected.Balance += fee
+	collected.UpdatedAt = now
+	if err := s.persist(StorageBatch{Put: []AccountSnapshot{charged, collected}}); err != nil {
+		s.logger.Error("charging overdraft fee", "accountID", accountID, "error", err)
+	} else {
+		account.restore(charged)
+		income.restore(collected)
+		s.systemAccounts[SystemFeeIncome] = income
+		episode.Fees = append(episode.Fees, OverdraftFee{Timestamp: now, Amount: fee})
+		s.record(Event{Timestamp: now, Type: EventOverdraftFee, AccountID: accountID, CounterpartyID: SystemFeeIncome, Amount: fee})
+	}
+
+	if facility.policy.FeeInterval > 0 {
+		s.armOverdraftFee(accountID, facility, facility.policy.FeeInterval)
+	} else {
+		facility.timer = nil
+	}
+}
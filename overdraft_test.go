@@ -0,0 +1,78 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverdraft(t *testing.T) {
+	policy := OverdraftPolicy{Limit: 500, GracePeriod: time.Hour, FeeInterval: time.Hour, Fees: []float64{10, 25}}
+
+	t.Run("Cured Within Grace Period Is Free", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(0, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(0, "a", 100)
+		store.CreateAccount(0, "b", 1000)
+		store.SetOverdraft("a", policy)
+
+		// ACT
+		_, overdrawErr := store.Transfer(10, "a", "b", 300)
+		clock.Advance(30 * time.Minute)
+		store.Transfer(1800, "b", "a", 300)
+		clock.Advance(time.Hour)
+
+		// ASSERT
+		assert.NoError(t, overdrawErr, "transfers within the overdraft limit should go through")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(100), account.Balance, "no fee should be charged")
+		history, _ := store.OverdraftHistory("a")
+		assert.Equal(t, []OverdraftEpisode{{StartedAt: 10, CuredAt: 1800, Deepest: -200}}, history, "history mismatch")
+	})
+
+	t.Run("Escalating Fees Until Cured", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(0, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(0, "a", 100)
+		store.CreateAccount(0, "b", 1000)
+		store.SetOverdraft("a", policy)
+		store.Transfer(0, "a", "b", 300)
+
+		// ACT
+		clock.Advance(time.Hour)
+		clock.Advance(time.Hour)
+		clock.Advance(time.Hour)
+		store.Transfer(10800, "b", "a", 500)
+		clock.Advance(time.Hour)
+
+		// ASSERT
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(240), account.Balance, "fees should escalate and repeat the last one")
+		history, _ := store.OverdraftHistory("a")
+		assert.Equal(t, []OverdraftEpisode{{StartedAt: 0, CuredAt: 10800, Deepest: -260, Fees: []OverdraftFee{
+			{Timestamp: 3600, Amount: 10},
+			{Timestamp: 7200, Amount: 25},
+			{Timestamp: 10800, Amount: 25},
+		}}}, history, "history mismatch")
+		view, _ := store.StateAt(10800)
+		replayed, _ := view.Account("a")
+		assert.Equal(t, float64(240), replayed.Balance, "history should replay the fees")
+	})
+
+	t.Run("Limit Bounds Spending", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(0, "a", 100)
+		store.CreateAccount(0, "b", 0)
+		store.SetOverdraft("a", policy)
+
+		// ACT
+		_, err := store.Transfer(1, "a", "b", 700)
+
+		// ASSERT
+		assert.ErrorIs(t, err, ErrInsufficientBalance, "expected the overdraft limit to apply")
+	})
+}
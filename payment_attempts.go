@@ -0,0 +1,49 @@
+package bankingsystem
+
+import (
+	"time"
+)
+
+type PaymentOutcome string
+
+const (
+	PaymentExecuted PaymentOutcome = "executed"
+	PaymentFailed   PaymentOutcome = "failed"
+)
+
+// Failure reasons recorded on failed payment attempts.
+const (
+	FailureAccountNotFound   = "account not found"
+	FailureInsufficientFunds = "insufficient funds"
+	FailureStorageError      = "storage error"
+	FailureAccountInactive   = "account not active"
+)
+
+// PaymentAttempt records one execution attempt of a scheduled payment.
+type PaymentAttempt struct {
+	AttemptedAt   time.Time
+	Outcome       PaymentOutcome
+	FailureReason string
+}
+
+// GetPaymentAttempts returns the execution attempts of a scheduled payment, oldest first.
+func (s *AccountStore) GetPaymentAttempts(paymentID string) ([]PaymentAttempt, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	payment, exists := s.scheduledPayments[paymentID]
+	if !exists {
+		return nil, ErrPaymentNotFound
+	}
+	return append([]PaymentAttempt(nil), payment.attempts...), nil
+}
+
+// recordAttempt appends an attempt to a payment. Callers must hold the write lock.
+func (s *AccountStore) recordAttempt(payment *scheduledPayment, failureReason string) {
+	attempt := PaymentAttempt{AttemptedAt: s.clock.Now(), Outcome: PaymentExecuted}
+	if failureReason != "" {
+		attempt.Outcome = PaymentFailed
+		attempt.FailureReason = failureReason
+	}
+	payment.attempts = append(payment.attempts, attempt)
+}
@@ -0,0 +1,85 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetPaymentAttempts(t *testing.T) {
+	clock := newManualClock(time.Unix(100, 0))
+	store := NewAccountStore(WithClock(clock))
+
+	t.Run("Executed Payment", func(t *testing.T) {
+		// ARRANGE
+		accountID := randomAccountID()
+		store.CreateAccount(100, accountID, 1000)
+		paymentID, _ := store.SchedulePayment(100, accountID, 200, 10)
+		clock.Advance(10 * time.Second)
+
+		// ACT
+		attempts, err := store.GetPaymentAttempts(*paymentID)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error fetching attempts")
+		assert.Equal(t, []PaymentAttempt{{AttemptedAt: clock.Now(), Outcome: PaymentExecuted}}, attempts, "attempts mismatch")
+	})
+
+	t.Run("Insufficient Funds", func(t *testing.T) {
+		// ARRANGE
+		accountID := randomAccountID()
+		store.CreateAccount(100, accountID, 100)
+		paymentID, _ := store.SchedulePayment(100, accountID, 200, 20)
+		clock.Advance(20 * time.Second)
+
+		// ACT
+		attempts, err := store.GetPaymentAttempts(*paymentID)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error fetching attempts")
+		assert.Len(t, attempts, 1, "expected one attempt")
+		assert.Equal(t, PaymentFailed, attempts[0].Outcome, "outcome mismatch")
+		assert.Equal(t, FailureInsufficientFunds, attempts[0].FailureReason, "failure reason mismatch")
+	})
+
+	t.Run("Merged Away Account", func(t *testing.T) {
+		// ARRANGE
+		fromID := randomAccountID()
+		toID := randomAccountID()
+		store.CreateAccount(100, fromID, 1000)
+		store.CreateAccount(100, toID, 1000)
+		paymentID, _ := store.SchedulePayment(100, fromID, 200, 40)
+		store.MergeAccounts(110, fromID, toID)
+		clock.Advance(20 * time.Second)
+
+		// ACT
+		attempts, err := store.GetPaymentAttempts(*paymentID)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error fetching attempts")
+		assert.Equal(t, FailureAccountNotFound, attempts[0].FailureReason, "failure reason mismatch")
+	})
+
+	t.Run("Pending Payment", func(t *testing.T) {
+		// ARRANGE
+		accountID := randomAccountID()
+		store.CreateAccount(100, accountID, 1000)
+		paymentID, _ := store.SchedulePayment(100, accountID, 200, 3600)
+
+		// ACT
+		attempts, err := store.GetPaymentAttempts(*paymentID)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error fetching attempts")
+		assert.Empty(t, attempts, "expected no attempts yet")
+	})
+
+	t.Run("Non-Existent Payment", func(t *testing.T) {
+		// ACT
+		_, err := store.GetPaymentAttempts("nonexistent-payment")
+
+		// ASSERT
+		assert.EqualError(t, err, "payment not found", "unexpected error message")
+	})
+}
@@ -0,0 +1,176 @@
+package bankingsystem
+
+import (
+	"sort"
+	"time"
+)
+
+// WithPaymentBatching coalesces scheduled payments into batches: every payment due within the
+// same window runs at the end of that window, together with the rest of its batch, under one
+// acquisition of the lock and one Storage batch. Windows are aligned to the Unix epoch, so
+// with a one-minute window a payment due at 00:00:10 runs at 00:01:00. This trades up to one
+// window of lateness for throughput when many payments fall due at once.
+func WithPaymentBatching(window time.Duration) Option {
+	return func(s *AccountStore) {
+		s.batchWindow = window
+	}
+}
+
+// paymentBatch is the set of payments that run together at a window boundary.
+type paymentBatch struct {
+	at       int
+	payments map[*scheduledPayment]bool
+	timer    Timer
+	fired    bool
+}
+
+// batchedTimer is a payment's handle on its batch. Stopping it takes the payment out of the
+// batch, and stops the batch once it is empty.
+type batchedTimer struct {
+	store   *AccountStore
+	batch   *paymentBatch
+	payment *scheduledPayment
+}
+
+// Stop is called with the store's write lock held.
+func (t *batchedTimer) Stop() bool {
+	if t.batch.fired || !t.batch.payments[t.payment] {
+		return false
+	}
+	delete(t.batch.payments, t.payment)
+	if len(t.batch.payments) == 0 {
+		t.batch.timer.Stop()
+		delete(t.store.paymentBatches, t.batch.at)
+	}
+	return true
+}
+
+// addToBatch puts the payment in the batch for its window, creating the batch and its timer
+// if needed. Callers must hold the write lock.
+func (s *AccountStore) addToBatch(payment *scheduledPayment) {
+	window := int(s.batchWindow / time.Second)
+	if window < 1 {
+		window = 1
+	}
+	at := payment.executeAt
+	if now := int(s.clock.Now().Unix()); len(payment.attempts) > 0 && at <= now {
+		// A retry never joins the batch it just failed in.
+		at = now + 1
+	}
+	if remainder := at % window; remainder != 0 {
+		at += window - remainder
+	}
+
+	batch, exists := s.paymentBatches[at]
+	if !exists {
+		batch = &paymentBatch{at: at, payments: make(map[*scheduledPayment]bool)}
+		delay := time.Unix(int64(at), 0).Sub(s.clock.Now())
+		if delay < 0 {
+			delay = 0
+		}
+		batch.timer = s.clock.AfterFunc(delay, func() {
+			s.executeBatch(batch)
+		})
+		s.paymentBatches[at] = batch
+	}
+	batch.payments[payment] = true
+	payment.timer = &batchedTimer{store: s, batch: batch, payment: payment}
+}
+
+// executeBatch runs every payment in the batch, or holds them while scheduling is paused.
+func (s *AccountStore) executeBatch(batch *paymentBatch) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch.fired = true
+	if s.paymentBatches[batch.at] == batch {
+		delete(s.paymentBatches, batch.at)
+	}
+
+	payments := make([]*scheduledPayment, 0, len(batch.payments))
+	for payment := range batch.payments {
+		payments = append(payments, payment)
+	}
+	if s.schedulingPaused {
+		for _, payment := range payments {
+			s.holdPayment(payment)
+		}
+		return
+	}
+	s.runPayments(batch.at, payments)
+}
+
+// runPayments runs payments in due order, then priority order. Each payment is checked against
+// the balance left by the ones before it, and all that pass are written in one Storage batch,
+// so a storage failure fails the whole batch. Callers must hold the write lock.
+func (s *AccountStore) runPayments(at int, payments []*scheduledPayment) {
+	sortPayments(payments)
+
+	// Batch members are about to run, so none of them counts as pending against the others.
+	for _, payment := range payments {
+		payment.executed = true
+	}
+
+	failures := make(map[*scheduledPayment]string)
+	projected := make(map[string]AccountSnapshot)
+	debited := make(map[string]float64)
+	var applied []*scheduledPayment
+	for _, payment := range payments {
+		account, exists := s.accounts.lookup(payment.accountID)
+		if !exists {
+			failures[payment] = FailureAccountNotFound
+			continue
+		}
+		if checkOperation(account, opDebit) != nil {
+			failures[payment] = FailureAccountInactive
+			continue
+		}
+		if s.availableBalance(account, payment.executeAt, payment)-debited[payment.accountID] < payment.amount {
+			failures[payment] = FailureInsufficientFunds
+			continue
+		}
+
+		snapshot, seen := projected[payment.accountID]
+		if !seen {
+			snapshot = account.snapshot()
+		}
+		snapshot.Balance -= payment.amount
+		snapshot.TotalTransferred += payment.amount
+		projected[payment.accountID] = snapshot
+		debited[payment.accountID] += payment.amount
+		applied = append(applied, payment)
+	}
+
+	if len(projected) > 0 {
+		var write StorageBatch
+		for _, snapshot := range projected {
+			write.Put = append(write.Put, snapshot)
+		}
+		sort.Slice(write.Put, func(i, j int) bool {
+			return write.Put[i].AccountID < write.Put[j].AccountID
+		})
+		if err := s.persist(write); err != nil {
+			s.logger.Error("persisting payment batch", "at", at, "payments", len(applied), "error", err)
+			for _, payment := range applied {
+				failures[payment] = FailureStorageError
+			}
+			applied = nil
+		}
+	}
+
+	for _, payment := range applied {
+		s.accounts.get(payment.accountID).restore(projected[payment.accountID])
+	}
+	for _, payment := range payments {
+		failureReason := failures[payment]
+		s.recordAttempt(payment, failureReason)
+		if failureReason == "" {
+			s.record(Event{Timestamp: payment.executeAt, Type: EventPaymentExecuted, AccountID: payment.accountID, Amount: payment.amount})
+			s.markDone(payment)
+			continue
+		}
+		s.logger.Warn("skipping batched payment", "paymentID", payment.paymentID, "accountID", payment.accountID, "reason", failureReason)
+		payment.executed = false
+		s.handleFailedPayment(payment, failureReason)
+	}
+}
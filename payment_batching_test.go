@@ -0,0 +1,74 @@
+package bankingsystem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentBatching(t *testing.T) {
+	t.Run("Runs Due Payments In One Storage Batch", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(6000, 0))
+		storage := &countingStorage{Storage: NewMemoryStorage()}
+		store := NewAccountStore(WithClock(clock), WithStorage(storage), WithPaymentBatching(time.Minute))
+		store.CreateAccount(6000, "a", 100)
+		store.CreateAccount(6000, "b", 100)
+		first, _ := store.SchedulePayment(6000, "a", 60, 10)
+		second, _ := store.SchedulePayment(6000, "a", 60, 20)
+		third, _ := store.SchedulePayment(6000, "b", 30, 50)
+		cancelled, _ := store.SchedulePayment(6000, "b", 30, 40)
+		assert.NoError(t, store.CancelScheduledPayment(*cancelled), "unexpected error during cancellation")
+		writes := storage.applies
+
+		// ACT
+		clock.Advance(59 * time.Second)
+		early, _ := store.GetAccount("a")
+		clock.Advance(time.Second)
+
+		// ASSERT
+		assert.Equal(t, float64(100), early.Balance, "batch should wait for the window to close")
+		assert.Equal(t, 1, storage.applies-writes, "expected one storage write for the batch")
+		a, _ := store.GetAccount("a")
+		b, _ := store.GetAccount("b")
+		assert.Equal(t, float64(40), a.Balance, "the first payment should execute")
+		assert.Equal(t, float64(70), b.Balance, "the other account's payment should execute")
+		attempts, _ := store.GetPaymentAttempts(*second)
+		assert.Equal(t, FailureInsufficientFunds, attempts[0].FailureReason, "the second payment should see the first one's debit")
+		firstAttempts, _ := store.GetPaymentAttempts(*first)
+		thirdAttempts, _ := store.GetPaymentAttempts(*third)
+		assert.Equal(t, PaymentExecuted, firstAttempts[0].Outcome, "outcome mismatch")
+		assert.Equal(t, PaymentExecuted, thirdAttempts[0].Outcome, "outcome mismatch")
+	})
+
+	t.Run("Retries Wait For The Next Window", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(6000, 0))
+		store := NewAccountStore(WithClock(clock), WithPaymentBatching(time.Minute), WithPaymentRetries(1, time.Second))
+		store.CreateAccount(6000, "a", 10)
+		paymentID, _ := store.SchedulePayment(6000, "a", 50, 10)
+
+		// ACT
+		clock.Advance(time.Minute)
+		afterFirst, _ := store.GetPaymentAttempts(*paymentID)
+		clock.Advance(time.Minute)
+
+		// ASSERT
+		assert.Len(t, afterFirst, 1, "retry should not run in the batch it failed in")
+		attempts, _ := store.GetPaymentAttempts(*paymentID)
+		assert.Len(t, attempts, 2, "retry should run with the next batch")
+		assert.Len(t, store.ListDeadLetters(), 1, "exhausted payment should be dead-lettered")
+	})
+}
+
+type countingStorage struct {
+	Storage
+	applies int
+}
+
+func (c *countingStorage) Apply(ctx context.Context, batch StorageBatch) error {
+	c.applies++
+	return c.Storage.Apply(ctx, batch)
+}
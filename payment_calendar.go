@@ -0,0 +1,64 @@
+package bankingsystem
+
+import (
+	"time"
+)
+
+// PaymentObligation is a pending payment out of an account.
+type PaymentObligation struct {
+	PaymentID string
+	Amount    float64
+	DueAt     int
+	Priority  PaymentPriority
+}
+
+// PaymentCalendarDay is one day of an account's payment calendar.
+type PaymentCalendarDay struct {
+	// Date is formatted as "2006-01-02", in the cut-off's time zone if the store has one and
+	// in UTC otherwise.
+	Date        string
+	Obligations []PaymentObligation
+	Total       float64
+}
+
+// GetPaymentCalendar returns the account's pending scheduled payments due in [from, to),
+// grouped by due date, earliest first, and within a day in the order they would run. Days
+// without payments are left out. Standing orders and payroll are scheduled payments with their
+// priority, so they appear alongside the others; payments held while scheduling is paused
+// keep their original due time.
+func (s *AccountStore) GetPaymentCalendar(accountID string, from, to int) ([]PaymentCalendarDay, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.accounts.lookup(accountID); !exists {
+		return nil, ErrAccountNotFound
+	}
+	var pending []*scheduledPayment
+	for _, payment := range s.scheduledPayments {
+		if payment.accountID == accountID && !payment.executed && payment.executeAt >= from && payment.executeAt < to {
+			pending = append(pending, payment)
+		}
+	}
+	sortPayments(pending)
+
+	location := time.UTC
+	if s.cutOff != nil {
+		location = s.cutOff.location
+	}
+	var days []PaymentCalendarDay
+	for _, payment := range pending {
+		date := time.Unix(int64(payment.executeAt), 0).In(location).Format(time.DateOnly)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, PaymentCalendarDay{Date: date})
+		}
+		day := &days[len(days)-1]
+		day.Obligations = append(day.Obligations, PaymentObligation{
+			PaymentID: payment.paymentID,
+			Amount:    payment.amount,
+			DueAt:     payment.executeAt,
+			Priority:  payment.priority,
+		})
+		day.Total += payment.amount
+	}
+	return days, nil
+}
@@ -0,0 +1,60 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentCalendar(t *testing.T) {
+	t.Run("Groups Pending Payments By Date", func(t *testing.T) {
+		// ARRANGE
+		start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		now := int(start.Unix())
+		clock := newManualClock(start)
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(now, "a", 1000)
+		store.CreateAccount(now, "b", 1000)
+		rent, _ := store.SchedulePaymentWithPriority(now, "a", 500, 10*3600, PriorityStandingOrder)
+		coffee, _ := store.SchedulePayment(now, "a", 5, 10*3600)
+		cancelled, _ := store.SchedulePayment(now, "a", 50, 12*3600)
+		store.CancelScheduledPayment(*cancelled)
+		nextDay, _ := store.SchedulePayment(now, "a", 20, 30*3600)
+		store.SchedulePayment(now, "a", 70, 80*3600)
+		store.SchedulePayment(now, "b", 30, 10*3600)
+
+		// ACT
+		days, err := store.GetPaymentCalendar("a", now, now+72*3600)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error reading calendar")
+		assert.Equal(t, []PaymentCalendarDay{
+			{Date: "2026-01-01", Total: 505, Obligations: []PaymentObligation{
+				{PaymentID: *rent, Amount: 500, DueAt: now + 10*3600, Priority: PriorityStandingOrder},
+				{PaymentID: *coffee, Amount: 5, DueAt: now + 10*3600},
+			}},
+			{Date: "2026-01-02", Total: 20, Obligations: []PaymentObligation{
+				{PaymentID: *nextDay, Amount: 20, DueAt: now + 30*3600},
+			}},
+		}, days, "calendar mismatch")
+		_, err = store.GetPaymentCalendar("missing", now, now+3600)
+		assert.ErrorIs(t, err, ErrAccountNotFound, "expected unknown account to fail")
+	})
+
+	t.Run("Uses The Cut-Off Time Zone", func(t *testing.T) {
+		// ARRANGE
+		start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		now := int(start.Unix())
+		store := NewAccountStore(WithClock(newManualClock(start)), WithCutOff(CutOff{At: 16 * time.Hour, Location: time.FixedZone("UTC-5", -5*3600)}))
+		store.CreateAccount(now, "a", 100)
+		store.SchedulePayment(now, "a", 10, 3*3600)
+
+		// ACT
+		days, _ := store.GetPaymentCalendar("a", now, now+24*3600)
+
+		// ASSERT
+		assert.Len(t, days, 1, "expected one day")
+		assert.Equal(t, "2025-12-31", days[0].Date, "the date should be local to the cut-off")
+	})
+}
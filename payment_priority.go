@@ -0,0 +1,48 @@
+package bankingsystem
+
+import (
+	"sort"
+)
+
+// PaymentPriority orders scheduled payments that fall due on the same account at the same
+// time; higher priorities run first. When the balance cannot cover them all, the lower
+// priorities are the ones that fail. Payments of equal priority run in the order they were
+// scheduled. With payment batching the same order applies within each batch, after due time.
+type PaymentPriority int
+
+const (
+	PrioritySweep         PaymentPriority = -10
+	PriorityNormal        PaymentPriority = 0
+	PriorityStandingOrder PaymentPriority = 10
+	PriorityPayroll       PaymentPriority = 20
+)
+
+// sortPayments orders payments by due time, then priority, then scheduling order.
+func sortPayments(payments []*scheduledPayment) {
+	sort.Slice(payments, func(i, j int) bool {
+		if payments[i].executeAt != payments[j].executeAt {
+			return payments[i].executeAt < payments[j].executeAt
+		}
+		if payments[i].priority != payments[j].priority {
+			return payments[i].priority > payments[j].priority
+		}
+		return payments[i].seq < payments[j].seq
+	})
+}
+
+// duePayments returns payment and every other pending payment on its account due at the same
+// time, in the order they must run. The others' timers are disarmed, since they run now.
+// Callers must hold the write lock.
+func (s *AccountStore) duePayments(payment *scheduledPayment) []*scheduledPayment {
+	due := []*scheduledPayment{payment}
+	for _, other := range s.scheduledPayments {
+		if other == payment || other.executed || other.accountID != payment.accountID || other.executeAt != payment.executeAt {
+			continue
+		}
+		other.timer.Stop()
+		other.armed++
+		due = append(due, other)
+	}
+	sortPayments(due)
+	return due
+}
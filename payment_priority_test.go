@@ -0,0 +1,94 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaymentPriority(t *testing.T) {
+	t.Run("Higher Priority Runs First When Funds Are Short", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(7000, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(7000, "a", 100)
+		sweep, _ := store.SchedulePaymentWithPriority(7000, "a", 80, 10, PrioritySweep)
+		standing, _ := store.SchedulePaymentWithPriority(7000, "a", 30, 10, PriorityStandingOrder)
+		payroll, _ := store.SchedulePaymentWithPriority(7000, "a", 60, 10, PriorityPayroll)
+
+		// ACT
+		clock.Advance(10 * time.Second)
+
+		// ASSERT
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(10), account.Balance, "payroll and the standing order should execute")
+		payrollAttempts, _ := store.GetPaymentAttempts(*payroll)
+		standingAttempts, _ := store.GetPaymentAttempts(*standing)
+		sweepAttempts, _ := store.GetPaymentAttempts(*sweep)
+		assert.Equal(t, PaymentExecuted, payrollAttempts[0].Outcome, "payroll should execute first")
+		assert.Equal(t, PaymentExecuted, standingAttempts[0].Outcome, "the standing order should execute second")
+		assert.Equal(t, FailureInsufficientFunds, sweepAttempts[0].FailureReason, "the sweep should fail")
+		assert.Len(t, sweepAttempts, 1, "each payment should be attempted once")
+	})
+
+	t.Run("Equal Priorities Run In Scheduling Order", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(7000, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(7000, "a", 50)
+		var paymentIDs []string
+		for i := 0; i < 12; i++ {
+			paymentID, _ := store.SchedulePayment(7000, "a", 10, 10)
+			paymentIDs = append(paymentIDs, *paymentID)
+		}
+
+		// ACT
+		clock.Advance(10 * time.Second)
+
+		// ASSERT
+		for i, paymentID := range paymentIDs {
+			attempts, _ := store.GetPaymentAttempts(paymentID)
+			if i < 5 {
+				assert.Equal(t, PaymentExecuted, attempts[0].Outcome, "the earliest payments should execute")
+			} else {
+				assert.Equal(t, FailureInsufficientFunds, attempts[0].FailureReason, "the latest payments should fail")
+			}
+		}
+	})
+
+	t.Run("Orders Payments Within A Batch", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(7000, 0))
+		store := NewAccountStore(WithClock(clock), WithPaymentBatching(time.Minute))
+		store.CreateAccount(7000, "a", 100)
+		sweep, _ := store.SchedulePaymentWithPriority(7000, "a", 80, 10, PrioritySweep)
+		payroll, _ := store.SchedulePaymentWithPriority(7000, "a", 60, 10, PriorityPayroll)
+
+		// ACT
+		clock.Advance(time.Minute)
+
+		// ASSERT
+		payrollAttempts, _ := store.GetPaymentAttempts(*payroll)
+		sweepAttempts, _ := store.GetPaymentAttempts(*sweep)
+		assert.Equal(t, PaymentExecuted, payrollAttempts[0].Outcome, "payroll should execute first")
+		assert.Equal(t, FailureInsufficientFunds, sweepAttempts[0].FailureReason, "the sweep should fail")
+	})
+
+	t.Run("Cancelled Payments Stay Cancelled", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(7000, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(7000, "a", 100)
+		cancelled, _ := store.SchedulePaymentWithPriority(7000, "a", 60, 10, PriorityPayroll)
+		store.SchedulePayment(7000, "a", 30, 10)
+		assert.NoError(t, store.CancelScheduledPayment(*cancelled), "unexpected error during cancellation")
+
+		// ACT
+		clock.Advance(10 * time.Second)
+
+		// ASSERT
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(70), account.Balance, "only the remaining payment should execute")
+	})
+}
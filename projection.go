@@ -0,0 +1,59 @@
+package bankingsystem
+
+// BalancePoint is the projected balance of an account right after a dated event.
+type BalancePoint struct {
+	Timestamp int
+	PaymentID string
+	Balance   float64
+}
+
+// BalanceProjection is the forecast balance trajectory of an account.
+type BalanceProjection struct {
+	AccountID     string
+	Points        []BalancePoint
+	FirstNegative *int
+}
+
+// ProjectBalance forecasts an account's balance up to and including untilTimestamp by applying
+// every pending scheduled payment in the order execution runs them: by due time, then by
+// priority, then in scheduling order. The first point is the current balance.
+//
+// Unlike real execution, which skips a payment the balance cannot cover, the projection applies
+// every payment so that FirstNegative reports when the account would first run short.
+func (s *AccountStore) ProjectBalance(accountID string, untilTimestamp int) (*BalanceProjection, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists {
+		return nil, ErrAccountNotFound
+	}
+
+	var pending []*scheduledPayment
+	for _, payment := range s.scheduledPayments {
+		if payment.accountID == accountID && !payment.executed && payment.executeAt <= untilTimestamp {
+			pending = append(pending, payment)
+		}
+	}
+	sortPayments(pending)
+
+	balance := account.balance
+	projection := &BalanceProjection{
+		AccountID: accountID,
+		Points:    []BalancePoint{{Timestamp: account.updatedAt, Balance: balance}},
+	}
+	for _, payment := range pending {
+		balance -= payment.amount
+		projection.Points = append(projection.Points, BalancePoint{
+			Timestamp: payment.executeAt,
+			PaymentID: payment.paymentID,
+			Balance:   balance,
+		})
+		if balance < 0 && projection.FirstNegative == nil {
+			executeAt := payment.executeAt
+			projection.FirstNegative = &executeAt
+		}
+	}
+
+	return projection, nil
+}
@@ -0,0 +1,90 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectBalance(t *testing.T) {
+	store := NewAccountStore()
+
+	t.Run("Forecasts Pending Payments In Order", func(t *testing.T) {
+		// ARRANGE
+		accountID := randomAccountID()
+		timestamp := int(time.Now().Unix())
+		store.CreateAccount(timestamp, accountID, 500)
+
+		late, _ := store.SchedulePayment(timestamp, accountID, 400, 300)
+		early, _ := store.SchedulePayment(timestamp, accountID, 200, 100)
+		beyond, _ := store.SchedulePayment(timestamp, accountID, 50, 900)
+		defer store.CancelScheduledPayment(*late)
+		defer store.CancelScheduledPayment(*early)
+		defer store.CancelScheduledPayment(*beyond)
+
+		// ACT
+		projection, err := store.ProjectBalance(accountID, timestamp+600)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during projection")
+		assert.Equal(t, []BalancePoint{
+			{Timestamp: timestamp, Balance: 500},
+			{Timestamp: timestamp + 100, PaymentID: *early, Balance: 300},
+			{Timestamp: timestamp + 300, PaymentID: *late, Balance: -100},
+		}, projection.Points, "projected series mismatch")
+		if assert.NotNil(t, projection.FirstNegative, "expected the balance to go negative") {
+			assert.Equal(t, timestamp+300, *projection.FirstNegative, "first negative timestamp mismatch")
+		}
+		assert.Equal(t, float64(500), store.accounts.get(accountID).balance, "projection should not change the balance")
+	})
+
+	t.Run("Orders Payments Due Together By Priority", func(t *testing.T) {
+		// ARRANGE
+		accountID := randomAccountID()
+		timestamp := int(time.Now().Unix())
+		store.CreateAccount(timestamp, accountID, 500)
+
+		routine, _ := store.SchedulePayment(timestamp, accountID, 100, 300)
+		payroll, _ := store.SchedulePaymentWithPriority(timestamp, accountID, 200, 300, PriorityPayroll)
+		defer store.CancelScheduledPayment(*routine)
+		defer store.CancelScheduledPayment(*payroll)
+
+		// ACT
+		projection, err := store.ProjectBalance(accountID, timestamp+600)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during projection")
+		assert.Equal(t, []BalancePoint{
+			{Timestamp: timestamp, Balance: 500},
+			{Timestamp: timestamp + 300, PaymentID: *payroll, Balance: 300},
+			{Timestamp: timestamp + 300, PaymentID: *routine, Balance: 200},
+		}, projection.Points, "payroll should be projected first, as it executes first")
+	})
+
+	t.Run("Stays Positive", func(t *testing.T) {
+		// ARRANGE
+		accountID := randomAccountID()
+		timestamp := int(time.Now().Unix())
+		store.CreateAccount(timestamp, accountID, 500)
+		paymentID, _ := store.SchedulePayment(timestamp, accountID, 100, 100)
+		defer store.CancelScheduledPayment(*paymentID)
+
+		// ACT
+		projection, err := store.ProjectBalance(accountID, timestamp+600)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during projection")
+		assert.Len(t, projection.Points, 2, "expected one projected payment")
+		assert.Nil(t, projection.FirstNegative, "balance should never go negative")
+	})
+
+	t.Run("Non-Existent Account", func(t *testing.T) {
+		// ACT
+		projection, err := store.ProjectBalance("nonexistent", 100)
+
+		// ASSERT
+		assert.Nil(t, projection, "expected no projection")
+		assert.Error(t, err, "expected error due to non-existent account")
+	})
+}
@@ -0,0 +1,264 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultQuoteTTL is how long a transfer quote stays valid unless WithTransferPricing says
+// otherwise.
+const DefaultQuoteTTL = 30 * time.Second
+
+// Errors returned when executing a quote.
+var (
+	ErrQuoteNotFound = errors.New("quote not found or already used")
+	ErrQuoteExpired  = errors.New("quote has expired")
+)
+
+// TransferPricing sets the terms of quoted transfers. Transfers made without a quote are not
+// charged fees and cannot cross currencies.
+type TransferPricing struct {
+	// FixedFee and PercentFee add up to the fee charged to the sender, in the sender's
+	// currency, on top of the amount. The fee goes to SystemFeeIncome.
+	FixedFee   float64
+	PercentFee float64
+	// Rates returns how many units of toCurrency one unit of fromCurrency buys. The empty
+	// currency is the store's original one. Without Rates, quotes between accounts in different
+	// currencies fail with ErrCurrencyMismatch.
+	Rates func(fromCurrency, toCurrency string) (float64, error)
+	// QuoteTTL is how long quotes stay valid. The default is DefaultQuoteTTL.
+	QuoteTTL time.Duration
+}
+
+// WithTransferPricing sets the fees and exchange rates QuoteTransfer quotes.
+func WithTransferPricing(pricing TransferPricing) Option {
+	return func(s *AccountStore) {
+		s.pricing = pricing
+	}
+}
+
+// TransferQuote is the terms QuoteTransfer offers for moving Amount from FromID to ToID.
+type TransferQuote struct {
+	ID     string
+	FromID string
+	ToID   string
+	// Amount and Fee are debited from FromID in FromCurrency. Rate converts Amount into
+	// ConvertedAmount, credited to ToID in ToCurrency, and is 1 between accounts in the same
+	// currency.
+	Amount          float64
+	Fee             float64
+	FromCurrency    string
+	ToCurrency      string
+	Rate            float64
+	ConvertedAmount float64
+	// Limits is how the transfer would stand against the sender's limits when quoted.
+	Limits LimitImpact
+	// QuotedAt and ExpiresAt are Unix seconds by the store's clock. The quote can be executed
+	// up to and including ExpiresAt.
+	QuotedAt  int
+	ExpiresAt int
+}
+
+// LimitImpact is how a transfer stands against the sender's limits.
+type LimitImpact struct {
+	// AvailableBefore and AvailableAfter are the sender's available balance without and with
+	// the transfer and its fee.
+	AvailableBefore float64
+	AvailableAfter  float64
+	// TransferLimit is the most a single transfer may move, or zero for no limit.
+	TransferLimit float64
+	// DailyVolumeUsed is the volume the sender's tenant will have transferred today, this
+	// transfer included, out of DailyVolumeLimit. Both are zero if the tenant has no quota.
+	DailyVolumeUsed  float64
+	DailyVolumeLimit float64
+}
+
+// QuoteTransfer validates a transfer of amount from fromID to toID as of now by the store's
+// clock, and returns its fee, exchange rate and converted amount along with its effect on the
+// sender's limits. Executing the quote with TransferWithQuote before it expires applies exactly
+// those terms, even if the pricing has changed in the meantime. Balances, limits and rules are
+// checked again on execution.
+func (s *AccountStore) QuoteTransfer(fromID, toID string, amount float64) (*TransferQuote, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceQuoteTransfer, AccountID: fromID, CounterpartyID: toID, Amount: amount})
+
+	now := int(s.clock.Now().Unix())
+	fromAccount, toAccount, err := s.checkTransfer(now, fromID, toID, amount, true)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkTransferRules(now, fromAccount, toID, amount); err != nil {
+		return nil, err
+	}
+
+	rate := 1.0
+	if fromAccount.currency != toAccount.currency {
+		if s.pricing.Rates == nil {
+			return nil, ErrCurrencyMismatch
+		}
+		if rate, err = s.pricing.Rates(fromAccount.currency, toAccount.currency); err != nil {
+			return nil, fmt.Errorf("quoting exchange rate: %w", err)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("exchange rate %v from %q to %q must be positive", rate, fromAccount.currency, toAccount.currency)
+		}
+	}
+	fee := roundCents(s.pricing.FixedFee + amount*s.pricing.PercentFee/100)
+	available := s.availableBalance(fromAccount, now, nil)
+	if available < amount+fee {
+		return nil, insufficientBalance(fromID, amount+fee, available)
+	}
+
+	seq, err := s.nextSequence(SequenceQuote)
+	if err != nil {
+		return nil, err
+	}
+	quote := &TransferQuote{
+		ID:              fmt.Sprintf("quote-%d", seq),
+		FromID:          fromID,
+		ToID:            toID,
+		Amount:          amount,
+		Fee:             fee,
+		FromCurrency:    fromAccount.currency,
+		ToCurrency:      toAccount.currency,
+		Rate:            rate,
+		ConvertedAmount: roundCents(amount * rate),
+		Limits: LimitImpact{
+			AvailableBefore: available,
+			AvailableAfter:  available - amount - fee,
+			TransferLimit:   s.limits.MaxTransferAmount,
+		},
+		QuotedAt:  now,
+		ExpiresAt: now + int(s.quoteTTL()/time.Second),
+	}
+	if fromAccount.tenantID != "" {
+		if limit := s.tenantQuotas[fromAccount.tenantID].DailyTransferVolume; limit > 0 {
+			quote.Limits.DailyVolumeUsed = s.tenantVolume[fromAccount.tenantID][dayOf(now)] + amount
+			quote.Limits.DailyVolumeLimit = limit
+		}
+	}
+	s.quotes[quote.ID] = quote
+	copied := *quote
+	return &copied, nil
+}
+
+// TransferWithQuote executes a quote from QuoteTransfer on its terms. A quote can be executed
+// once, and fails with ErrQuoteExpired once the store's clock is past its ExpiresAt. The
+// transfer is recorded as a transfer event, carrying the converted amount if it crosses
+// currencies, followed by a fee event if there is a fee.
+func (s *AccountStore) TransferWithQuote(timestamp int, quoteID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceTransferWithQuote, Timestamp: timestamp, ID: quoteID})
+
+	quote, exists := s.quotes[quoteID]
+	if !exists {
+		return ErrQuoteNotFound
+	}
+	if int(s.clock.Now().Unix()) > quote.ExpiresAt {
+		delete(s.quotes, quoteID)
+		return ErrQuoteExpired
+	}
+
+	fromAccount, toAccount, err := s.checkTransfer(timestamp, quote.FromID, quote.ToID, quote.Amount, true)
+	if err != nil {
+		return err
+	}
+	if fromAccount.currency != quote.FromCurrency || toAccount.currency != quote.ToCurrency {
+		return fmt.Errorf("%w: an account was redenominated after the quote", ErrCurrencyMismatch)
+	}
+	if available := s.availableBalance(fromAccount, timestamp, nil); available < quote.Amount+quote.Fee {
+		return insufficientBalance(quote.FromID, quote.Amount+quote.Fee, available)
+	}
+	flags, err := s.checkTransferRules(timestamp, fromAccount, quote.ToID, quote.Amount)
+	if err != nil {
+		return err
+	}
+
+	income := s.systemAccount(SystemFeeIncome)
+	targets := map[string]*Account{quote.FromID: fromAccount, quote.ToID: toAccount}
+	projected := map[string]AccountSnapshot{quote.FromID: fromAccount.snapshot()}
+	from := projected[quote.FromID]
+	from.Balance -= quote.Amount + quote.Fee
+	from.TotalTransferred += quote.Amount
+	from.UpdatedAt = timestamp
+	projected[quote.FromID] = from
+	to, seen := projected[quote.ToID]
+	if !seen {
+		to = toAccount.snapshot()
+	}
+	to.Balance += quote.ConvertedAmount
+	to.UpdatedAt = timestamp
+	projected[quote.ToID] = to
+	if quote.Fee > 0 {
+		collected := income.snapshot()
+		collected.Balance += quote.Fee
+		collected.UpdatedAt = timestamp
+		projected[SystemFeeIncome] = collected
+		targets[SystemFeeIncome] = income
+	}
+
+	var batch StorageBatch
+	for _, snapshot := range projected {
+		batch.Put = append(batch.Put, snapshot)
+	}
+	sort.Slice(batch.Put, func(i, j int) bool {
+		return batch.Put[i].AccountID < batch.Put[j].AccountID
+	})
+	if err := s.persist(batch); err != nil {
+		return err
+	}
+	for accountID, account := range targets {
+		account.restore(projected[accountID])
+	}
+	if quote.Fee > 0 {
+		s.systemAccounts[SystemFeeIncome] = income
+	}
+	delete(s.quotes, quoteID)
+	s.addTransferVolume(fromAccount.tenantID, timestamp, quote.Amount)
+
+	event := Event{Timestamp: timestamp, Type: EventTransfer, AccountID: quote.FromID, CounterpartyID: quote.ToID, Amount: quote.Amount}
+	if quote.FromCurrency != quote.ToCurrency {
+		event.CreditedAmount = quote.ConvertedAmount
+	}
+	if valueDate := s.ValueDate(timestamp); valueDate != timestamp {
+		event.ValueTimestamp = valueDate
+	}
+	s.record(event)
+	if quote.Fee > 0 {
+		s.record(Event{Timestamp: timestamp, Type: EventTransferFee, AccountID: quote.FromID, CounterpartyID: SystemFeeIncome, Amount: quote.Fee})
+	}
+	if len(flags) > 0 {
+		s.flagTransfer(event, flags)
+	}
+	return nil
+}
+
+func (s *AccountStore) quoteTTL() time.Duration {
+	if s.pricing.QuoteTTL <= 0 {
+		return DefaultQuoteTTL
+	}
+	return s.pricing.QuoteTTL
+}
+
+// forgetExpiredQuotes drops the quotes that can no longer be executed and returns how many.
+// Callers must hold the write lock.
+func (s *AccountStore) forgetExpiredQuotes(now int) int {
+	expired := 0
+	for id, quote := range s.quotes {
+		if now > quote.ExpiresAt {
+			delete(s.quotes, id)
+			expired++
+		}
+	}
+	return expired
+}
+
+// roundCents rounds amount to a hundredth.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,113 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuoteTransfer(t *testing.T) {
+	t.Run("Quotes And Charges The Fee", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		store := NewAccountStore(WithClock(clock), WithTransferPricing(TransferPricing{FixedFee: 1, PercentFee: 0.5}), WithLimits(Limits{MaxTransferAmount: 500}))
+		store.CreateAccount(1000, "a", 300)
+		store.CreateAccount(1000, "b", 0)
+
+		// ACT
+		quote, err := store.QuoteTransfer("a", "b", 200)
+		assert.NoError(t, err, "unexpected error quoting transfer")
+		executed := store.TransferWithQuote(1000, quote.ID)
+		reused := store.TransferWithQuote(1000, quote.ID)
+
+		// ASSERT
+		assert.NoError(t, executed, "unexpected error executing quote")
+		assert.Equal(t, float64(2), quote.Fee, "fee mismatch")
+		assert.Equal(t, float64(1), quote.Rate, "same-currency rate should be 1")
+		assert.Equal(t, float64(200), quote.ConvertedAmount, "converted amount mismatch")
+		assert.Equal(t, LimitImpact{AvailableBefore: 300, AvailableAfter: 98, TransferLimit: 500}, quote.Limits, "limit impact mismatch")
+		assert.Equal(t, 1000+int(DefaultQuoteTTL/time.Second), quote.ExpiresAt, "expiry mismatch")
+		assert.ErrorIs(t, reused, ErrQuoteNotFound, "a quote should only execute once")
+		from, _ := store.GetAccount("a")
+		to, _ := store.GetAccount("b")
+		assert.Equal(t, float64(98), from.Balance, "sender should pay the amount and fee")
+		assert.Equal(t, float64(200), to.Balance, "recipient balance mismatch")
+		report, _ := store.CheckIntegrity()
+		assert.True(t, report.OK(), "fee should be accounted for in the history")
+		assert.Equal(t, float64(2), report.SystemBalance, "fee income mismatch")
+	})
+
+	t.Run("Guarantees The Quoted Rate Until Expiry", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		rate := 0.9
+		store := NewAccountStore(WithClock(clock), WithTransferPricing(TransferPricing{
+			Rates: func(from, to string) (float64, error) {
+				return rate, nil
+			},
+			QuoteTTL: time.Minute,
+		}))
+		store.CreateAccount(1000, "a", 100)
+		store.CreateAccount(1000, "b", 0)
+		store.RedenominateAccount(1000, "b", "EUR", 1)
+		quote, _ := store.QuoteTransfer("a", "b", 50)
+		late, _ := store.QuoteTransfer("a", "b", 10)
+
+		// ACT
+		rate = 0.5
+		clock.Advance(time.Minute)
+		onTime := store.TransferWithQuote(1060, quote.ID)
+		clock.Advance(time.Second)
+		expired := store.TransferWithQuote(1061, late.ID)
+
+		// ASSERT
+		assert.NoError(t, onTime, "unexpected error executing quote at its expiry")
+		assert.ErrorIs(t, expired, ErrQuoteExpired, "expected expired quote to fail")
+		assert.Equal(t, "EUR", quote.ToCurrency, "currency mismatch")
+		assert.Equal(t, 0.9, quote.Rate, "rate mismatch")
+		to, _ := store.GetAccount("b")
+		assert.Equal(t, float64(45), to.Balance, "the quoted rate should apply")
+		report, _ := store.CheckIntegrity()
+		assert.Empty(t, report.Mismatches, "replay should credit the converted amount")
+		_, plain := store.Transfer(1061, "a", "b", 10)
+		assert.ErrorIs(t, plain, ErrCurrencyMismatch, "unquoted transfers should not cross currencies")
+	})
+
+	t.Run("Validates Before Quoting", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithTransferPricing(TransferPricing{FixedFee: 5}))
+		store.CreateAccount(1, "a", 100)
+		store.CreateAccount(1, "b", 0)
+		store.RedenominateAccount(1, "b", "EUR", 1)
+		store.CreateAccount(1, "c", 0)
+
+		// ACT
+		_, noRates := store.QuoteTransfer("a", "b", 10)
+		_, feeUncovered := store.QuoteTransfer("a", "c", 100)
+		_, missing := store.QuoteTransfer("a", "missing", 10)
+
+		// ASSERT
+		assert.ErrorIs(t, noRates, ErrCurrencyMismatch, "expected no rates to fail across currencies")
+		assert.ErrorIs(t, feeUncovered, ErrInsufficientBalance, "expected the fee to count against the balance")
+		assert.ErrorIs(t, missing, ErrAccountNotFound, "expected missing account to fail")
+	})
+
+	t.Run("Sweeps Expired Quotes", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(1000, "a", 100)
+		store.CreateAccount(1000, "b", 0)
+		store.QuoteTransfer("a", "b", 10)
+
+		// ACT
+		kept := store.Sweep(0)
+		clock.Advance(DefaultQuoteTTL + time.Second)
+		swept := store.Sweep(0)
+
+		// ASSERT
+		assert.Equal(t, 0, kept.ExpiredQuotes, "valid quotes should be kept")
+		assert.Equal(t, 1, swept.ExpiredQuotes, "expected the expired quote to be swept")
+	})
+}
@@ -0,0 +1,78 @@
+package bankingsystem
+
+import (
+	"errors"
+)
+
+// ErrCurrencyMismatch is returned when moving money between accounts in different currencies.
+var ErrCurrencyMismatch = errors.New("accounts are in different currencies")
+
+// RedenominateAccount converts the account to newCurrency, multiplying every amount held in
+// the old currency by rate: the balance and total transferred, the minimum balance, holds,
+// pending scheduled payments and the overdraft limit and fees. Fees already charged keep the
+// amounts they were charged in. The conversion is written to Storage and recorded in the history
+// as one change, so it applies entirely or not at all.
+func (s *AccountStore) RedenominateAccount(timestamp int, accountID, newCurrency string, rate float64) error {
+	if newCurrency == "" {
+		return errors.New("currency is required")
+	}
+	if rate <= 0 {
+		return errors.New("conversion rate must be positive")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceRedenominateAccount, Timestamp: timestamp, AccountID: accountID, Currency: newCurrency, Rate: rate})
+
+	account, exists := s.accounts.lookup(accountID)
+	if !exists {
+		return ErrAccountNotFound
+	}
+	if err := checkOperation(account, opModify); err != nil {
+		return err
+	}
+	if account.currency == newCurrency {
+		return errors.New("account is already in that currency")
+	}
+
+	converted := account.snapshot()
+	converted.Currency = newCurrency
+	converted.Balance *= rate
+	converted.TotalTransferred *= rate
+	converted.UpdatedAt = timestamp
+	if err := s.persist(StorageBatch{Put: []AccountSnapshot{converted}}); err != nil {
+		return err
+	}
+
+	account.restore(converted)
+	account.minimumBalance *= rate
+	account.held *= rate
+	for _, hold := range s.holds {
+		if hold.AccountID == accountID {
+			hold.Amount *= rate
+		}
+	}
+	for _, payment := range s.scheduledPayments {
+		if payment.accountID == accountID && !payment.executed {
+			payment.amount *= rate
+		}
+	}
+	if facility, exists := s.overdrafts[accountID]; exists {
+		facility.policy.Limit *= rate
+		fees := make([]float64, len(facility.policy.Fees))
+		for i, fee := range facility.policy.Fees {
+			fees[i] = fee * rate
+		}
+		facility.policy.Fees = fees
+	}
+
+	s.record(Event{Timestamp: timestamp, Type: EventAccountRedenominated, AccountID: accountID, Amount: rate, Currency: newCurrency})
+	return nil
+}
+
+func checkSameCurrency(a, b *Account) error {
+	if a.currency != b.currency {
+		return ErrCurrencyMismatch
+	}
+	return nil
+}
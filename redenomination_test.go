@@ -0,0 +1,91 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedenominateAccount(t *testing.T) {
+	t.Run("Converts Balance, Holds And Pending Payments", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(100, "a", 1000)
+		store.PlaceHold(100, "a", 100)
+		store.SetMinimumBalance("a", 50)
+		paymentID, _ := store.SchedulePayment(100, "a", 200, 60)
+
+		// ACT
+		err := store.RedenominateAccount(110, "a", "EUR", 0.5)
+		clock.Advance(time.Minute)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during redenomination")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, "EUR", account.Currency, "currency mismatch")
+		assert.Equal(t, float64(400), account.Balance, "balance should be converted, then debited by the converted payment")
+		assert.Equal(t, float64(325), account.AvailableBalance, "holds and minimum balance should be converted")
+		assert.Equal(t, float64(50), store.Holds("a")[0].Amount, "hold amount mismatch")
+		attempts, _ := store.GetPaymentAttempts(*paymentID)
+		assert.Equal(t, PaymentExecuted, attempts[0].Outcome, "payment should execute")
+
+		view, _ := store.StateAt(110)
+		replayed, _ := view.Account("a")
+		assert.Equal(t, AccountSnapshot{AccountID: "a", UpdatedAt: 110, Balance: 500, Currency: "EUR"}, replayed, "history should replay the conversion")
+	})
+
+	t.Run("Converts The Overdraft", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(100, "a", 100)
+		store.CreateAccount(100, "b", 0)
+		policy := OverdraftPolicy{Limit: 200, Fees: []float64{10}}
+		store.SetOverdraft("a", policy)
+		store.RedenominateAccount(100, "b", "EUR", 0.5)
+
+		// ACT
+		err := store.RedenominateAccount(100, "a", "EUR", 0.5)
+		account, _ := store.GetAccount("a")
+		store.Transfer(100, "a", "b", 100)
+		clock.Advance(time.Second)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during redenomination")
+		assert.Equal(t, float64(150), account.AvailableBalance, "overdraft limit should be converted")
+		episodes, _ := store.OverdraftHistory("a")
+		assert.Len(t, episodes, 1, "expected one overdraft episode")
+		assert.Equal(t, []OverdraftFee{{Timestamp: 101, Amount: 5}}, episodes[0].Fees, "fee should be converted")
+		assert.Equal(t, []float64{10}, policy.Fees, "the caller's policy should be left alone")
+	})
+
+	t.Run("Rejects Transfers Across Currencies", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 1000)
+		store.RedenominateAccount(2, "a", "EUR", 0.5)
+
+		// ACT
+		_, transferErr := store.Transfer(3, "a", "b", 10)
+		mergeErr := store.MergeAccounts(3, "a", "b")
+
+		// ASSERT
+		assert.ErrorIs(t, transferErr, ErrCurrencyMismatch, "expected currency mismatch")
+		assert.ErrorIs(t, mergeErr, ErrCurrencyMismatch, "expected currency mismatch")
+	})
+
+	t.Run("Invalid Rate", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+
+		// ACT
+		err := store.RedenominateAccount(2, "a", "EUR", 0)
+
+		// ASSERT
+		assert.EqualError(t, err, "conversion rate must be positive", "unexpected error message")
+	})
+}
@@ -0,0 +1,163 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers carrying the client nonce, the request time in Unix seconds and the request
+// signature on mutating requests.
+const (
+	NonceHeader            = "X-Request-Nonce"
+	RequestTimestampHeader = "X-Request-Timestamp"
+	SignatureHeader        = "X-Request-Signature"
+)
+
+var (
+	ErrReplayedRequest  = errors.New("request nonce already used")
+	ErrStaleRequest     = errors.New("request timestamp outside replay window")
+	ErrInvalidSignature = errors.New("request signature does not match")
+)
+
+// WithReplayProtection makes NewHTTPHandler reject mutating requests that do not carry a
+// fresh nonce and timestamp, as ReplayProtection does with a guard over window and key.
+func WithReplayProtection(window time.Duration, key []byte) Option {
+	return func(s *AccountStore) {
+		s.replayWindow = window
+		s.replayKey = key
+	}
+}
+
+// ReplayGuard rejects requests whose nonce was already seen within the window, and requests
+// whose timestamp is further than the window from now. Nonces only need remembering for the
+// window, because anything older is rejected as stale anyway.
+//
+// With a key, requests must also be signed with SignRequest, which binds the nonce and
+// timestamp to the request they came with. Without one, anybody who sees a request can send
+// it again under a new nonce, so an unkeyed guard only protects requests that arrive over a
+// transport that already authenticates them.
+type ReplayGuard struct {
+	mu     sync.Mutex
+	window time.Duration
+	clock  Clock
+	key    []byte
+	seen   map[string]time.Time
+	order  []seenNonce
+}
+
+type seenNonce struct {
+	nonce  string
+	seenAt time.Time
+}
+
+// NewReplayGuard returns a guard over window. key may be nil for unsigned requests.
+func NewReplayGuard(window time.Duration, clock Clock, key []byte) *ReplayGuard {
+	return &ReplayGuard{
+		window: window,
+		clock:  clock,
+		key:    key,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// SignRequest returns the hex HMAC-SHA256, under key, of a request's method, path, nonce,
+// timestamp and body, for the SignatureHeader.
+func SignRequest(key []byte, method, path, nonce string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	io.WriteString(mac, method+"\n"+path+"\n"+nonce+"\n"+strconv.FormatInt(timestamp, 10)+"\n")
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Check accepts a nonce and request timestamp once, returning ErrStaleRequest or
+// ErrReplayedRequest if the request must be rejected.
+func (g *ReplayGuard) Check(nonce string, timestamp time.Time) error {
+	if nonce == "" {
+		return errors.New("request nonce is required")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.clock.Now()
+	g.forgetBefore(now.Add(-g.window))
+
+	if skew := now.Sub(timestamp); skew > g.window || skew < -g.window {
+		return ErrStaleRequest
+	}
+	if _, seen := g.seen[nonce]; seen {
+		return ErrReplayedRequest
+	}
+
+	g.seen[nonce] = now
+	g.order = append(g.order, seenNonce{nonce: nonce, seenAt: now})
+	return nil
+}
+
+func (g *ReplayGuard) forgetBefore(cutoff time.Time) {
+	expired := 0
+	for expired < len(g.order) && g.order[expired].seenAt.Before(cutoff) {
+		delete(g.seen, g.order[expired].nonce)
+		expired++
+	}
+	g.order = g.order[expired:]
+}
+
+// ReplayProtection wraps an HTTP handler so that every mutating request (anything but GET,
+// HEAD and OPTIONS) must carry a fresh nonce and timestamp, and a signature if the guard has a
+// key. Rejections are APIErrors: CodeInvalidRequest for missing values, CodeStaleRequest,
+// CodeInvalidSignature and CodeReplayedRequest. A request with a bad signature does not use
+// up its nonce.
+func ReplayProtection(guard *ReplayGuard, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		seconds, err := strconv.ParseInt(r.Header.Get(RequestTimestampHeader), 10, 64)
+		if err != nil {
+			status, body := invalidRequest(errors.New("missing or malformed " + RequestTimestampHeader + " header"))
+			writeJSON(w, status, encodeBody(body))
+			return
+		}
+		nonce := r.Header.Get(NonceHeader)
+		if nonce == "" {
+			status, body := invalidRequest(errors.New("missing " + NonceHeader + " header"))
+			writeJSON(w, status, encodeBody(body))
+			return
+		}
+
+		if guard.key != nil {
+			payload, err := io.ReadAll(r.Body)
+			if err != nil {
+				status, body := invalidRequest(err)
+				writeJSON(w, status, encodeBody(body))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(payload))
+			want := SignRequest(guard.key, r.Method, r.URL.Path, nonce, seconds, payload)
+			if !hmac.Equal([]byte(want), []byte(r.Header.Get(SignatureHeader))) {
+				status, body := apiErrorFor(ErrInvalidSignature)
+				writeJSON(w, status, encodeBody(body))
+				return
+			}
+		}
+
+		if err := guard.Check(nonce, time.Unix(seconds, 0)); err != nil {
+			status, body := apiErrorFor(err)
+			writeJSON(w, status, encodeBody(body))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
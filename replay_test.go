@@ -0,0 +1,121 @@
+package bankingsystem
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplayGuard(t *testing.T) {
+	t.Run("Rejects Replays Within The Window", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		guard := NewReplayGuard(time.Minute, clock, nil)
+
+		// ACT
+		first := guard.Check("nonce-1", clock.Now())
+		replay := guard.Check("nonce-1", clock.Now())
+		other := guard.Check("nonce-2", clock.Now())
+
+		// ASSERT
+		assert.NoError(t, first, "first use should be accepted")
+		assert.ErrorIs(t, replay, ErrReplayedRequest, "replay should be rejected")
+		assert.NoError(t, other, "different nonce should be accepted")
+	})
+
+	t.Run("Rejects Stale And Future Timestamps", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		guard := NewReplayGuard(time.Minute, clock, nil)
+
+		// ACT
+		stale := guard.Check("nonce-1", clock.Now().Add(-2*time.Minute))
+		future := guard.Check("nonce-2", clock.Now().Add(2*time.Minute))
+
+		// ASSERT
+		assert.ErrorIs(t, stale, ErrStaleRequest, "stale request should be rejected")
+		assert.ErrorIs(t, future, ErrStaleRequest, "future request should be rejected")
+	})
+
+	t.Run("Forgets Nonces Outside The Window", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		guard := NewReplayGuard(time.Minute, clock, nil)
+		guard.Check("nonce-1", clock.Now())
+
+		// ACT
+		clock.Advance(2 * time.Minute)
+		guard.Check("nonce-2", clock.Now())
+
+		// ASSERT
+		assert.Len(t, guard.seen, 1, "expired nonces should be forgotten")
+	})
+}
+
+func TestReplayProtection(t *testing.T) {
+	send := func(handler http.Handler, method, nonce string, timestamp int64, signature string) (int, APIError) {
+		request := httptest.NewRequest(method, "/transfers", strings.NewReader("{}"))
+		if nonce != "" {
+			request.Header.Set(NonceHeader, nonce)
+			request.Header.Set(RequestTimestampHeader, strconv.FormatInt(timestamp, 10))
+			request.Header.Set(SignatureHeader, signature)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		var apiError APIError
+		json.Unmarshal(recorder.Body.Bytes(), &apiError)
+		return recorder.Code, apiError
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	t.Run("Requires Fresh Nonces", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		handler := ReplayProtection(NewReplayGuard(time.Minute, clock, nil), next)
+
+		// ACT
+		read, _ := send(handler, http.MethodGet, "", 0, "")
+		missingStatus, missing := send(handler, http.MethodPost, "", 0, "")
+		fresh, _ := send(handler, http.MethodPost, "abc", 1000, "")
+		replayStatus, replay := send(handler, http.MethodPost, "abc", 1000, "")
+		staleStatus, stale := send(handler, http.MethodPost, "def", 500, "")
+
+		// ASSERT
+		assert.Equal(t, http.StatusNoContent, read, "reads should not need a nonce")
+		assert.Equal(t, http.StatusBadRequest, missingStatus, "mutations should need a nonce")
+		assert.Equal(t, CodeInvalidRequest, missing.Code, "code mismatch")
+		assert.Equal(t, http.StatusNoContent, fresh, "fresh request should pass")
+		assert.Equal(t, http.StatusConflict, replayStatus, "replay should be rejected")
+		assert.ErrorIs(t, replay.Err(), ErrReplayedRequest, "expected the sentinel back")
+		assert.Equal(t, http.StatusBadRequest, staleStatus, "stale request should be rejected")
+		assert.ErrorIs(t, stale.Err(), ErrStaleRequest, "expected the sentinel back")
+	})
+
+	t.Run("Requires Signatures With A Key", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		key := []byte("secret")
+		handler := ReplayProtection(NewReplayGuard(time.Minute, clock, key), next)
+		signature := SignRequest(key, http.MethodPost, "/transfers", "abc", 1000, []byte("{}"))
+
+		// ACT
+		forgedStatus, forged := send(handler, http.MethodPost, "abc", 1000, SignRequest([]byte("guess"), http.MethodPost, "/transfers", "abc", 1000, []byte("{}")))
+		signed, _ := send(handler, http.MethodPost, "abc", 1000, signature)
+		renoncedStatus, renonced := send(handler, http.MethodPost, "def", 1000, signature)
+
+		// ASSERT
+		assert.Equal(t, http.StatusUnauthorized, forgedStatus, "forged signature should be rejected")
+		assert.ErrorIs(t, forged.Err(), ErrInvalidSignature, "expected the sentinel back")
+		assert.Equal(t, http.StatusNoContent, signed, "a forged request should not use up the nonce")
+		assert.Equal(t, http.StatusUnauthorized, renoncedStatus, "the signature should bind the nonce")
+		assert.ErrorIs(t, renonced.Err(), ErrInvalidSignature, "expected the sentinel back")
+	})
+}
@@ -0,0 +1,279 @@
+package bankingsystem
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// VectorClock counts the writes each region has made to an account.
+type VectorClock map[string]uint64
+
+// ClockOrdering is how two vector clocks relate.
+type ClockOrdering int
+
+const (
+	ClockEqual ClockOrdering = iota
+	ClockBefore
+	ClockAfter
+	ClockConcurrent
+)
+
+// Compare reports whether v happened before, after, at the same point as, or concurrently
+// with other.
+func (v VectorClock) Compare(other VectorClock) ClockOrdering {
+	before, after := false, false
+	for region, count := range v {
+		if count > other[region] {
+			after = true
+		}
+	}
+	for region, count := range other {
+		if count > v[region] {
+			before = true
+		}
+	}
+	switch {
+	case before && after:
+		return ClockConcurrent
+	case before:
+		return ClockBefore
+	case after:
+		return ClockAfter
+	}
+	return ClockEqual
+}
+
+// Merge returns the smallest clock that is not before v or other.
+func (v VectorClock) Merge(other VectorClock) VectorClock {
+	merged := make(VectorClock, len(v))
+	for region, count := range v {
+		merged[region] = count
+	}
+	for region, count := range other {
+		if count > merged[region] {
+			merged[region] = count
+		}
+	}
+	return merged
+}
+
+// ReplicatedAccount is one account's state as exchanged between regions. Deleted marks an
+// account removed by a merge.
+type ReplicatedAccount struct {
+	Account AccountSnapshot
+	Clock   VectorClock
+	Deleted bool
+}
+
+// Conflict records two concurrent versions of an account and the version they were resolved
+// to, for an operator to review.
+type Conflict struct {
+	ID         int
+	AccountID  string
+	Local      ReplicatedAccount
+	Remote     ReplicatedAccount
+	Resolved   ReplicatedAccount
+	DetectedAt time.Time
+}
+
+// WithRegion runs the store in multi-region mode: every account write advances the account's
+// vector clock for region, and ReplicationState and MergeReplica exchange state with the
+// stores of other regions. System accounts are not replicated: each region keeps the fees it
+// collected itself.
+func WithRegion(region string) Option {
+	return func(s *AccountStore) {
+		s.region = region
+	}
+}
+
+// advanceVersions ticks this region's entry in the clock of every customer account in batch.
+// Callers must hold the write lock.
+func (s *AccountStore) advanceVersions(batch StorageBatch) {
+	if s.region == "" {
+		return
+	}
+	for _, snapshot := range batch.Put {
+		if isSystemAccount(snapshot.AccountID) {
+			continue
+		}
+		s.tick(snapshot.AccountID)
+		delete(s.tombstones, snapshot.AccountID)
+	}
+	for _, accountID := range batch.Delete {
+		s.tick(accountID)
+		s.tombstones[accountID] = true
+	}
+}
+
+func (s *AccountStore) tick(accountID string) {
+	clock := s.versions[accountID].Merge(nil)
+	clock[s.region]++
+	s.versions[accountID] = clock
+}
+
+// ReplicationState returns every account this region knows of, including deleted ones, ordered
+// by account ID, for shipping to other regions.
+func (s *AccountStore) ReplicationState() []ReplicatedAccount {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	state := make([]ReplicatedAccount, 0, len(s.versions))
+	for accountID := range s.versions {
+		state = append(state, s.replica(accountID))
+	}
+	sort.Slice(state, func(i, j int) bool {
+		return state[i].Account.AccountID < state[j].Account.AccountID
+	})
+	return state
+}
+
+func (s *AccountStore) replica(accountID string) ReplicatedAccount {
+	replica := ReplicatedAccount{Clock: s.versions[accountID].Merge(nil), Deleted: s.tombstones[accountID]}
+	if account, exists := s.accounts.lookup(accountID); exists && !replica.Deleted {
+		replica.Account = account.snapshot()
+	} else {
+		replica.Account = AccountSnapshot{AccountID: accountID}
+	}
+	return replica
+}
+
+// MergeReplica folds another region's ReplicationState into this store and returns the
+// conflicts it found. For each account:
+//
+//   - a remote version that happened after the local one replaces it;
+//   - a remote version that happened before, or is equal, is ignored;
+//   - concurrent versions are resolved by the rules below, and the resolved version carries
+//     the merge of both clocks so that every region converges on it.
+//
+// Concurrent versions resolve to the deleted one if either is deleted, because reviving an
+// account that was merged away would count its balance twice. Otherwise the version with the
+// later UpdatedAt wins, then the one with the higher balance, then the higher total
+// transferred. The outcome does not depend on which region runs the merge.
+//
+// Merged-in changes are written to Storage but, being another region's history, are not
+// recorded as events here. Entries for system accounts are ignored.
+func (s *AccountStore) MergeReplica(remote []ReplicatedAccount) ([]Conflict, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceMergeReplica, Replicas: remote})
+
+	if s.region == "" {
+		return nil, errors.New("store is not running in multi-region mode")
+	}
+
+	var conflicts []Conflict
+	for _, incoming := range remote {
+		accountID := incoming.Account.AccountID
+		if isSystemAccount(accountID) {
+			continue
+		}
+		local := s.replica(accountID)
+
+		ordering := local.Clock.Compare(incoming.Clock)
+		resolved := incoming
+		switch ordering {
+		case ClockConcurrent:
+			resolved = resolveConcurrent(local, incoming)
+			resolved.Clock = local.Clock.Merge(incoming.Clock)
+		case ClockEqual, ClockAfter:
+			continue
+		}
+
+		if err := s.adoptReplica(resolved); err != nil {
+			s.conflicts = append(s.conflicts, conflicts...)
+			return conflicts, err
+		}
+		if ordering == ClockConcurrent {
+			conflicts = append(conflicts, Conflict{
+				ID:         s.nextConflictID,
+				AccountID:  accountID,
+				Local:      local,
+				Remote:     incoming,
+				Resolved:   resolved,
+				DetectedAt: s.clock.Now(),
+			})
+			s.nextConflictID++
+		}
+	}
+
+	s.conflicts = append(s.conflicts, conflicts...)
+	return conflicts, nil
+}
+
+func resolveConcurrent(a, b ReplicatedAccount) ReplicatedAccount {
+	if a.Deleted != b.Deleted {
+		if a.Deleted {
+			return a
+		}
+		return b
+	}
+	switch {
+	case a.Account.UpdatedAt != b.Account.UpdatedAt:
+		if a.Account.UpdatedAt > b.Account.UpdatedAt {
+			return a
+		}
+		return b
+	case a.Account.Balance != b.Account.Balance:
+		if a.Account.Balance > b.Account.Balance {
+			return a
+		}
+		return b
+	case a.Account.TotalTransferred > b.Account.TotalTransferred:
+		return a
+	}
+	return b
+}
+
+// adoptReplica writes a replicated version through to Storage and into memory without
+// advancing this region's clock. Callers must hold the write lock.
+func (s *AccountStore) adoptReplica(replica ReplicatedAccount) error {
+	accountID := replica.Account.AccountID
+	batch := StorageBatch{Put: []AccountSnapshot{replica.Account}}
+	if replica.Deleted {
+		batch = StorageBatch{Delete: []string{accountID}}
+	}
+	if err := s.write(batch); err != nil {
+		return err
+	}
+
+	if replica.Deleted {
+		s.removeAccount(accountID)
+		s.tombstones[accountID] = true
+	} else if account, exists := s.accounts.lookup(accountID); exists && account.tenantID == replica.Account.TenantID {
+		// Holds, minimum balances and tags are local to this region, so the replica only
+		// replaces the replicated state of the account already here.
+		account.restore(replica.Account)
+	} else {
+		account := &Account{accountID: accountID, tenantID: replica.Account.TenantID}
+		account.restore(replica.Account)
+		s.putAccount(account)
+		delete(s.tombstones, accountID)
+	}
+	s.versions[accountID] = replica.Clock.Merge(nil)
+	return nil
+}
+
+// Conflicts returns the conflicts awaiting review, oldest first.
+func (s *AccountStore) Conflicts() []Conflict {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]Conflict(nil), s.conflicts...)
+}
+
+// AcknowledgeConflict removes a reviewed conflict. The resolved version already stands;
+// an operator who disagrees corrects the account with a new write.
+func (s *AccountStore) AcknowledgeConflict(conflictID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceAcknowledgeConflict, Value: conflictID})
+
+	for i, conflict := range s.conflicts {
+		if conflict.ID == conflictID {
+			s.conflicts = append(s.conflicts[:i], s.conflicts[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("conflict not found")
+}
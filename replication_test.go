@@ -0,0 +1,143 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVectorClock(t *testing.T) {
+	a := VectorClock{"eu": 2, "us": 1}
+
+	assert.Equal(t, ClockEqual, a.Compare(VectorClock{"eu": 2, "us": 1}), "expected equal clocks")
+	assert.Equal(t, ClockBefore, a.Compare(VectorClock{"eu": 2, "us": 2}), "expected a before")
+	assert.Equal(t, ClockAfter, a.Compare(VectorClock{"eu": 1}), "expected a after")
+	assert.Equal(t, ClockConcurrent, a.Compare(VectorClock{"eu": 1, "us": 2}), "expected concurrent clocks")
+	assert.Equal(t, VectorClock{"eu": 2, "us": 2}, a.Merge(VectorClock{"eu": 1, "us": 2}), "merge mismatch")
+}
+
+func TestMergeReplica(t *testing.T) {
+	t.Run("Replicates Ordered Writes", func(t *testing.T) {
+		// ARRANGE
+		eu := NewAccountStore(WithRegion("eu"))
+		us := NewAccountStore(WithRegion("us"))
+		eu.CreateAccount(1, "a", 100)
+		eu.CreateAccount(1, "b", 100)
+
+		// ACT
+		conflicts, err := us.MergeReplica(eu.ReplicationState())
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error merging replica")
+		assert.Empty(t, conflicts, "expected no conflicts")
+		assert.Equal(t, eu.ReplicationState(), us.ReplicationState(), "regions should converge")
+	})
+
+	t.Run("Resolves Concurrent Writes Deterministically", func(t *testing.T) {
+		// ARRANGE
+		eu := NewAccountStore(WithRegion("eu"))
+		us := NewAccountStore(WithRegion("us"))
+		eu.CreateAccount(1, "a", 100)
+		eu.CreateAccount(1, "b", 100)
+		us.MergeReplica(eu.ReplicationState())
+		eu.Transfer(5, "a", "b", 30)
+		us.Transfer(6, "a", "b", 10)
+		euState, usState := eu.ReplicationState(), us.ReplicationState()
+
+		// ACT
+		euConflicts, euErr := eu.MergeReplica(usState)
+		usConflicts, usErr := us.MergeReplica(euState)
+
+		// ASSERT
+		assert.NoError(t, euErr, "unexpected error merging replica")
+		assert.NoError(t, usErr, "unexpected error merging replica")
+		assert.Len(t, euConflicts, 2, "expected both accounts to conflict")
+		assert.Len(t, usConflicts, 2, "expected both accounts to conflict")
+		assert.Equal(t, eu.ReplicationState(), us.ReplicationState(), "regions should converge")
+		a, _ := eu.GetAccount("a")
+		assert.Equal(t, float64(90), a.Balance, "the later write should win")
+		assert.Equal(t, VectorClock{"eu": 2, "us": 1}, euConflicts[0].Resolved.Clock, "resolved clock should merge both")
+		assert.Len(t, eu.Conflicts(), 2, "conflicts should await review")
+		assert.NoError(t, eu.AcknowledgeConflict(euConflicts[0].ID), "unexpected error acknowledging conflict")
+		assert.Len(t, eu.Conflicts(), 1, "acknowledged conflict should be removed")
+	})
+
+	t.Run("Deletion Wins Over Concurrent Update", func(t *testing.T) {
+		// ARRANGE
+		eu := NewAccountStore(WithRegion("eu"))
+		us := NewAccountStore(WithRegion("us"))
+		eu.CreateAccount(1, "a", 100)
+		eu.CreateAccount(1, "b", 100)
+		us.MergeReplica(eu.ReplicationState())
+		eu.MergeAccounts(5, "a", "b")
+		us.Transfer(9, "a", "b", 10)
+
+		// ACT
+		us.MergeReplica(eu.ReplicationState())
+
+		// ASSERT
+		_, err := us.GetAccount("a")
+		assert.ErrorIs(t, err, ErrAccountNotFound, "merged-away account should stay deleted")
+	})
+
+	t.Run("Keeps Local Holds Across Merges", func(t *testing.T) {
+		// ARRANGE
+		eu := NewAccountStore(WithRegion("eu"))
+		us := NewAccountStore(WithRegion("us"))
+		eu.CreateAccount(1, "a", 100)
+		eu.CreateAccount(1, "b", 100)
+		us.MergeReplica(eu.ReplicationState())
+		holdID, _ := us.PlaceHold(2, "a", 50)
+		eu.Transfer(3, "a", "b", 10)
+
+		// ACT
+		_, err := us.MergeReplica(eu.ReplicationState())
+		held, _ := us.GetAccount("a")
+		us.ReleaseHold(holdID)
+		released, _ := us.GetAccount("a")
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error merging replica")
+		assert.Equal(t, float64(90), held.Balance, "balance should follow the replica")
+		assert.Equal(t, float64(40), held.AvailableBalance, "the hold should survive the merge")
+		assert.Equal(t, float64(90), released.AvailableBalance, "releasing the hold should restore the balance")
+	})
+
+	t.Run("Keeps System Accounts In Their Region", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(0, 0))
+		eu := NewAccountStore(WithRegion("eu"), WithClock(clock))
+		us := NewAccountStore(WithRegion("us"))
+		eu.CreateAccount(0, "a", 100)
+		eu.CreateAccount(0, "b", 0)
+		eu.SetOverdraft("a", OverdraftPolicy{Limit: 500, Fees: []float64{15}})
+		eu.Transfer(0, "a", "b", 200)
+		clock.Advance(time.Second)
+
+		// ACT
+		_, err := us.MergeReplica(eu.ReplicationState())
+		_, forgedErr := us.MergeReplica([]ReplicatedAccount{{Account: AccountSnapshot{AccountID: SystemFeeIncome, Balance: 15}, Clock: VectorClock{"eu": 1}}})
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error merging replica")
+		assert.NoError(t, forgedErr, "unexpected error merging replica")
+		for _, replica := range eu.ReplicationState() {
+			assert.NotEqual(t, SystemFeeIncome, replica.Account.AccountID, "system accounts should not be shipped")
+		}
+		_, getErr := us.GetAccount(SystemFeeIncome)
+		assert.ErrorIs(t, getErr, ErrAccountNotFound, "system accounts should not become customer accounts")
+		assert.Empty(t, us.ListSystemAccounts(), "fees should stay in the region that charged them")
+		assert.Len(t, eu.ListSystemAccounts(), 1, "expected the fee in the charging region")
+		account, _ := us.GetAccount("a")
+		assert.Equal(t, float64(-115), account.Balance, "the fee should still reach the customer account")
+	})
+
+	t.Run("Not In Multi-Region Mode", func(t *testing.T) {
+		// ACT
+		_, err := NewAccountStore().MergeReplica(nil)
+
+		// ASSERT
+		assert.EqualError(t, err, "store is not running in multi-region mode", "unexpected error message")
+	})
+}
@@ -0,0 +1,368 @@
+package bankingsystem
+
+import (
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SegmentArchive stores archived events on disk in compressed, time-partitioned segments laid
+// out column by column. A small per-segment index (time range, amount range, accounts touched)
+// is kept in memory so queries only decompress segments that can contain matches.
+type SegmentArchive struct {
+	mu              sync.RWMutex
+	dir             string
+	partitionWidth  int
+	segments        map[int]*SegmentIndex
+	segmentsDecoded int
+}
+
+// SegmentIndex summarizes the contents of one segment.
+type SegmentIndex struct {
+	PartitionStart int
+	Count          int
+	MinTimestamp   int
+	MaxTimestamp   int
+	MinAmount      float64
+	MaxAmount      float64
+	Accounts       map[string]bool
+}
+
+// SegmentQuery filters archived events. A zero To or MaxAmount means no upper bound and an
+// empty AccountID matches every account.
+type SegmentQuery struct {
+	From      int
+	To        int
+	AccountID string
+	MinAmount float64
+	MaxAmount float64
+}
+
+// segmentColumns is the on-disk layout of a segment: one slice per event field.
+type segmentColumns struct {
+	Seq            []int
+	Timestamp      []int
+	Type           []EventType
+	AccountID      []string
+	CounterpartyID []string
+	TenantID       []string
+	Amount         []float64
+	// Currency is missing from segments written before redenomination existed.
+	Currency []string
+	// SourceIDs is missing from segments written before bulk merges existed.
+	SourceIDs [][]string
+	// State, Reason and Actor are missing from segments written before account lifecycles.
+	State  []AccountState
+	Reason []ReasonCode
+	Actor  []string
+	// ValueTimestamp is missing from segments written before cut-offs existed.
+	ValueTimestamp []int
+	// CreditedAmount is missing from segments written before quoted transfers existed.
+	CreditedAmount []float64
+}
+
+// NewSegmentArchive opens, or creates, a segment archive in dir that partitions events into
+// segments covering partitionDays days each.
+func NewSegmentArchive(dir string, partitionDays int) (*SegmentArchive, error) {
+	if partitionDays <= 0 {
+		return nil, errors.New("partition days must be positive")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	archive := &SegmentArchive{
+		dir:            dir,
+		partitionWidth: partitionDays * secondsPerDay,
+		segments:       make(map[int]*SegmentIndex),
+	}
+
+	indexFiles, err := filepath.Glob(filepath.Join(dir, "segment-*.idx.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range indexFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var index SegmentIndex
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, fmt.Errorf("reading segment index %s: %w", path, err)
+		}
+		archive.segments[index.PartitionStart] = &index
+	}
+	return archive, nil
+}
+
+func (a *SegmentArchive) Append(events []Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	partitions := make(map[int][]Event)
+	for _, event := range events {
+		start := a.partitionStart(event.Timestamp)
+		partitions[start] = append(partitions[start], event)
+	}
+
+	for start, partitionEvents := range partitions {
+		var existing []Event
+		if _, exists := a.segments[start]; exists {
+			decoded, err := a.readSegment(start)
+			if err != nil {
+				return err
+			}
+			existing = decoded
+		}
+		if err := a.writeSegment(start, append(existing, partitionEvents...)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *SegmentArchive) Range(from, to int) ([]Event, error) {
+	return a.query(SegmentQuery{From: from, To: to, MinAmount: math.Inf(-1), MaxAmount: math.Inf(1)})
+}
+
+// Query returns archived events matching q in commit order.
+func (a *SegmentArchive) Query(q SegmentQuery) ([]Event, error) {
+	if q.To == 0 {
+		q.To = math.MaxInt
+	}
+	if q.MaxAmount == 0 {
+		q.MaxAmount = math.Inf(1)
+	}
+	return a.query(q)
+}
+
+func (a *SegmentArchive) query(q SegmentQuery) ([]Event, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var events []Event
+	for start, index := range a.segments {
+		if !index.mayContain(q) {
+			continue
+		}
+		decoded, err := a.readSegment(start)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range decoded {
+			if q.matches(event) {
+				events = append(events, event)
+			}
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Seq < events[j].Seq
+	})
+	return events, nil
+}
+
+// Segments returns the index of every segment ordered by partition start.
+func (a *SegmentArchive) Segments() []SegmentIndex {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	indexes := make([]SegmentIndex, 0, len(a.segments))
+	for _, index := range a.segments {
+		indexes = append(indexes, *index)
+	}
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i].PartitionStart < indexes[j].PartitionStart
+	})
+	return indexes
+}
+
+func (a *SegmentArchive) partitionStart(timestamp int) int {
+	start := timestamp - timestamp%a.partitionWidth
+	if timestamp < 0 && timestamp%a.partitionWidth != 0 {
+		start -= a.partitionWidth
+	}
+	return start
+}
+
+func (a *SegmentArchive) segmentPath(start int) string {
+	return filepath.Join(a.dir, fmt.Sprintf("segment-%d.gz", start))
+}
+
+func (a *SegmentArchive) indexPath(start int) string {
+	return filepath.Join(a.dir, fmt.Sprintf("segment-%d.idx.json", start))
+}
+
+func (a *SegmentArchive) readSegment(start int) ([]Event, error) {
+	file, err := os.Open(a.segmentPath(start))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var columns segmentColumns
+	if err := gob.NewDecoder(reader).Decode(&columns); err != nil {
+		return nil, fmt.Errorf("decoding segment %d: %w", start, err)
+	}
+	a.segmentsDecoded++
+
+	events := make([]Event, len(columns.Seq))
+	for i := range events {
+		events[i] = Event{
+			Seq:            columns.Seq[i],
+			Timestamp:      columns.Timestamp[i],
+			Type:           columns.Type[i],
+			AccountID:      columns.AccountID[i],
+			CounterpartyID: columns.CounterpartyID[i],
+			TenantID:       columns.TenantID[i],
+			Amount:         columns.Amount[i],
+		}
+		if i < len(columns.Currency) {
+			events[i].Currency = columns.Currency[i]
+		}
+		if i < len(columns.SourceIDs) && len(columns.SourceIDs[i]) > 0 {
+			events[i].SourceIDs = columns.SourceIDs[i]
+		}
+		if i < len(columns.State) {
+			events[i].State = columns.State[i]
+			events[i].Reason = columns.Reason[i]
+			events[i].Actor = columns.Actor[i]
+		}
+		if i < len(columns.ValueTimestamp) {
+			events[i].ValueTimestamp = columns.ValueTimestamp[i]
+		}
+		if i < len(columns.CreditedAmount) {
+			events[i].CreditedAmount = columns.CreditedAmount[i]
+		}
+	}
+	return events, nil
+}
+
+// writeSegment replaces the segment at start with events and refreshes its index.
+func (a *SegmentArchive) writeSegment(start int, events []Event) error {
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Seq < events[j].Seq
+	})
+
+	var columns segmentColumns
+	index := &SegmentIndex{
+		PartitionStart: start,
+		Count:          len(events),
+		MinTimestamp:   math.MaxInt,
+		MaxTimestamp:   math.MinInt,
+		MinAmount:      math.Inf(1),
+		MaxAmount:      math.Inf(-1),
+		Accounts:       make(map[string]bool),
+	}
+	for _, event := range events {
+		columns.Seq = append(columns.Seq, event.Seq)
+		columns.Timestamp = append(columns.Timestamp, event.Timestamp)
+		columns.Type = append(columns.Type, event.Type)
+		columns.AccountID = append(columns.AccountID, event.AccountID)
+		columns.CounterpartyID = append(columns.CounterpartyID, event.CounterpartyID)
+		columns.TenantID = append(columns.TenantID, event.TenantID)
+		columns.Amount = append(columns.Amount, event.Amount)
+		columns.Currency = append(columns.Currency, event.Currency)
+		columns.SourceIDs = append(columns.SourceIDs, event.SourceIDs)
+		columns.State = append(columns.State, event.State)
+		columns.Reason = append(columns.Reason, event.Reason)
+		columns.Actor = append(columns.Actor, event.Actor)
+		columns.ValueTimestamp = append(columns.ValueTimestamp, event.ValueTimestamp)
+		columns.CreditedAmount = append(columns.CreditedAmount, event.CreditedAmount)
+
+		index.MinTimestamp = min(index.MinTimestamp, event.Timestamp)
+		index.MaxTimestamp = max(index.MaxTimestamp, event.Timestamp)
+		index.MinAmount = math.Min(index.MinAmount, event.Amount)
+		index.MaxAmount = math.Max(index.MaxAmount, event.Amount)
+		index.Accounts[event.AccountID] = true
+		if event.CounterpartyID != "" {
+			index.Accounts[event.CounterpartyID] = true
+		}
+		for _, sourceID := range event.SourceIDs {
+			index.Accounts[sourceID] = true
+		}
+	}
+
+	if err := writeFileAtomically(a.segmentPath(start), func(file *os.File) error {
+		writer := gzip.NewWriter(file)
+		if err := gob.NewEncoder(writer).Encode(columns); err != nil {
+			return err
+		}
+		return writer.Close()
+	}); err != nil {
+		return err
+	}
+
+	indexData, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomically(a.indexPath(start), func(file *os.File) error {
+		_, err := file.Write(indexData)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	a.segments[start] = index
+	return nil
+}
+
+func (index *SegmentIndex) mayContain(q SegmentQuery) bool {
+	if index.MaxTimestamp < q.From || index.MinTimestamp > q.To {
+		return false
+	}
+	if index.MaxAmount < q.MinAmount || index.MinAmount > q.MaxAmount {
+		return false
+	}
+	if q.AccountID != "" && !index.Accounts[q.AccountID] {
+		return false
+	}
+	return true
+}
+
+func (q SegmentQuery) matches(event Event) bool {
+	if event.Timestamp < q.From || event.Timestamp > q.To {
+		return false
+	}
+	if event.Amount < q.MinAmount || event.Amount > q.MaxAmount {
+		return false
+	}
+	if q.AccountID != "" && !event.involves(q.AccountID) {
+		return false
+	}
+	return true
+}
+
+// writeFileAtomically writes path through a temporary file in the same directory.
+func writeFileAtomically(path string, write func(file *os.File) error) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))+"-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := file.Name()
+
+	if err := write(file); err != nil {
+		file.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
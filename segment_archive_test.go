@@ -0,0 +1,93 @@
+package bankingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentArchive(t *testing.T) {
+	// ARRANGE
+	dir := t.TempDir()
+	archive, err := NewSegmentArchive(dir, 1)
+	assert.NoError(t, err, "unexpected error opening archive")
+
+	day := secondsPerDay
+	events := []Event{
+		{Seq: 1, Timestamp: 0, Type: EventAccountCreated, AccountID: "a", Amount: 1000},
+		{Seq: 2, Timestamp: 10, Type: EventAccountCreated, AccountID: "b", Amount: 50},
+		{Seq: 3, Timestamp: day + 5, Type: EventTransfer, AccountID: "a", CounterpartyID: "b", Amount: 20},
+		{Seq: 4, Timestamp: 2*day + 5, Type: EventTransfer, AccountID: "b", CounterpartyID: "c", Amount: 5},
+	}
+
+	// ACT
+	err = archive.Append(events)
+
+	// ASSERT
+	assert.NoError(t, err, "unexpected error appending events")
+	assert.Len(t, archive.Segments(), 3, "expected one segment per day")
+
+	t.Run("Range Reads All Segments", func(t *testing.T) {
+		// ACT
+		ranged, err := archive.Range(historyStart, 3*day)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error reading range")
+		assert.Equal(t, events, ranged, "expected events in commit order")
+	})
+
+	t.Run("Query Skips Segments Using Index", func(t *testing.T) {
+		// ARRANGE
+		decodedBefore := archive.segmentsDecoded
+
+		// ACT
+		matched, err := archive.Query(SegmentQuery{AccountID: "c"})
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error querying")
+		assert.Equal(t, []Event{events[3]}, matched, "expected only the transfer to c")
+		assert.Equal(t, 1, archive.segmentsDecoded-decodedBefore, "expected only one segment to be decoded")
+	})
+
+	t.Run("Query By Amount", func(t *testing.T) {
+		// ACT
+		matched, err := archive.Query(SegmentQuery{MinAmount: 10, MaxAmount: 100})
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error querying")
+		assert.Equal(t, []Event{events[1], events[2]}, matched, "expected events within the amount range")
+	})
+
+	t.Run("Reopen Loads Indexes", func(t *testing.T) {
+		// ACT
+		reopened, err := NewSegmentArchive(dir, 1)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error reopening archive")
+		assert.Equal(t, archive.Segments(), reopened.Segments(), "segment indexes mismatch after reopen")
+		ranged, err := reopened.Range(historyStart, 3*day)
+		assert.NoError(t, err, "unexpected error reading range")
+		assert.Equal(t, events, ranged, "events mismatch after reopen")
+	})
+
+	t.Run("Works As Store Archive", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		segments, err := NewSegmentArchive(t.TempDir(), 7)
+		assert.NoError(t, err, "unexpected error opening archive")
+		assert.NoError(t, store.EnableArchival(segments, 1), "unexpected error enabling archival")
+		accountID := randomAccountID()
+		store.CreateAccount(day, accountID, 100)
+
+		// ACT
+		moved, err := store.ArchiveTransactions(10 * day)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during archival")
+		assert.Equal(t, 1, moved, "expected the creation event to be archived")
+		view, err := store.StateAt(10 * day)
+		assert.NoError(t, err, "unexpected error materializing view")
+		account, _ := view.Account(accountID)
+		assert.Equal(t, float64(100), account.Balance, "balance mismatch after archival")
+	})
+}
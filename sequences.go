@@ -0,0 +1,169 @@
+package bankingsystem
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SequenceProvider hands out numbers from named sequences, starting at 1. A provider must
+// never return the same number twice for a name, including across restarts and across stores
+// that share it, so that IDs built from them do not collide.
+type SequenceProvider interface {
+	Next(ctx context.Context, name string) (int, error)
+}
+
+// Sequence names used by the store.
+const (
+	SequencePayment = "payment"
+	SequenceHold    = "hold"
+	SequenceCase    = "case"
+	SequenceQuote   = "quote"
+)
+
+// WithSequences sets where the store draws payment, hold, case and quote IDs from. The default
+// keeps counters in memory, so IDs restart from 1 with every new store.
+func WithSequences(provider SequenceProvider) Option {
+	return func(s *AccountStore) {
+		s.sequences = provider
+	}
+}
+
+// nextSequence draws the next number of a sequence within the operation timeout.
+func (s *AccountStore) nextSequence(name string) (int, error) {
+	ctx, cancel := s.operationContext()
+	defer cancel()
+	seq, err := s.sequences.Next(ctx, name)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return 0, fmt.Errorf("%w after %s: %w", ErrOperationTimeout, s.operationTimeout, err)
+	}
+	return seq, err
+}
+
+// MemorySequences keeps sequences in memory. They are collision-free only within one process.
+type MemorySequences struct {
+	mu     sync.Mutex
+	values map[string]int
+}
+
+func NewMemorySequences() *MemorySequences {
+	return &MemorySequences{values: make(map[string]int)}
+}
+
+func (m *MemorySequences) Next(ctx context.Context, name string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.values[name]++
+	return m.values[name], nil
+}
+
+// fileLockRetry is how long FileSequences waits before trying a lock held by someone else again.
+const fileLockRetry = 5 * time.Millisecond
+
+// FileSequences keeps each sequence in a file in Dir. Processes sharing Dir take turns through
+// a lock on a file next to each sequence, so Dir can be shared by every instance on a host. On
+// Unix the lock is an flock, which the operating system releases if its owner crashes; on
+// other systems it is a lock file created exclusively, which must be removed by hand if its
+// owner crashes while holding it.
+type FileSequences struct {
+	Dir string
+	// Clock times the waits for a held lock; pass the store's clock. Nil means the system
+	// clock.
+	Clock Clock
+}
+
+func (f FileSequences) Next(ctx context.Context, name string) (int, error) {
+	path := filepath.Join(f.Dir, name+".seq")
+	clock := f.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+	unlock, err := lockFile(ctx, clock, path+".lock")
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	value := 0
+	contents, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		value, err = strconv.Atoi(strings.TrimSpace(string(contents)))
+		if err != nil {
+			return 0, fmt.Errorf("reading sequence %s: %w", name, err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return 0, err
+	}
+
+	value++
+	if err := writeFileAtomically(path, func(file *os.File) error {
+		_, err := file.WriteString(strconv.Itoa(value) + "\n")
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// SQLSequences keeps sequences in a database table with a name and a value column, which
+// CreateTable creates. Each Next runs in its own transaction, so instances sharing the
+// database never receive the same number.
+type SQLSequences struct {
+	DB    *sql.DB
+	Table string
+	// Numbered uses $1-style placeholders, as PostgreSQL expects, instead of ?.
+	Numbered bool
+}
+
+// CreateTable creates the sequence table if it does not exist.
+func (q SQLSequences) CreateTable(ctx context.Context) error {
+	_, err := q.DB.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+q.Table+" (name VARCHAR(64) PRIMARY KEY, value BIGINT NOT NULL)")
+	return err
+}
+
+func (q SQLSequences) Next(ctx context.Context, name string) (int, error) {
+	tx, err := q.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, "UPDATE "+q.Table+" SET value = value + 1 WHERE name = "+q.placeholder(), name)
+	if err != nil {
+		return 0, err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if updated == 0 {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO "+q.Table+" (name, value) VALUES ("+q.placeholder()+", 1)", name); err != nil {
+			return 0, err
+		}
+	}
+
+	var value int
+	if err := tx.QueryRowContext(ctx, "SELECT value FROM "+q.Table+" WHERE name = "+q.placeholder(), name).Scan(&value); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+func (q SQLSequences) placeholder() string {
+	if q.Numbered {
+		return "$1"
+	}
+	return "?"
+}
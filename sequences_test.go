@@ -0,0 +1,218 @@
+package bankingsystem
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSequenceProviders(t *testing.T) {
+	db := sql.OpenDB(fakeSequenceConnector{table: make(map[string]int64)})
+	defer db.Close()
+
+	providers := map[string]func(t *testing.T) SequenceProvider{
+		"Memory": func(t *testing.T) SequenceProvider { return NewMemorySequences() },
+		"File":   func(t *testing.T) SequenceProvider { return FileSequences{Dir: t.TempDir()} },
+		"SQL": func(t *testing.T) SequenceProvider {
+			sequences := SQLSequences{DB: db, Table: "sequences_" + strings.ReplaceAll(t.Name(), "/", "_")}
+			assert.NoError(t, sequences.CreateTable(context.Background()), "unexpected error creating table")
+			return sequences
+		},
+	}
+	for name, newProvider := range providers {
+		t.Run(name, func(t *testing.T) {
+			// ARRANGE
+			provider := newProvider(t)
+			var mu sync.Mutex
+			seen := make(map[int]bool)
+			var wg sync.WaitGroup
+
+			// ACT
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					value, err := provider.Next(context.Background(), "payment")
+					assert.NoError(t, err, "unexpected error drawing a number")
+					mu.Lock()
+					seen[value] = true
+					mu.Unlock()
+				}()
+			}
+			wg.Wait()
+			other, _ := provider.Next(context.Background(), "hold")
+
+			// ASSERT
+			assert.Len(t, seen, 20, "every number should be unique")
+			for i := 1; i <= 20; i++ {
+				assert.True(t, seen[i], "numbers should run from 1 without gaps")
+			}
+			assert.Equal(t, 1, other, "sequences should be independent")
+		})
+	}
+}
+
+func TestWithSequences(t *testing.T) {
+	t.Run("Stores Sharing A Provider Never Collide", func(t *testing.T) {
+		// ARRANGE
+		sequences := FileSequences{Dir: t.TempDir()}
+		first := NewAccountStore(WithSequences(sequences))
+		first.CreateAccount(1, "a", 1000)
+		first.SchedulePayment(1, "a", 10, 3600)
+
+		// ACT
+		restarted := NewAccountStore(WithSequences(sequences))
+		restarted.CreateAccount(1, "a", 1000)
+		paymentID, err := restarted.SchedulePayment(1, "a", 10, 3600)
+		holdID, holdErr := restarted.PlaceHold(1, "a", 10)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error scheduling payment")
+		assert.Equal(t, "payment-a-2", *paymentID, "payment IDs should continue after a restart")
+		assert.NoError(t, holdErr, "unexpected error placing hold")
+		assert.Equal(t, "hold-1", holdID, "hold IDs have their own sequence")
+	})
+
+	t.Run("Provider Failure Fails The Operation", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithSequences(failingSequences{}))
+		store.CreateAccount(1, "a", 1000)
+
+		// ACT
+		_, err := store.SchedulePayment(1, "a", 10, 60)
+
+		// ASSERT
+		assert.EqualError(t, err, "sequences unavailable", "unexpected error message")
+		assert.Equal(t, 0, store.pendingPayments, "no payment should be scheduled")
+	})
+}
+
+type failingSequences struct{}
+
+func (failingSequences) Next(ctx context.Context, name string) (int, error) {
+	return 0, errors.New("sequences unavailable")
+}
+
+// fakeSequenceConnector is a database/sql driver that understands just the statements
+// SQLSequences runs, over one in-memory table keyed by table and sequence name.
+type fakeSequenceConnector struct {
+	table map[string]int64
+}
+
+func (c fakeSequenceConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeSequenceConn{connector: c}, nil
+}
+
+func (c fakeSequenceConnector) Driver() driver.Driver {
+	return nil
+}
+
+var fakeSequenceLock sync.Mutex
+
+type fakeSequenceConn struct {
+	connector fakeSequenceConnector
+	inTx      bool
+}
+
+func (c *fakeSequenceConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeSequenceStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeSequenceConn) Close() error {
+	return nil
+}
+
+// Begin serializes transactions, standing in for the database's row locks.
+func (c *fakeSequenceConn) Begin() (driver.Tx, error) {
+	fakeSequenceLock.Lock()
+	c.inTx = true
+	return c, nil
+}
+
+func (c *fakeSequenceConn) Commit() error {
+	c.inTx = false
+	fakeSequenceLock.Unlock()
+	return nil
+}
+
+func (c *fakeSequenceConn) Rollback() error {
+	if c.inTx {
+		c.inTx = false
+		fakeSequenceLock.Unlock()
+	}
+	return nil
+}
+
+type fakeSequenceStmt struct {
+	conn  *fakeSequenceConn
+	query string
+}
+
+func (s *fakeSequenceStmt) Close() error {
+	return nil
+}
+
+func (s *fakeSequenceStmt) NumInput() int {
+	return strings.Count(s.query, "?")
+}
+
+func (s *fakeSequenceStmt) key(args []driver.Value) string {
+	fields := strings.Fields(s.query)
+	for i, field := range fields {
+		if field == "UPDATE" || field == "INTO" || field == "FROM" {
+			return fields[i+1] + "/" + args[0].(string)
+		}
+	}
+	return ""
+}
+
+func (s *fakeSequenceStmt) Exec(args []driver.Value) (driver.Result, error) {
+	table := s.conn.connector.table
+	switch {
+	case strings.HasPrefix(s.query, "CREATE"):
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "UPDATE"):
+		if _, exists := table[s.key(args)]; !exists {
+			return driver.RowsAffected(0), nil
+		}
+		table[s.key(args)]++
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "INSERT"):
+		table[s.key(args)] = 1
+		return driver.RowsAffected(1), nil
+	}
+	return nil, errors.New("unsupported statement: " + s.query)
+}
+
+func (s *fakeSequenceStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return &fakeSequenceRows{value: s.conn.connector.table[s.key(args)]}, nil
+}
+
+type fakeSequenceRows struct {
+	value int64
+	read  bool
+}
+
+func (r *fakeSequenceRows) Columns() []string {
+	return []string{"value"}
+}
+
+func (r *fakeSequenceRows) Close() error {
+	return nil
+}
+
+func (r *fakeSequenceRows) Next(dest []driver.Value) error {
+	if r.read {
+		return io.EOF
+	}
+	r.read = true
+	dest[0] = r.value
+	return nil
+}
@@ -0,0 +1,175 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// WeightedDest is one recipient of a SplitTransfer. Exactly one of Percent and Amount is set.
+type WeightedDest struct {
+	AccountID string
+	// Percent is the recipient's share, in percent, of what is left of the total once the fixed
+	// amounts are paid. The percentages of a split add up to 100.
+	Percent float64
+	// Amount is a fixed amount paid before any percentage is.
+	Amount float64
+}
+
+// SplitTransfer moves totalAmount out of fromID to every destination, all or nothing, and
+// returns the amount each destination received, in order. Fixed amounts are paid first and
+// the rest is divided by percentage. Amounts are in hundredths: shares are rounded down to a
+// hundredth and the hundredths left over go one each to the destinations that lost the most
+// to rounding, the earliest first on ties, so the same split always divides the same way and
+// the shares add up to exactly totalAmount.
+//
+// The split is checked against limits, quotas and the available balance as one transfer of
+// totalAmount, and each destination against the transfer rules as a transfer of its share. It
+// is recorded as one transfer event per destination.
+func (s *AccountStore) SplitTransfer(timestamp int, fromID string, totalAmount float64, destinations []WeightedDest) ([]float64, error) {
+	shares, err := splitAmounts(totalAmount, destinations)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSplitTransfer, Timestamp: timestamp, AccountID: fromID, Amount: totalAmount, Destinations: destinations})
+
+	var fromAccount *Account
+	toAccounts := make([]*Account, len(destinations))
+	var flags [][]string
+	for i, destination := range destinations {
+		fromAccount, toAccounts[i], err = s.validateTransfer(timestamp, fromID, destination.AccountID, totalAmount)
+		if err != nil {
+			return nil, err
+		}
+		legFlags, err := s.checkTransferRules(timestamp, fromAccount, destination.AccountID, shares[i])
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, legFlags)
+	}
+
+	projected := map[string]AccountSnapshot{fromID: fromAccount.snapshot()}
+	from := projected[fromID]
+	from.Balance -= totalAmount
+	from.TotalTransferred += totalAmount
+	from.UpdatedAt = timestamp
+	projected[fromID] = from
+	for i, toAccount := range toAccounts {
+		to, seen := projected[toAccount.accountID]
+		if !seen {
+			to = toAccount.snapshot()
+		}
+		to.Balance += shares[i]
+		to.UpdatedAt = timestamp
+		projected[toAccount.accountID] = to
+	}
+
+	var batch StorageBatch
+	for _, snapshot := range projected {
+		batch.Put = append(batch.Put, snapshot)
+	}
+	sort.Slice(batch.Put, func(i, j int) bool {
+		return batch.Put[i].AccountID < batch.Put[j].AccountID
+	})
+	if err := s.persist(batch); err != nil {
+		return nil, err
+	}
+	fromAccount.restore(projected[fromID])
+	for _, toAccount := range toAccounts {
+		toAccount.restore(projected[toAccount.accountID])
+	}
+	s.addTransferVolume(fromAccount.tenantID, timestamp, totalAmount)
+
+	valueDate := s.ValueDate(timestamp)
+	for i, destination := range destinations {
+		event := Event{Timestamp: timestamp, Type: EventTransfer, AccountID: fromID, CounterpartyID: destination.AccountID, Amount: shares[i]}
+		if valueDate != timestamp {
+			event.ValueTimestamp = valueDate
+		}
+		s.record(event)
+		if len(flags[i]) > 0 {
+			s.flagTransfer(event, flags[i])
+		}
+	}
+	return shares, nil
+}
+
+// splitAmounts divides total between destinations as SplitTransfer describes.
+func splitAmounts(total float64, destinations []WeightedDest) ([]float64, error) {
+	if len(destinations) == 0 {
+		return nil, errors.New("split needs at least one destination")
+	}
+	totalCents, ok := toCents(total)
+	if !ok || totalCents <= 0 {
+		return nil, fmt.Errorf("split amount %v must be positive and in hundredths", total)
+	}
+
+	cents := make([]int64, len(destinations))
+	remaining := totalCents
+	percent := 0.0
+	for i, destination := range destinations {
+		switch {
+		case (destination.Percent > 0) == (destination.Amount > 0):
+			return nil, fmt.Errorf("destination %s needs exactly one of a positive percent or amount", destination.AccountID)
+		case destination.Amount > 0:
+			amount, ok := toCents(destination.Amount)
+			if !ok {
+				return nil, fmt.Errorf("amount %v for %s must be in hundredths", destination.Amount, destination.AccountID)
+			}
+			cents[i] = amount
+			remaining -= amount
+		default:
+			percent += destination.Percent
+		}
+	}
+	switch {
+	case remaining < 0:
+		return nil, fmt.Errorf("fixed amounts exceed the split amount %v", total)
+	case percent == 0 && remaining != 0:
+		return nil, fmt.Errorf("fixed amounts must add up to the split amount %v", total)
+	case percent != 0 && math.Abs(percent-100) > 1e-9:
+		return nil, fmt.Errorf("percentages add up to %v, not 100", percent)
+	}
+
+	// Largest remainder: round every share down, then hand out the leftover hundredths.
+	type fraction struct {
+		index int
+		lost  float64
+	}
+	var fractions []fraction
+	allocated := int64(0)
+	for i, destination := range destinations {
+		if destination.Percent == 0 {
+			continue
+		}
+		exact := float64(remaining) * destination.Percent / 100
+		cents[i] = int64(math.Floor(exact + 1e-9))
+		allocated += cents[i]
+		fractions = append(fractions, fraction{index: i, lost: exact - float64(cents[i])})
+	}
+	sort.SliceStable(fractions, func(i, j int) bool {
+		return fractions[i].lost > fractions[j].lost+1e-9
+	})
+	for i := int64(0); i < remaining-allocated; i++ {
+		cents[fractions[int(i)%len(fractions)].index]++
+	}
+
+	shares := make([]float64, len(destinations))
+	for i, amount := range cents {
+		if amount == 0 {
+			return nil, fmt.Errorf("share of %s rounds to zero", destinations[i].AccountID)
+		}
+		shares[i] = float64(amount) / 100
+	}
+	return shares, nil
+}
+
+// toCents converts amount to hundredths, reporting whether it had no finer part.
+func toCents(amount float64) (int64, bool) {
+	cents := math.Round(amount * 100)
+	return int64(cents), math.Abs(amount*100-cents) < 1e-6
+}
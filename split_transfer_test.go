@@ -0,0 +1,108 @@
+package bankingsystem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTransfer(t *testing.T) {
+	newStore := func() *AccountStore {
+		store := NewAccountStore()
+		store.CreateAccount(1, "market", 1000)
+		store.CreateAccount(1, "a", 0)
+		store.CreateAccount(1, "b", 0)
+		store.CreateAccount(1, "c", 0)
+		return store
+	}
+
+	t.Run("Pays Fixed Amounts Then Percentages", func(t *testing.T) {
+		// ARRANGE
+		store := newStore()
+
+		// ACT
+		shares, err := store.SplitTransfer(2, "market", 100, []WeightedDest{
+			{AccountID: "a", Percent: 70},
+			{AccountID: "b", Amount: 10},
+			{AccountID: "c", Percent: 30},
+		})
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during split")
+		assert.Equal(t, []float64{63, 10, 27}, shares, "shares mismatch")
+		market, _ := store.GetAccount("market")
+		a, _ := store.GetAccount("a")
+		assert.Equal(t, float64(900), market.Balance, "source should be debited the total")
+		assert.Equal(t, float64(100), market.TotalTransferred, "total transferred mismatch")
+		assert.Equal(t, float64(63), a.Balance, "destination should be credited its share")
+		events, _ := store.Transactions(2, 2)
+		assert.Len(t, events, 3, "expected one transfer event per destination")
+	})
+
+	t.Run("Allocates Remainders Deterministically", func(t *testing.T) {
+		// ARRANGE
+		store := newStore()
+		thirds := []WeightedDest{
+			{AccountID: "a", Percent: 100.0 / 3},
+			{AccountID: "b", Percent: 100.0 / 3},
+			{AccountID: "c", Percent: 100.0 / 3},
+		}
+
+		// ACT
+		first, firstErr := store.SplitTransfer(2, "market", 100, thirds)
+		second, secondErr := store.SplitTransfer(3, "market", 100, thirds)
+
+		// ASSERT
+		assert.NoError(t, firstErr, "unexpected error during split")
+		assert.NoError(t, secondErr, "unexpected error during split")
+		assert.Equal(t, []float64{33.34, 33.33, 33.33}, first, "the leftover hundredth should go to the first destination")
+		assert.Equal(t, first, second, "splits should be deterministic")
+	})
+
+	t.Run("Gives Leftovers To The Largest Losses", func(t *testing.T) {
+		// ACT
+		shares, err := splitAmounts(1, []WeightedDest{
+			{AccountID: "a", Percent: 50.4},
+			{AccountID: "b", Percent: 49.6},
+		})
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error during split")
+		assert.Equal(t, []float64{0.5, 0.5}, shares, "the leftover hundredth should go to the larger loss")
+	})
+
+	t.Run("Is All Or Nothing", func(t *testing.T) {
+		// ARRANGE
+		store := newStore()
+		store.TransitionAccount(2, "c", StateClosed, ReasonCustomerRequest, "agent-1")
+
+		// ACT
+		_, closed := store.SplitTransfer(3, "market", 100, []WeightedDest{{AccountID: "a", Percent: 50}, {AccountID: "c", Percent: 50}})
+		_, overdrawn := store.SplitTransfer(3, "market", 2000, []WeightedDest{{AccountID: "a", Percent: 50}, {AccountID: "b", Percent: 50}})
+
+		// ASSERT
+		assert.ErrorIs(t, closed, ErrOperationNotPermitted, "closed destinations should fail the split")
+		assert.ErrorIs(t, overdrawn, ErrInsufficientBalance, "the total should be checked against the balance")
+		market, _ := store.GetAccount("market")
+		a, _ := store.GetAccount("a")
+		assert.Equal(t, float64(1000), market.Balance, "source should be untouched")
+		assert.Equal(t, float64(0), a.Balance, "destinations should be untouched")
+	})
+
+	t.Run("Rejects Invalid Splits", func(t *testing.T) {
+		for _, destinations := range [][]WeightedDest{
+			nil,
+			{{AccountID: "a", Percent: 60}, {AccountID: "b", Percent: 30}},
+			{{AccountID: "a", Amount: 60}},
+			{{AccountID: "a", Amount: 120}, {AccountID: "b", Percent: 100}},
+			{{AccountID: "a", Amount: 50, Percent: 50}},
+			{{AccountID: "a", Amount: 99.999}, {AccountID: "b", Percent: 100}},
+		} {
+			// ACT
+			_, err := splitAmounts(100, destinations)
+
+			// ASSERT
+			assert.Error(t, err, "split %v should be rejected", destinations)
+		}
+	})
+}
@@ -0,0 +1,427 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Statement is an account's activity over the period [PeriodStart, PeriodEnd). Balances are
+// replayed from the event history, so accounts restored from Storage without history start
+// from zero.
+type Statement struct {
+	AccountID      string
+	PeriodStart    int
+	PeriodEnd      int
+	OpeningBalance float64
+	ClosingBalance float64
+	Events         []Event
+}
+
+// StatementDeliverer hands a statement to the account holder.
+type StatementDeliverer interface {
+	Deliver(ctx context.Context, statement Statement) error
+}
+
+// StatementStatus is where a statement's delivery stands.
+type StatementStatus string
+
+const (
+	StatementDelivered StatementStatus = "delivered"
+	StatementFailed    StatementStatus = "failed"
+)
+
+// StatementDelivery tracks the delivery of one account's statement for one period.
+type StatementDelivery struct {
+	AccountID   string
+	PeriodStart int
+	PeriodEnd   int
+	Status      StatementStatus
+	Attempts    int
+	Error       string
+}
+
+type statementKey struct {
+	accountID   string
+	periodStart int
+}
+
+// StatementSchedule configures ScheduleStatements.
+type StatementSchedule struct {
+	// Cycle is the statement period. Cycles are aligned to the Unix epoch, so with a 24-hour
+	// cycle every statement covers one UTC day.
+	Cycle time.Duration
+	// Tag limits statements to accounts carrying it. An empty Tag covers every account.
+	Tag string
+}
+
+// SetAccountTags replaces the account's tags.
+func (s *AccountStore) SetAccountTags(accountID string, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSetAccountTags, AccountID: accountID, IDs: tags})
+
+	if _, exists := s.accounts.lookup(accountID); !exists {
+		return ErrAccountNotFound
+	}
+	if len(tags) == 0 {
+		delete(s.accountTags, accountID)
+		return nil
+	}
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[tag] = true
+	}
+	s.accountTags[accountID] = set
+	return nil
+}
+
+// GenerateStatement builds the account's statement for [periodStart, periodEnd).
+func (s *AccountStore) GenerateStatement(accountID string, periodStart, periodEnd int) (*Statement, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if _, exists := s.accounts.lookup(accountID); !exists {
+		return nil, ErrAccountNotFound
+	}
+	statements, err := s.generateStatements([]string{accountID}, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	return &statements[0], nil
+}
+
+// generateStatements replays the history once for every account in accountIDs. Callers must
+// hold the lock.
+func (s *AccountStore) generateStatements(accountIDs []string, periodStart, periodEnd int) ([]Statement, error) {
+	events, err := s.history(historyStart, periodEnd-1)
+	if err != nil {
+		return nil, err
+	}
+
+	opening := make(map[string]AccountSnapshot)
+	closing := make(map[string]AccountSnapshot)
+	for _, event := range events {
+		if event.Timestamp < periodStart {
+			applyEvent(opening, event)
+		}
+		applyEvent(closing, event)
+	}
+
+	statements := make([]Statement, len(accountIDs))
+	for i, accountID := range accountIDs {
+		statements[i] = Statement{
+			AccountID:      accountID,
+			PeriodStart:    periodStart,
+			PeriodEnd:      periodEnd,
+			OpeningBalance: opening[accountID].Balance,
+			ClosingBalance: closing[accountID].Balance,
+		}
+	}
+	index := make(map[string]int, len(accountIDs))
+	for i, accountID := range accountIDs {
+		index[accountID] = i
+	}
+	for _, event := range events {
+		if event.Timestamp < periodStart {
+			continue
+		}
+		for accountID, i := range index {
+			if event.involves(accountID) {
+				statements[i].Events = append(statements[i].Events, event)
+			}
+		}
+	}
+	return statements, nil
+}
+
+// balanceAfter returns the balance of accountID after event, given its balance before. It
+// agrees with applyEvent, but needs no other account: merges into accountID add the amount the
+// event records as moved.
+func balanceAfter(accountID string, balance float64, event Event) float64 {
+	switch event.Type {
+	case EventAccountCreated:
+		return event.Amount
+	case EventTransfer:
+		if event.AccountID == accountID {
+			balance -= event.Amount
+		}
+		if event.CounterpartyID == accountID {
+			balance += event.credited()
+		}
+	case EventPaymentExecuted:
+		balance -= event.Amount
+	case EventAccountsMerged, EventAccountsMergedMany:
+		if event.CounterpartyID == accountID {
+			return balance + event.Amount
+		}
+		return 0
+	case EventOverdraftFee, EventTransferFee:
+		if event.AccountID == accountID {
+			balance -= event.Amount
+		}
+		if event.CounterpartyID == accountID {
+			balance += event.Amount
+		}
+	case EventAccountRedenominated:
+		balance *= event.Amount
+	}
+	return balance
+}
+
+// DeliverStatements generates the statements for [periodStart, periodEnd) of every account
+// carrying tag, or of every account if tag is empty, and hands each to deliverer. Statements
+// that failed to deliver in earlier calls are generated again and retried first. It returns
+// the first delivery error, after attempting every statement.
+func (s *AccountStore) DeliverStatements(ctx context.Context, periodStart, periodEnd int, tag string, deliverer StatementDeliverer) error {
+	s.mu.RLock()
+	var statements []Statement
+	retries := make(map[int][]string)
+	for key, delivery := range s.statements {
+		_, exists := s.accounts.lookup(key.accountID)
+		if delivery.Status == StatementFailed && exists && key.periodStart != periodStart {
+			retries[key.periodStart] = append(retries[key.periodStart], key.accountID)
+		}
+	}
+	starts := make([]int, 0, len(retries))
+	for start := range retries {
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+	for _, start := range starts {
+		accountIDs := retries[start]
+		sort.Strings(accountIDs)
+		retried, err := s.generateStatements(accountIDs, start, s.statements[statementKey{accountIDs[0], start}].PeriodEnd)
+		if err != nil {
+			s.mu.RUnlock()
+			return err
+		}
+		statements = append(statements, retried...)
+	}
+
+	var accountIDs []string
+	for accountID := range s.accounts.all() {
+		if tag == "" || s.accountTags[accountID][tag] {
+			accountIDs = append(accountIDs, accountID)
+		}
+	}
+	sort.Strings(accountIDs)
+	current, err := s.generateStatements(accountIDs, periodStart, periodEnd)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	statements = append(statements, current...)
+
+	var firstErr error
+	for _, statement := range statements {
+		err := deliverer.Deliver(ctx, statement)
+		if err != nil {
+			s.logger.Warn("delivering statement", "accountID", statement.AccountID, "periodStart", statement.PeriodStart, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		s.recordStatementDelivery(statement, err)
+	}
+	return firstErr
+}
+
+func (s *AccountStore) recordStatementDelivery(statement Statement, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := statementKey{accountID: statement.AccountID, periodStart: statement.PeriodStart}
+	delivery, exists := s.statements[key]
+	if !exists {
+		delivery = &StatementDelivery{AccountID: statement.AccountID, PeriodStart: statement.PeriodStart, PeriodEnd: statement.PeriodEnd}
+		s.statements[key] = delivery
+	}
+	delivery.Attempts++
+	delivery.Status = StatementDelivered
+	delivery.Error = ""
+	if err != nil {
+		delivery.Status = StatementFailed
+		delivery.Error = err.Error()
+	}
+}
+
+// StatementDeliveries returns the delivery status of every statement generated for the
+// account, oldest period first.
+func (s *AccountStore) StatementDeliveries(accountID string) []StatementDelivery {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var deliveries []StatementDelivery
+	for key, delivery := range s.statements {
+		if key.accountID == accountID {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].PeriodStart < deliveries[j].PeriodStart
+	})
+	return deliveries
+}
+
+// ScheduleStatements delivers statements at the close of every cycle, using the store's clock,
+// until stop is called. Each run covers the cycle that just closed, as DeliverStatements does.
+func (s *AccountStore) ScheduleStatements(schedule StatementSchedule, deliverer StatementDeliverer) (stop func(), err error) {
+	cycle := int(schedule.Cycle / time.Second)
+	if cycle < 1 {
+		return nil, errors.New("statement cycle must be at least one second")
+	}
+
+	job := &statementJob{store: s, cycle: cycle, tag: schedule.Tag, deliverer: deliverer}
+	job.arm()
+	return job.stop, nil
+}
+
+type statementJob struct {
+	store     *AccountStore
+	cycle     int
+	tag       string
+	deliverer StatementDeliverer
+
+	mu      sync.Mutex
+	timer   Timer
+	stopped bool
+}
+
+func (j *statementJob) arm() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.stopped {
+		return
+	}
+	now := j.store.clock.Now()
+	closeAt := int(now.Unix())/j.cycle*j.cycle + j.cycle
+	j.timer = j.store.clock.AfterFunc(time.Unix(int64(closeAt), 0).Sub(now), func() {
+		j.store.DeliverStatements(context.Background(), closeAt-j.cycle, closeAt, j.tag, j.deliverer)
+		j.arm()
+	})
+}
+
+func (j *statementJob) stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.stopped = true
+	if j.timer != nil {
+		j.timer.Stop()
+	}
+}
+
+// FileStatementDeliverer writes each statement as JSON into Dir.
+type FileStatementDeliverer struct {
+	Dir string
+}
+
+func (d FileStatementDeliverer) Deliver(ctx context.Context, statement Statement) error {
+	payload, err := json.MarshalIndent(statement, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("statement-%s-%d.json", url.PathEscape(statement.AccountID), statement.PeriodStart)
+	return writeFileAtomically(filepath.Join(d.Dir, name), func(file *os.File) error {
+		_, err := file.Write(payload)
+		return err
+	})
+}
+
+// WebhookStatementDeliverer posts each statement as JSON to a URL, signed the same way as event
+// webhooks.
+type WebhookStatementDeliverer struct {
+	client *http.Client
+	clock  Clock
+	url    string
+	secret string
+}
+
+// NewWebhookStatementDeliverer creates a deliverer that posts to url through client, signing
+// with secret at the time on clock, normally the store's. A nil client is http.DefaultClient
+// and a nil clock the system clock.
+func NewWebhookStatementDeliverer(client *http.Client, clock Clock, url, secret string) WebhookStatementDeliverer {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if clock == nil {
+		clock = systemClock{}
+	}
+	return WebhookStatementDeliverer{client: client, clock: clock, url: url, secret: secret}
+}
+
+func (d WebhookStatementDeliverer) Deliver(ctx context.Context, statement Statement) error {
+	if d.client == nil {
+		return errors.New("webhook statement deliverer was not created with NewWebhookStatementDeliverer")
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(payload, d.clock.Now(), d.secret))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("statement endpoint responded with %s", response.Status)
+	}
+	return nil
+}
+
+// EmailStatementDeliverer renders each statement as a plain-text email and hands it to Send.
+// It does not talk to a mail server itself.
+type EmailStatementDeliverer struct {
+	recipients map[string]string
+	send       func(ctx context.Context, to, subject, body string) error
+}
+
+// NewEmailStatementDeliverer creates a deliverer that emails each account's statement to the
+// address recipients maps its ID to, through send.
+func NewEmailStatementDeliverer(recipients map[string]string, send func(ctx context.Context, to, subject, body string) error) (EmailStatementDeliverer, error) {
+	if send == nil {
+		return EmailStatementDeliverer{}, errors.New("email statement deliverer needs a send function")
+	}
+	return EmailStatementDeliverer{recipients: recipients, send: send}, nil
+}
+
+func (d EmailStatementDeliverer) Deliver(ctx context.Context, statement Statement) error {
+	if d.send == nil {
+		return errors.New("email statement deliverer was not created with NewEmailStatementDeliverer")
+	}
+	to, exists := d.recipients[statement.AccountID]
+	if !exists {
+		return fmt.Errorf("no email address for account %s", statement.AccountID)
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Opening balance: %.2f\n", statement.OpeningBalance)
+	for _, event := range statement.Events {
+		fmt.Fprintf(&body, "%d %s %.2f\n", event.Timestamp, event.Type, event.Amount)
+	}
+	fmt.Fprintf(&body, "Closing balance: %.2f\n", statement.ClosingBalance)
+
+	subject := fmt.Sprintf("Statement for %s, %d to %d", statement.AccountID, statement.PeriodStart, statement.PeriodEnd)
+	return d.send(ctx, to, subject, body.String())
+}
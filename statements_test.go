@@ -0,0 +1,172 @@
+package bankingsystem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingDeliverer collects delivered statements, failing accounts listed in fail.
+type recordingDeliverer struct {
+	delivered []Statement
+	fail      map[string]bool
+}
+
+func (d *recordingDeliverer) Deliver(ctx context.Context, statement Statement) error {
+	if d.fail[statement.AccountID] {
+		return errors.New("mailbox full")
+	}
+	d.delivered = append(d.delivered, statement)
+	return nil
+}
+
+func TestGenerateStatement(t *testing.T) {
+	// ARRANGE
+	store := NewAccountStore()
+	store.CreateAccount(10, "a", 1000)
+	store.CreateAccount(10, "b", 0)
+	store.Transfer(20, "a", "b", 100)
+	store.Transfer(120, "a", "b", 50)
+	store.Transfer(220, "b", "a", 10)
+
+	// ACT
+	statement, err := store.GenerateStatement("a", 100, 200)
+
+	// ASSERT
+	assert.NoError(t, err, "unexpected error generating statement")
+	assert.Equal(t, float64(900), statement.OpeningBalance, "opening balance mismatch")
+	assert.Equal(t, float64(850), statement.ClosingBalance, "closing balance mismatch")
+	assert.Len(t, statement.Events, 1, "expected only the transfer within the period")
+	assert.Equal(t, 120, statement.Events[0].Timestamp, "event mismatch")
+}
+
+func TestScheduleStatements(t *testing.T) {
+	t.Run("Delivers Tagged Accounts At Cycle Close", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(110, "a", 1000)
+		store.CreateAccount(110, "b", 0)
+		store.SetAccountTags("a", "monthly")
+		deliverer := &recordingDeliverer{}
+		stop, err := store.ScheduleStatements(StatementSchedule{Cycle: time.Minute, Tag: "monthly"}, deliverer)
+
+		// ACT
+		clock.Advance(20 * time.Second)
+		store.Transfer(130, "a", "b", 100)
+		clock.Advance(time.Minute)
+		stop()
+		clock.Advance(time.Minute)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error scheduling statements")
+		assert.Len(t, deliverer.delivered, 2, "expected one statement per closed cycle")
+		assert.Equal(t, Statement{AccountID: "a", PeriodStart: 60, PeriodEnd: 120, ClosingBalance: 1000, Events: []Event{{Seq: 1, Timestamp: 110, Type: EventAccountCreated, AccountID: "a", Amount: 1000}}}, deliverer.delivered[0], "first statement mismatch")
+		assert.Equal(t, float64(900), deliverer.delivered[1].ClosingBalance, "second statement should include the transfer")
+		assert.Equal(t, []StatementDelivery{
+			{AccountID: "a", PeriodStart: 60, PeriodEnd: 120, Status: StatementDelivered, Attempts: 1},
+			{AccountID: "a", PeriodStart: 120, PeriodEnd: 180, Status: StatementDelivered, Attempts: 1},
+		}, store.StatementDeliveries("a"), "delivery status mismatch")
+		assert.Empty(t, store.StatementDeliveries("b"), "untagged accounts get no statements")
+	})
+
+	t.Run("Retries Failed Deliveries Next Cycle", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(10, "a", 1000)
+		deliverer := &recordingDeliverer{fail: map[string]bool{"a": true}}
+
+		// ACT
+		failed := store.DeliverStatements(context.Background(), 0, 60, "", deliverer)
+		deliverer.fail = nil
+		retried := store.DeliverStatements(context.Background(), 60, 120, "", deliverer)
+
+		// ASSERT
+		assert.EqualError(t, failed, "mailbox full", "expected the delivery error")
+		assert.NoError(t, retried, "unexpected error on retry")
+		assert.Equal(t, []StatementDelivery{
+			{AccountID: "a", PeriodStart: 0, PeriodEnd: 60, Status: StatementDelivered, Attempts: 2},
+			{AccountID: "a", PeriodStart: 60, PeriodEnd: 120, Status: StatementDelivered, Attempts: 1},
+		}, store.StatementDeliveries("a"), "delivery status mismatch")
+	})
+
+	t.Run("Rejects Short Cycles", func(t *testing.T) {
+		// ACT
+		_, err := NewAccountStore().ScheduleStatements(StatementSchedule{Cycle: time.Millisecond}, &recordingDeliverer{})
+
+		// ASSERT
+		assert.EqualError(t, err, "statement cycle must be at least one second", "unexpected error message")
+	})
+}
+
+func TestStatementDeliverers(t *testing.T) {
+	statement := Statement{AccountID: "a", PeriodStart: 0, PeriodEnd: 60, OpeningBalance: 10, ClosingBalance: 5}
+
+	t.Run("File", func(t *testing.T) {
+		// ARRANGE
+		dir := t.TempDir()
+
+		// ACT
+		err := FileStatementDeliverer{Dir: dir}.Deliver(context.Background(), statement)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error writing statement")
+		contents, _ := os.ReadFile(filepath.Join(dir, "statement-a-0.json"))
+		var written Statement
+		assert.NoError(t, json.Unmarshal(contents, &written), "statement should be valid JSON")
+		assert.Equal(t, statement, written, "statement mismatch")
+	})
+
+	t.Run("Webhook", func(t *testing.T) {
+		// ARRANGE
+		var verifyErr, staleErr error
+		clock := newManualClock(time.Unix(1000, 0))
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			verifyErr = VerifyWebhookSignature(body, r.Header.Get(WebhookSignatureHeader), "secret", time.Minute, clock.Now())
+			staleErr = VerifyWebhookSignature(body, r.Header.Get(WebhookSignatureHeader), "secret", time.Minute, time.Now())
+		}))
+		defer server.Close()
+
+		// ACT
+		err := NewWebhookStatementDeliverer(server.Client(), clock, server.URL, "secret").Deliver(context.Background(), statement)
+		unbuilt := WebhookStatementDeliverer{}.Deliver(context.Background(), statement)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected delivery error")
+		assert.NoError(t, verifyErr, "statement should be signed at the time on the clock")
+		assert.Error(t, staleErr, "expected the signature to carry the clock's time, not the system's")
+		assert.Error(t, unbuilt, "expected a deliverer built without the constructor to fail")
+	})
+
+	t.Run("Email", func(t *testing.T) {
+		// ARRANGE
+		var to, subject string
+		deliverer, err := NewEmailStatementDeliverer(map[string]string{"a": "a@example.com"}, func(ctx context.Context, address, title, body string) error {
+			to, subject = address, title
+			return nil
+		})
+		assert.NoError(t, err, "unexpected error creating the deliverer")
+
+		// ACT
+		err = deliverer.Deliver(context.Background(), statement)
+		_, withoutSend := NewEmailStatementDeliverer(map[string]string{"a": "a@example.com"}, nil)
+		missing := deliverer.Deliver(context.Background(), Statement{AccountID: "b"})
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected delivery error")
+		assert.Equal(t, "a@example.com", to, "recipient mismatch")
+		assert.Equal(t, "Statement for a, 0 to 60", subject, "subject mismatch")
+		assert.EqualError(t, missing, "no email address for account b", "unexpected error message")
+		assert.EqualError(t, withoutSend, "email statement deliverer needs a send function", "unexpected error message")
+	})
+}
@@ -0,0 +1,90 @@
+package bankingsystem
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// Storage persists account state. The store writes every change through to Storage before
+// applying it in memory, so Apply must either apply the whole batch or none of it.
+type Storage interface {
+	Apply(ctx context.Context, batch StorageBatch) error
+	Load(ctx context.Context) ([]AccountSnapshot, error)
+}
+
+// StorageBatch is a set of account writes applied atomically.
+type StorageBatch struct {
+	Put    []AccountSnapshot
+	Delete []string
+}
+
+// OpenAccountStore creates a store and restores its accounts from the Storage given with
+// WithStorage. Event history is not part of Storage, so the restored store starts with none.
+func OpenAccountStore(ctx context.Context, opts ...Option) (*AccountStore, error) {
+	s := NewAccountStore(opts...)
+	if s.storage == nil {
+		return s, nil
+	}
+
+	snapshots, err := s.storage.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, snapshot := range snapshots {
+		account := &Account{accountID: snapshot.AccountID, tenantID: snapshot.TenantID}
+		account.restore(snapshot)
+		if isSystemAccount(account.accountID) {
+			s.systemAccounts[account.accountID] = account
+			continue
+		}
+		s.putAccount(account)
+	}
+	return s, nil
+}
+
+// MemoryStorage is a Storage that keeps accounts in memory.
+type MemoryStorage struct {
+	mu       sync.RWMutex
+	accounts map[string]AccountSnapshot
+}
+
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{accounts: make(map[string]AccountSnapshot)}
+}
+
+func (m *MemoryStorage) Apply(ctx context.Context, batch StorageBatch) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, snapshot := range batch.Put {
+		m.accounts[snapshot.AccountID] = snapshot
+	}
+	for _, accountID := range batch.Delete {
+		delete(m.accounts, accountID)
+	}
+	return nil
+}
+
+// Load returns every stored account ordered by account ID.
+func (m *MemoryStorage) Load(ctx context.Context) ([]AccountSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	snapshots := make([]AccountSnapshot, 0, len(m.accounts))
+	for _, snapshot := range m.accounts {
+		snapshots = append(snapshots, snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].AccountID < snapshots[j].AccountID
+	})
+	return snapshots, nil
+}
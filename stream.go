@@ -0,0 +1,218 @@
+package bankingsystem
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const resumeTokenPrefix = "seq:"
+
+// TransactionFilter selects events for StreamTransactions. An empty AccountID matches every
+// account, a zero MaxAmount means no upper bound and empty Types matches every event type.
+type TransactionFilter struct {
+	AccountID string
+	MinAmount float64
+	MaxAmount float64
+	Types     []EventType
+}
+
+// DefaultStreamBuffer is how many live events a stream holds for a slow consumer unless
+// WithStreamBuffer says otherwise.
+const DefaultStreamBuffer = 10_000
+
+// ErrStreamOverflowed ends a stream whose consumer fell further behind than its buffer.
+var ErrStreamOverflowed = errors.New("stream consumer fell too far behind")
+
+// WithStreamBuffer sets how many live events each stream of StreamTransactions buffers while
+// its consumer is busy. Values below 1 mean DefaultStreamBuffer.
+func WithStreamBuffer(events int) Option {
+	return func(s *AccountStore) {
+		if events > 0 {
+			s.streamBuffer = events
+		}
+	}
+}
+
+// StreamedTransaction is an event delivered by StreamTransactions, with the token that resumes
+// the stream right after it. Err is only set on the last value of a stream that was dropped
+// for falling behind; it carries no event, and the stream resumes without gaps from the
+// previous ResumeToken.
+type StreamedTransaction struct {
+	Event       Event
+	ResumeToken string
+	Err         error
+}
+
+// StartOfHistoryToken is a resume token that replays the whole history.
+func StartOfHistoryToken() string {
+	return encodeResumeToken(0)
+}
+
+// StreamTransactions streams committed events matching filter in commit order. With an empty
+// resumeToken the stream starts with the next commit; otherwise it first replays history,
+// archived events included, after the event the token was issued for, then continues live
+// without gaps. The history is read without holding up writers, and live events are buffered
+// meanwhile; a consumer that lets the buffer fill is sent ErrStreamOverflowed. The channel is
+// closed when ctx is done or after ErrStreamOverflowed.
+//
+// This is the store side of a server-streaming transaction feed: a transport such as gRPC
+// only needs to forward each StreamedTransaction and hand resume tokens back to the store.
+func (s *AccountStore) StreamTransactions(ctx context.Context, filter TransactionFilter, resumeToken string) (<-chan StreamedTransaction, error) {
+	if filter.MaxAmount == 0 {
+		filter.MaxAmount = math.Inf(1)
+	}
+	var afterSeq int
+	if resumeToken != "" {
+		seq, err := decodeResumeToken(resumeToken)
+		if err != nil {
+			return nil, err
+		}
+		afterSeq = seq
+	}
+
+	s.mu.Lock()
+	stream := &transactionStream{ready: make(chan struct{}, 1), filter: filter, capacity: s.streamBuffer}
+	// Events committed from here on reach the stream through the subscription. Those before
+	// are read afterwards, as ForEachTransaction reads them, from a view of the hot events
+	// and the part of the archive older than them.
+	hot := s.events[:len(s.events):len(s.events)]
+	archive, archived := s.archive, s.archivedEvents
+	end := s.lastSeq + 1
+	if len(hot) > 0 {
+		end = hot[0].Seq
+	}
+	unsubscribe := s.subscribe(stream.push)
+	s.mu.Unlock()
+
+	var backlog [][]Event
+	if resumeToken != "" {
+		if archive != nil && archived > 0 && afterSeq+1 < end {
+			events, err := archive.Range(historyStart, math.MaxInt)
+			if err != nil {
+				unsubscribe()
+				return nil, err
+			}
+			sort.SliceStable(events, func(i, j int) bool {
+				return events[i].Seq < events[j].Seq
+			})
+			older := sort.Search(len(events), func(i int) bool { return events[i].Seq >= end })
+			backlog = append(backlog, events[:older])
+		}
+		backlog = append(backlog, hot)
+	}
+
+	out := make(chan StreamedTransaction)
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+		send := func(transaction StreamedTransaction) bool {
+			select {
+			case out <- transaction:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for _, events := range backlog {
+			for _, event := range events {
+				if event.Seq <= afterSeq || !filter.matches(event) {
+					continue
+				}
+				if !send(StreamedTransaction{Event: event, ResumeToken: encodeResumeToken(event.Seq)}) {
+					return
+				}
+			}
+		}
+		for {
+			events, overflowed := stream.drain()
+			for _, event := range events {
+				if !send(StreamedTransaction{Event: event, ResumeToken: encodeResumeToken(event.Seq)}) {
+					return
+				}
+			}
+			if overflowed {
+				send(StreamedTransaction{Err: ErrStreamOverflowed})
+				return
+			}
+			select {
+			case <-stream.ready:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// transactionStream buffers matching events between the committing goroutine, which must not
+// block, and the goroutine feeding the consumer. Once capacity events are waiting, it stops
+// buffering and is marked overflowed.
+type transactionStream struct {
+	mu         sync.Mutex
+	pending    []Event
+	overflowed bool
+	capacity   int
+	ready      chan struct{}
+	filter     TransactionFilter
+}
+
+func (t *transactionStream) push(event Event) {
+	if !t.filter.matches(event) {
+		return
+	}
+	t.mu.Lock()
+	if len(t.pending) >= t.capacity {
+		t.overflowed = true
+	} else if !t.overflowed {
+		t.pending = append(t.pending, event)
+	}
+	t.mu.Unlock()
+
+	select {
+	case t.ready <- struct{}{}:
+	default:
+	}
+}
+
+// drain returns the buffered events and whether the stream overflowed after them.
+func (t *transactionStream) drain() ([]Event, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := t.pending
+	t.pending = nil
+	return events, t.overflowed
+}
+
+func (f TransactionFilter) matches(event Event) bool {
+	if f.AccountID != "" && !event.involves(f.AccountID) {
+		return false
+	}
+	if event.Amount < f.MinAmount || event.Amount > f.MaxAmount {
+		return false
+	}
+	return len(f.Types) == 0 || slices.Contains(f.Types, event.Type)
+}
+
+func encodeResumeToken(seq int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(resumeTokenPrefix + strconv.Itoa(seq)))
+}
+
+func decodeResumeToken(token string) (int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || !strings.HasPrefix(string(decoded), resumeTokenPrefix) {
+		return 0, errors.New("invalid resume token")
+	}
+	seq, err := strconv.Atoi(strings.TrimPrefix(string(decoded), resumeTokenPrefix))
+	if err != nil {
+		return 0, errors.New("invalid resume token")
+	}
+	return seq, nil
+}
@@ -0,0 +1,136 @@
+package bankingsystem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamTransactions(t *testing.T) {
+	receive := func(t *testing.T, stream <-chan StreamedTransaction) StreamedTransaction {
+		t.Helper()
+		select {
+		case transaction := <-stream:
+			return transaction
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a streamed transaction")
+			return StreamedTransaction{}
+		}
+	}
+
+	t.Run("Streams Live Matching Transactions", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 1000)
+		store.CreateAccount(1, "c", 1000)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, err := store.StreamTransactions(ctx, TransactionFilter{AccountID: "b", MinAmount: 50}, "")
+		assert.NoError(t, err, "unexpected error opening stream")
+
+		// ACT
+		store.Transfer(2, "a", "b", 10)
+		store.Transfer(3, "a", "c", 100)
+		store.Transfer(4, "b", "c", 100)
+		store.Transfer(5, "a", "b", 200)
+
+		// ASSERT
+		first := receive(t, stream)
+		second := receive(t, stream)
+		assert.Equal(t, 6, first.Event.Seq, "expected the transfer from b")
+		assert.Equal(t, 7, second.Event.Seq, "expected the transfer to b")
+	})
+
+	t.Run("Resumes Without Gaps", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 1000)
+		ctx, cancel := context.WithCancel(context.Background())
+		stream, _ := store.StreamTransactions(ctx, TransactionFilter{Types: []EventType{EventTransfer}}, StartOfHistoryToken())
+		store.Transfer(2, "a", "b", 1)
+		token := receive(t, stream).ResumeToken
+		cancel()
+
+		store.Transfer(3, "a", "b", 2)
+		store.Transfer(4, "a", "b", 3)
+
+		// ACT
+		ctx, cancel = context.WithCancel(context.Background())
+		defer cancel()
+		resumed, err := store.StreamTransactions(ctx, TransactionFilter{Types: []EventType{EventTransfer}}, token)
+		assert.NoError(t, err, "unexpected error resuming stream")
+		store.Transfer(5, "a", "b", 4)
+
+		// ASSERT
+		var amounts []float64
+		for range 3 {
+			amounts = append(amounts, receive(t, resumed).Event.Amount)
+		}
+		assert.Equal(t, []float64{2, 3, 4}, amounts, "expected backlog then live transactions in commit order")
+	})
+
+	t.Run("Resumes From The Archive", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithArchive(NewMemoryArchive(), 1))
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 1000)
+		store.Transfer(2, "a", "b", 1)
+		store.Transfer(3*secondsPerDay, "a", "b", 2)
+		store.ArchiveTransactions(3 * secondsPerDay)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// ACT
+		stream, err := store.StreamTransactions(ctx, TransactionFilter{Types: []EventType{EventTransfer}}, encodeResumeToken(1))
+		assert.NoError(t, err, "unexpected error opening stream")
+		store.Transfer(3*secondsPerDay, "a", "b", 3)
+
+		// ASSERT
+		var amounts []float64
+		for range 3 {
+			amounts = append(amounts, receive(t, stream).Event.Amount)
+		}
+		assert.Equal(t, []float64{1, 2, 3}, amounts, "expected archived, hot then live transactions")
+	})
+
+	t.Run("Drops Consumers That Fall Behind", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore(WithStreamBuffer(2))
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 1000)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, _ := store.StreamTransactions(ctx, TransactionFilter{}, "")
+
+		// ACT
+		for i := range 5 {
+			store.Transfer(2+i, "a", "b", 1)
+		}
+
+		// ASSERT
+		var seqs []int
+		transaction := receive(t, stream)
+		for ; transaction.Err == nil; transaction = receive(t, stream) {
+			seqs = append(seqs, transaction.Event.Seq)
+		}
+		_, open := <-stream
+		assert.ErrorIs(t, transaction.Err, ErrStreamOverflowed, "expected the consumer to be dropped")
+		assert.False(t, open, "the stream should be closed")
+		assert.Less(t, len(seqs), 5, "the buffer should be bounded")
+		for i, seq := range seqs {
+			assert.Equal(t, 3+i, seq, "buffered events should be delivered without gaps")
+		}
+	})
+
+	t.Run("Invalid Resume Token", func(t *testing.T) {
+		// ACT
+		_, err := NewAccountStore().StreamTransactions(context.Background(), TransactionFilter{}, "not-a-token")
+
+		// ASSERT
+		assert.EqualError(t, err, "invalid resume token", "unexpected error message")
+	})
+}
@@ -0,0 +1,124 @@
+package bankingsystem
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SweepPolicy configures StartSweeper.
+type SweepPolicy struct {
+	// Interval is how often the sweeper runs.
+	Interval time.Duration
+	// HoldTTL is how long a hold may stay placed before the sweeper releases it. Zero keeps
+	// holds until they are released.
+	HoldTTL time.Duration
+	// OnSweep, if set, receives the report of every run, for example to export it as metrics.
+	OnSweep func(SweepReport)
+}
+
+// SweepReport is what one sweep cleaned up.
+type SweepReport struct {
+	At int
+	// ExpiredHolds lists the IDs of the holds released for being older than the HoldTTL, and
+	// ReleasedAmount their total.
+	ExpiredHolds   []string
+	ReleasedAmount float64
+	// ExpiredIdempotencyKeys is how many idempotency keys of HTTP handlers on the store were
+	// forgotten for being older than their TTL.
+	ExpiredIdempotencyKeys int
+	// ExpiredQuotes is how many transfer quotes were forgotten for having expired unused.
+	ExpiredQuotes int
+}
+
+// StartSweeper runs Sweep every Interval on the store's clock until stop is called. Idempotency
+// keys otherwise only expire when a request for a new key arrives, and holds never do.
+func (s *AccountStore) StartSweeper(policy SweepPolicy) (stop func(), err error) {
+	switch {
+	case policy.Interval <= 0:
+		return nil, errors.New("sweep interval must be positive")
+	case policy.HoldTTL < 0:
+		return nil, errors.New("hold TTL must not be negative")
+	}
+
+	job := &sweepJob{store: s, policy: policy}
+	job.arm()
+	return job.stop, nil
+}
+
+// Sweep releases holds older than holdTTL, unless it is zero, and forgets expired idempotency
+// keys and transfer quotes, once and now.
+func (s *AccountStore) Sweep(holdTTL time.Duration) SweepReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSweep, Duration: holdTTL})
+	return s.sweep(holdTTL)
+}
+
+// sweep is Sweep without tracing, for the sweeper, whose timer is traced instead. Callers
+// must hold the write lock.
+func (s *AccountStore) sweep(holdTTL time.Duration) SweepReport {
+	now := int(s.clock.Now().Unix())
+	report := SweepReport{At: now}
+	if holdTTL > 0 {
+		cutoff := now - int(holdTTL/time.Second)
+		for _, hold := range s.holds {
+			if hold.PlacedAt <= cutoff {
+				report.ExpiredHolds = append(report.ExpiredHolds, hold.ID)
+				report.ReleasedAmount += hold.Amount
+				s.releaseHold(hold)
+			}
+		}
+		sort.Strings(report.ExpiredHolds)
+	}
+	report.ExpiredQuotes = s.forgetExpiredQuotes(now)
+	for _, cache := range s.idempotencyCaches {
+		report.ExpiredIdempotencyKeys += cache.sweep()
+	}
+
+	s.sweeps++
+	s.expiredHolds += len(report.ExpiredHolds)
+	s.expiredKeys += report.ExpiredIdempotencyKeys
+	if len(report.ExpiredHolds) > 0 || report.ExpiredIdempotencyKeys > 0 || report.ExpiredQuotes > 0 {
+		s.logger.Info("swept stale state", "expiredHolds", len(report.ExpiredHolds), "releasedAmount", report.ReleasedAmount, "expiredIdempotencyKeys", report.ExpiredIdempotencyKeys, "expiredQuotes", report.ExpiredQuotes)
+	}
+	return report
+}
+
+type sweepJob struct {
+	store  *AccountStore
+	policy SweepPolicy
+
+	mu      sync.Mutex
+	timer   Timer
+	stopped bool
+}
+
+func (j *sweepJob) arm() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.stopped {
+		return
+	}
+	j.timer = j.store.clock.AfterFunc(j.policy.Interval, func() {
+		j.store.mu.Lock()
+		report := j.store.sweep(j.policy.HoldTTL)
+		j.store.mu.Unlock()
+		if j.policy.OnSweep != nil {
+			j.policy.OnSweep(report)
+		}
+		j.arm()
+	})
+}
+
+func (j *sweepJob) stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.stopped = true
+	if j.timer != nil {
+		j.timer.Stop()
+	}
+}
@@ -0,0 +1,87 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSweeper(t *testing.T) {
+	t.Run("Expires Stale Holds", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(1000, "a", 1000)
+		stale, _ := store.PlaceHold(1000, "a", 300)
+		var reports []SweepReport
+		stop, err := store.StartSweeper(SweepPolicy{Interval: time.Hour, HoldTTL: time.Hour, OnSweep: func(report SweepReport) {
+			reports = append(reports, report)
+		}})
+		assert.NoError(t, err, "unexpected error starting sweeper")
+		defer stop()
+		clock.Advance(45 * time.Minute)
+		fresh, _ := store.PlaceHold(3700, "a", 200)
+
+		// ACT
+		clock.Advance(15 * time.Minute)
+
+		// ASSERT
+		assert.Len(t, reports, 1, "expected one sweep")
+		assert.Equal(t, []string{stale}, reports[0].ExpiredHolds, "only the stale hold should expire")
+		assert.Equal(t, float64(300), reports[0].ReleasedAmount, "released amount mismatch")
+		holds := store.Holds("a")
+		assert.Len(t, holds, 1, "the fresh hold should stay")
+		assert.Equal(t, fresh, holds[0].ID, "hold mismatch")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(800), account.AvailableBalance, "available balance mismatch")
+		stats := store.DebugStats()
+		assert.Equal(t, 1, stats.Sweeps, "sweep count mismatch")
+		assert.Equal(t, 1, stats.ExpiredHolds, "expired hold count mismatch")
+	})
+
+	t.Run("Forgets Expired Idempotency Keys", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		store := NewAccountStore(WithClock(clock))
+		handler := NewHTTPHandler(store)
+		for _, key := range []string{"one", "two"} {
+			body, _ := json.Marshal(CreateAccountRequest{Timestamp: 1000, AccountID: key, InitialBalance: 100})
+			request := httptest.NewRequest(http.MethodPost, "/accounts", bytes.NewReader(body))
+			request.Header.Set(IdempotencyKeyHeader, key)
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+		}
+		clock.Advance(DefaultIdempotencyTTL + time.Second)
+
+		// ACT
+		report := store.Sweep(0)
+		again := store.Sweep(0)
+
+		// ASSERT
+		assert.Equal(t, 2, report.ExpiredIdempotencyKeys, "expected both keys to expire")
+		assert.Empty(t, report.ExpiredHolds, "holds should be kept without a TTL")
+		assert.Equal(t, 0, again.ExpiredIdempotencyKeys, "expired keys should only be counted once")
+		assert.Equal(t, 2, store.DebugStats().ExpiredIdempotencyKeys, "expired key count mismatch")
+	})
+
+	t.Run("Stops", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(1000, 0))
+		store := NewAccountStore(WithClock(clock))
+		stop, _ := store.StartSweeper(SweepPolicy{Interval: time.Minute})
+
+		// ACT
+		clock.Advance(time.Minute)
+		stop()
+		clock.Advance(time.Hour)
+
+		// ASSERT
+		assert.Equal(t, 1, store.DebugStats().Sweeps, "no sweep should run after stop")
+		_, err := store.StartSweeper(SweepPolicy{})
+		assert.Error(t, err, "expected a zero interval to be rejected")
+	})
+}
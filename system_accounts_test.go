@@ -0,0 +1,89 @@
+package bankingsystem
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemAccounts(t *testing.T) {
+	t.Run("Fees Collect In A System Account", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(0, 0))
+		storage := NewMemoryStorage()
+		store := NewAccountStore(WithClock(clock), WithStorage(storage))
+		store.CreateAccount(0, "a", 100)
+		store.CreateAccount(0, "b", 0)
+		store.SetOverdraft("a", OverdraftPolicy{Limit: 500, Fees: []float64{15}})
+		store.Transfer(0, "a", "b", 200)
+
+		// ACT
+		clock.Advance(time.Second)
+		restored, _ := OpenAccountStore(context.Background(), WithStorage(storage))
+
+		// ASSERT
+		assert.Equal(t, []AccountSnapshot{{AccountID: SystemFeeIncome, UpdatedAt: 1, Balance: 15}}, store.ListSystemAccounts(), "fee should be collected")
+		assert.Equal(t, store.ListSystemAccounts(), restored.ListSystemAccounts(), "system accounts should be restored")
+		_, err := restored.GetAccount(SystemFeeIncome)
+		assert.ErrorIs(t, err, ErrAccountNotFound, "system accounts are not customer accounts")
+	})
+
+	t.Run("Reserved IDs", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+
+		// ACT
+		account := store.CreateAccount(1, SystemFeeIncome, 100)
+		_, err := store.CreateTenantAccount(1, "acme", "system:other", 100)
+
+		// ASSERT
+		assert.Nil(t, account, "expected creation to fail")
+		assert.EqualError(t, err, "account IDs starting with system: are reserved", "unexpected error message")
+	})
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	t.Run("Balanced Store", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(0, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.CreateAccount(0, "a", 100)
+		store.CreateAccount(0, "b", 50)
+		store.SetOverdraft("a", OverdraftPolicy{Limit: 500, Fees: []float64{15}})
+		store.Transfer(0, "a", "b", 200)
+		store.SchedulePayment(0, "b", 30, 1)
+		clock.Advance(time.Second)
+
+		// ACT
+		report, err := store.CheckIntegrity()
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error checking integrity")
+		assert.True(t, report.OK(), "expected a clean report")
+		assert.Equal(t, &IntegrityReport{
+			Accounts:        2,
+			SystemAccounts:  1,
+			Deposited:       150,
+			PaidOut:         30,
+			CustomerBalance: 105,
+			SystemBalance:   15,
+		}, report, "report mismatch")
+	})
+
+	t.Run("Detects Drift From History", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(0, "a", 100)
+		store.accounts.get("a").balance = 90
+
+		// ACT
+		report, _ := store.CheckIntegrity()
+
+		// ASSERT
+		assert.False(t, report.OK(), "expected drift to be reported")
+		assert.Equal(t, []IntegrityMismatch{{AccountID: "a", Live: 90, Replayed: 100}}, report.Mismatches, "mismatch details")
+		assert.Equal(t, float64(10), report.Unaccounted, "unaccounted money mismatch")
+	})
+}
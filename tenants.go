@@ -0,0 +1,125 @@
+package bankingsystem
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrQuotaExceeded is returned when an operation would take a tenant over its Quota.
+var ErrQuotaExceeded = errors.New("tenant quota exceeded")
+
+// Quota caps what a tenant's accounts may use. A zero field means no limit. Daily transfer
+// volume counts transfers sent from the tenant's accounts per UTC day of the transfer timestamp.
+type Quota struct {
+	MaxAccounts          int
+	MaxScheduledPayments int
+	DailyTransferVolume  float64
+}
+
+// TenantUsage is how much of its quota a tenant is using.
+type TenantUsage struct {
+	Accounts          int
+	ScheduledPayments int
+	TransferVolume    float64
+}
+
+// SetTenantQuota sets, or replaces, a tenant's quota. Usage already above the new quota is not
+// revoked; only new operations are rejected.
+func (s *AccountStore) SetTenantQuota(tenantID string, quota Quota) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSetTenantQuota, TenantID: tenantID, Quota: &quota})
+
+	s.tenantQuotas[tenantID] = quota
+}
+
+// CreateTenantAccount creates, or replaces, an account owned by tenantID.
+func (s *AccountStore) CreateTenantAccount(timestamp int, tenantID, accountID string, initialBalance float64) (*Account, error) {
+	if tenantID == "" {
+		return nil, errors.New("tenant ID is required")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceCreateTenantAccount, Timestamp: timestamp, TenantID: tenantID, AccountID: accountID, Amount: initialBalance})
+
+	return s.createAccount(timestamp, tenantID, accountID, initialBalance)
+}
+
+// TenantUsage reports a tenant's current accounts and pending scheduled payments, and its
+// transfer volume on the day containing timestamp.
+func (s *AccountStore) TenantUsage(tenantID string, timestamp int) TenantUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.tenantUsage(tenantID, timestamp)
+}
+
+// tenantUsage is TenantUsage for callers that hold the lock.
+func (s *AccountStore) tenantUsage(tenantID string, timestamp int) TenantUsage {
+	return TenantUsage{
+		Accounts:          s.tenantAccounts[tenantID],
+		ScheduledPayments: s.tenantPayments[tenantID],
+		TransferVolume:    s.tenantVolume[tenantID][dayOf(timestamp)],
+	}
+}
+
+func (s *AccountStore) checkAccountQuota(tenantID, accountID string) error {
+	limit := s.tenantQuotas[tenantID].MaxAccounts
+	if limit == 0 {
+		return nil
+	}
+	if existing, exists := s.accounts.lookup(accountID); exists && existing.tenantID == tenantID {
+		return nil
+	}
+	if s.tenantAccounts[tenantID] >= limit {
+		return fmt.Errorf("%w: tenant %s has %d accounts", ErrQuotaExceeded, tenantID, s.tenantAccounts[tenantID])
+	}
+	return nil
+}
+
+func (s *AccountStore) checkPaymentQuota(tenantID string) error {
+	if tenantID == "" {
+		return nil
+	}
+	if limit := s.tenantQuotas[tenantID].MaxScheduledPayments; limit > 0 && s.tenantPayments[tenantID] >= limit {
+		return fmt.Errorf("%w: tenant %s has %d pending payments", ErrQuotaExceeded, tenantID, s.tenantPayments[tenantID])
+	}
+	return nil
+}
+
+func (s *AccountStore) checkTransferQuota(tenantID string, timestamp int, amount float64) error {
+	if tenantID == "" {
+		return nil
+	}
+	limit := s.tenantQuotas[tenantID].DailyTransferVolume
+	if limit == 0 {
+		return nil
+	}
+	if used := s.tenantVolume[tenantID][dayOf(timestamp)]; used+amount > limit {
+		return fmt.Errorf("%w: tenant %s has transferred %.2f of %.2f today", ErrQuotaExceeded, tenantID, used, limit)
+	}
+	return nil
+}
+
+// addTransferVolume counts a committed transfer against its tenant. Callers must hold the
+// write lock.
+func (s *AccountStore) addTransferVolume(tenantID string, timestamp int, amount float64) {
+	if tenantID == "" {
+		return
+	}
+	if s.tenantVolume[tenantID] == nil {
+		s.tenantVolume[tenantID] = make(map[int]float64)
+	}
+	s.tenantVolume[tenantID][dayOf(timestamp)] += amount
+	s.meterTransfer(tenantID, timestamp, amount)
+}
+
+// dayOf returns the UTC day number containing timestamp.
+func dayOf(timestamp int) int {
+	day := timestamp / secondsPerDay
+	if timestamp < 0 && timestamp%secondsPerDay != 0 {
+		day--
+	}
+	return day
+}
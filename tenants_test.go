@@ -0,0 +1,90 @@
+package bankingsystem
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantQuotas(t *testing.T) {
+	t.Run("Max Accounts", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.SetTenantQuota("acme", Quota{MaxAccounts: 2})
+		_, firstErr := store.CreateTenantAccount(1, "acme", "a", 100)
+		_, secondErr := store.CreateTenantAccount(1, "acme", "b", 100)
+
+		// ACT
+		account, err := store.CreateTenantAccount(1, "acme", "c", 100)
+		_, replaceErr := store.CreateTenantAccount(2, "acme", "b", 200)
+		_, otherErr := store.CreateTenantAccount(1, "globex", "d", 100)
+
+		// ASSERT
+		assert.NoError(t, firstErr, "unexpected error creating first account")
+		assert.NoError(t, secondErr, "unexpected error creating second account")
+		assert.Nil(t, account, "expected no account over quota")
+		assert.ErrorIs(t, err, ErrQuotaExceeded, "expected quota error")
+		assert.NoError(t, replaceErr, "replacing an account should not count against the quota")
+		assert.NoError(t, otherErr, "other tenants should not be affected")
+		assert.Equal(t, 2, store.TenantUsage("acme", 1).Accounts, "account usage mismatch")
+	})
+
+	t.Run("Merged Accounts Free Capacity", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.SetTenantQuota("acme", Quota{MaxAccounts: 2})
+		store.CreateTenantAccount(1, "acme", "a", 100)
+		store.CreateTenantAccount(1, "acme", "b", 100)
+
+		// ACT
+		store.MergeAccounts(2, "a", "b")
+		_, err := store.CreateTenantAccount(3, "acme", "c", 100)
+
+		// ASSERT
+		assert.NoError(t, err, "merge should free account capacity")
+	})
+
+	t.Run("Max Scheduled Payments", func(t *testing.T) {
+		// ARRANGE
+		clock := newManualClock(time.Unix(100, 0))
+		store := NewAccountStore(WithClock(clock))
+		store.SetTenantQuota("acme", Quota{MaxScheduledPayments: 1})
+		store.CreateTenantAccount(100, "acme", "a", 1000)
+		store.CreateTenantAccount(100, "acme", "b", 1000)
+		_, firstErr := store.SchedulePayment(100, "a", 10, 10)
+
+		// ACT
+		_, err := store.SchedulePayment(100, "b", 10, 10)
+		clock.Advance(10 * time.Second)
+		_, afterExecutionErr := store.SchedulePayment(110, "b", 10, 10)
+
+		// ASSERT
+		assert.NoError(t, firstErr, "unexpected error scheduling first payment")
+		assert.ErrorIs(t, err, ErrQuotaExceeded, "expected quota error across the tenant's accounts")
+		assert.NoError(t, afterExecutionErr, "executed payment should free capacity")
+		assert.Equal(t, 1, store.TenantUsage("acme", 110).ScheduledPayments, "payment usage mismatch")
+	})
+
+	t.Run("Daily Transfer Volume", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.SetTenantQuota("acme", Quota{DailyTransferVolume: 500})
+		store.CreateTenantAccount(1, "acme", "a", 1000)
+		store.CreateAccount(1, "b", 0)
+		success, firstErr := store.Transfer(10, "a", "b", 400)
+
+		// ACT
+		_, err := store.Transfer(20, "a", "b", 200)
+		_, nextDayErr := store.Transfer(secondsPerDay+10, "a", "b", 200)
+
+		// ASSERT
+		assert.True(t, success, "expected first transfer to succeed")
+		assert.NoError(t, firstErr, "unexpected error on first transfer")
+		assert.ErrorIs(t, err, ErrQuotaExceeded, "expected quota error")
+		assert.NoError(t, nextDayErr, "volume should reset on the next day")
+		assert.Equal(t, float64(400), store.TenantUsage("acme", 20).TransferVolume, "first day usage mismatch")
+		assert.Equal(t, float64(200), store.TenantUsage("acme", secondsPerDay).TransferVolume, "second day usage mismatch")
+		assert.Equal(t, float64(400), store.accounts.get("a").balance, "balance mismatch")
+	})
+}
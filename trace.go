@@ -0,0 +1,411 @@
+package bankingsystem
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// TraceOp is the kind of a TraceEntry.
+type TraceOp string
+
+const (
+	TraceCreateAccount       TraceOp = "create_account"
+	TraceCreateTenantAccount TraceOp = "create_tenant_account"
+	TraceTransfer            TraceOp = "transfer"
+	TraceSchedulePayment     TraceOp = "schedule_payment"
+	TraceCancelPayment       TraceOp = "cancel_payment"
+	TraceMergeAccounts       TraceOp = "merge_accounts"
+	TracePlaceHold           TraceOp = "place_hold"
+	TraceReleaseHold         TraceOp = "release_hold"
+	TraceSplitTransfer       TraceOp = "split_transfer"
+	TraceQuoteTransfer       TraceOp = "quote_transfer"
+	TraceTransferWithQuote   TraceOp = "transfer_with_quote"
+	TraceMergeAccountsMany   TraceOp = "merge_accounts_many"
+	TraceTransitionAccount   TraceOp = "transition_account"
+	TraceRedenominateAccount TraceOp = "redenominate_account"
+	TraceSetOverdraft        TraceOp = "set_overdraft"
+	TraceSetMinimumBalance   TraceOp = "set_minimum_balance"
+	TraceSetAccountTags      TraceOp = "set_account_tags"
+	TraceSetAmountPolicy     TraceOp = "set_amount_policy"
+	TraceSetTierAmountPolicy TraceOp = "set_tier_amount_policy"
+	TraceSetTenantQuota      TraceOp = "set_tenant_quota"
+	TraceSetTransferGuard    TraceOp = "set_transfer_guard"
+	TraceRequeueDeadLetter   TraceOp = "requeue_dead_letter"
+	TraceDiscardDeadLetter   TraceOp = "discard_dead_letter"
+	TracePauseScheduling     TraceOp = "pause_scheduling"
+	TraceResumeScheduling    TraceOp = "resume_scheduling"
+	TraceMergeReplica        TraceOp = "merge_replica"
+	TraceAcknowledgeConflict TraceOp = "acknowledge_conflict"
+	TraceReloadConfig        TraceOp = "reload_config"
+	TraceSweep               TraceOp = "sweep"
+	TraceArchiveTransactions TraceOp = "archive_transactions"
+	TraceAcknowledgeEvents   TraceOp = "acknowledge_events"
+	TraceAddAccountNote      TraceOp = "add_account_note"
+	TraceOpenCase            TraceOp = "open_case"
+	TraceAddCaseNote         TraceOp = "add_case_note"
+	TraceCloseCase           TraceOp = "close_case"
+	// TraceUnreplayable records a call to the operation Name, whose arguments cannot be
+	// written down, such as AddTransferRule. ReplayTrace fails when it meets one.
+	TraceUnreplayable TraceOp = "unreplayable"
+	// TraceSequence records that the store drew Value from the sequence Name.
+	TraceSequence TraceOp = "sequence"
+	// TraceTimer records that the Timer-th timer the store created fired.
+	TraceTimer TraceOp = "timer"
+)
+
+// TraceEntry is one step of an operation trace. At is the store's clock when the step was
+// recorded; the other fields are the operation's arguments, and are left zero when they do
+// not apply. For transfers and merges AccountID is the source and CounterpartyID the
+// destination; IDs holds the sources of MergeAccountsMany and the tags of SetAccountTags.
+type TraceEntry struct {
+	Op             TraceOp
+	At             time.Time
+	Timestamp      int             `json:",omitempty"`
+	AccountID      string          `json:",omitempty"`
+	CounterpartyID string          `json:",omitempty"`
+	TenantID       string          `json:",omitempty"`
+	Amount         float64         `json:",omitempty"`
+	DelaySeconds   int             `json:",omitempty"`
+	Priority       PaymentPriority `json:",omitempty"`
+	// ID is the payment, hold, quote, dead letter or case an operation refers to.
+	ID           string              `json:",omitempty"`
+	IDs          []string            `json:",omitempty"`
+	Destinations []WeightedDest      `json:",omitempty"`
+	State        AccountState        `json:",omitempty"`
+	Reason       ReasonCode          `json:",omitempty"`
+	Actor        string              `json:",omitempty"`
+	Text         string              `json:",omitempty"`
+	Kind         CaseKind            `json:",omitempty"`
+	Currency     string              `json:",omitempty"`
+	Rate         float64             `json:",omitempty"`
+	Duration     time.Duration       `json:",omitempty"`
+	Overdraft    *OverdraftPolicy    `json:",omitempty"`
+	AmountPolicy *AmountPolicy       `json:",omitempty"`
+	Quota        *Quota              `json:",omitempty"`
+	Guard        *TransferGuard      `json:",omitempty"`
+	Config       *Config             `json:",omitempty"`
+	Replicas     []ReplicatedAccount `json:",omitempty"`
+	// Name is the sequence a number was drawn from, the tier of SetTierAmountPolicy or the
+	// operation of TraceUnreplayable.
+	Name string `json:",omitempty"`
+	// Value is the number drawn from a sequence, the sequence number of AcknowledgeEvents, the
+	// transfer of OpenCase or the conflict of AcknowledgeConflict.
+	Value int `json:",omitempty"`
+	Timer int `json:",omitempty"`
+}
+
+// TraceRecorder writes an operation trace as JSON lines, one TraceEntry per line, for
+// ReplayTrace to reproduce the store's state from. Install it with WithTrace.
+type TraceRecorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	err     error
+}
+
+func NewTraceRecorder(w io.Writer) *TraceRecorder {
+	return &TraceRecorder{encoder: json.NewEncoder(w)}
+}
+
+// Err returns the first error writing the trace. Entries after it are dropped.
+func (r *TraceRecorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.err
+}
+
+func (r *TraceRecorder) record(entry TraceEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err == nil {
+		r.err = r.encoder.Encode(entry)
+	}
+}
+
+// WithTrace records to recorder every call to an operation that changes the store, along with
+// the numbers drawn from the store's sequences and when the store's timers fire. Together
+// these are the inputs that decide the store's state, so replaying the trace against a fresh
+// store reproduces it. Subscribers, watchers, streams and webhooks only observe the store and
+// are not traced, and neither is MigrateStorage, which moves the store's state without
+// changing it. AddTransferRule and EnableArchival are recorded, but take arguments a trace
+// cannot hold, so a trace that calls them cannot be replayed. Timers are told apart by the
+// order they were created in, so background jobs such as StartSweeper and ScheduleStatements
+// must not run on a traced store.
+func WithTrace(recorder *TraceRecorder) Option {
+	return func(s *AccountStore) {
+		s.tracer = recorder
+	}
+}
+
+// traceCall records a call to an operation. Callers must hold the write lock, so that calls
+// are recorded in the order they take effect.
+func (s *AccountStore) traceCall(entry TraceEntry) {
+	if s.tracer == nil {
+		return
+	}
+	entry.At = s.clock.Now()
+	s.tracer.record(entry)
+}
+
+// startTracing wraps the clock and sequences chosen by the options so that what they hand the
+// store is traced.
+func (s *AccountStore) startTracing() {
+	if s.tracer == nil {
+		return
+	}
+	s.clock = &tracingClock{Clock: s.clock, recorder: s.tracer}
+	s.sequences = &tracingSequences{SequenceProvider: s.sequences, recorder: s.tracer, clock: s.clock}
+}
+
+type tracingClock struct {
+	Clock
+	recorder *TraceRecorder
+
+	mu     sync.Mutex
+	timers int
+}
+
+func (c *tracingClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	c.timers++
+	timer := c.timers
+	c.mu.Unlock()
+
+	return c.Clock.AfterFunc(d, func() {
+		c.recorder.record(TraceEntry{Op: TraceTimer, At: c.Now(), Timer: timer})
+		f()
+	})
+}
+
+type tracingSequences struct {
+	SequenceProvider
+	recorder *TraceRecorder
+	clock    Clock
+}
+
+func (t *tracingSequences) Next(ctx context.Context, name string) (int, error) {
+	value, err := t.SequenceProvider.Next(ctx, name)
+	if err == nil {
+		t.recorder.record(TraceEntry{Op: TraceSequence, At: t.clock.Now(), Name: name, Value: value})
+	}
+	return value, err
+}
+
+// ReadTrace reads a trace written by a TraceRecorder.
+func ReadTrace(r io.Reader) ([]TraceEntry, error) {
+	var entries []TraceEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var entry TraceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("reading trace line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// ReplayTrace applies a trace to a fresh store built with options and returns the store.
+// options should configure the store as the traced one was, except for its clock and
+// sequences: the replay runs on a clock that moves only to the recorded times, fires each timer
+// where the trace says it fired, and draws the recorded sequence numbers. Operations that
+// failed when traced fail again and are otherwise ignored. Steps that ran concurrently, such as
+// a timer firing during a transfer, are replayed in the order they were recorded.
+func ReplayTrace(entries []TraceEntry, options ...Option) (*AccountStore, error) {
+	clock := &replayClock{timers: make(map[int]*replayTimer)}
+	sequences := &replaySequences{values: make(map[string][]int)}
+	for _, entry := range entries {
+		if entry.Op == TraceSequence {
+			sequences.values[entry.Name] = append(sequences.values[entry.Name], entry.Value)
+		}
+	}
+	if len(entries) > 0 {
+		clock.now = entries[0].At
+	}
+	store := NewAccountStore(append(options, WithClock(clock), WithSequences(sequences))...)
+
+	for i, entry := range entries {
+		clock.now = entry.At
+		switch entry.Op {
+		case TraceCreateAccount:
+			store.CreateAccount(entry.Timestamp, entry.AccountID, entry.Amount)
+		case TraceCreateTenantAccount:
+			store.CreateTenantAccount(entry.Timestamp, entry.TenantID, entry.AccountID, entry.Amount)
+		case TraceTransfer:
+			store.Transfer(entry.Timestamp, entry.AccountID, entry.CounterpartyID, entry.Amount)
+		case TraceSchedulePayment:
+			store.SchedulePaymentWithPriority(entry.Timestamp, entry.AccountID, entry.Amount, entry.DelaySeconds, entry.Priority)
+		case TraceCancelPayment:
+			store.CancelScheduledPayment(entry.ID)
+		case TraceMergeAccounts:
+			store.MergeAccounts(entry.Timestamp, entry.AccountID, entry.CounterpartyID)
+		case TracePlaceHold:
+			store.PlaceHold(entry.Timestamp, entry.AccountID, entry.Amount)
+		case TraceReleaseHold:
+			store.ReleaseHold(entry.ID)
+		case TraceSplitTransfer:
+			store.SplitTransfer(entry.Timestamp, entry.AccountID, entry.Amount, entry.Destinations)
+		case TraceQuoteTransfer:
+			store.QuoteTransfer(entry.AccountID, entry.CounterpartyID, entry.Amount)
+		case TraceTransferWithQuote:
+			store.TransferWithQuote(entry.Timestamp, entry.ID)
+		case TraceMergeAccountsMany:
+			store.MergeAccountsMany(entry.Timestamp, entry.IDs, entry.CounterpartyID)
+		case TraceTransitionAccount:
+			store.TransitionAccount(entry.Timestamp, entry.AccountID, entry.State, entry.Reason, entry.Actor)
+		case TraceRedenominateAccount:
+			store.RedenominateAccount(entry.Timestamp, entry.AccountID, entry.Currency, entry.Rate)
+		case TraceSetMinimumBalance:
+			store.SetMinimumBalance(entry.AccountID, entry.Amount)
+		case TraceSetAccountTags:
+			store.SetAccountTags(entry.AccountID, entry.IDs...)
+		case TraceRequeueDeadLetter:
+			store.RequeueDeadLetter(entry.Timestamp, entry.ID)
+		case TraceDiscardDeadLetter:
+			store.DiscardDeadLetter(entry.ID)
+		case TracePauseScheduling:
+			store.PauseScheduling()
+		case TraceResumeScheduling:
+			store.ResumeScheduling()
+		case TraceMergeReplica:
+			store.MergeReplica(entry.Replicas)
+		case TraceAcknowledgeConflict:
+			store.AcknowledgeConflict(entry.Value)
+		case TraceSweep:
+			store.Sweep(entry.Duration)
+		case TraceArchiveTransactions:
+			store.ArchiveTransactions(entry.Timestamp)
+		case TraceAcknowledgeEvents:
+			store.AcknowledgeEvents(entry.AccountID, entry.Value)
+		case TraceAddAccountNote:
+			store.AddAccountNote(entry.Timestamp, entry.AccountID, entry.Actor, entry.Text)
+		case TraceOpenCase:
+			store.OpenCase(entry.Timestamp, entry.Kind, entry.AccountID, entry.Value, entry.Actor, entry.Text)
+		case TraceAddCaseNote:
+			store.AddCaseNote(entry.Timestamp, entry.ID, entry.Actor, entry.Text)
+		case TraceCloseCase:
+			store.CloseCase(entry.Timestamp, entry.ID, entry.Actor, entry.Text)
+		case TraceSetOverdraft, TraceSetAmountPolicy, TraceSetTierAmountPolicy, TraceSetTenantQuota, TraceSetTransferGuard, TraceReloadConfig:
+			if err := replaySetting(store, entry); err != nil {
+				return nil, fmt.Errorf("replaying trace entry %d: %w", i+1, err)
+			}
+		case TraceUnreplayable:
+			return nil, fmt.Errorf("replaying trace entry %d: %s cannot be replayed", i+1, entry.Name)
+		case TraceSequence:
+		case TraceTimer:
+			if err := clock.fire(entry.Timer); err != nil {
+				return nil, fmt.Errorf("replaying trace entry %d: %w", i+1, err)
+			}
+		default:
+			return nil, fmt.Errorf("replaying trace entry %d: unknown operation %q", i+1, entry.Op)
+		}
+	}
+	return store, nil
+}
+
+// replaySetting applies an entry that changes one of the store's settings. The setting is
+// required, since a trace missing it would otherwise replay with the zero setting.
+func replaySetting(store *AccountStore, entry TraceEntry) error {
+	switch {
+	case entry.Op == TraceSetOverdraft && entry.Overdraft != nil:
+		store.SetOverdraft(entry.AccountID, *entry.Overdraft)
+	case entry.Op == TraceSetAmountPolicy && entry.AmountPolicy != nil:
+		store.SetAmountPolicy(entry.TenantID, *entry.AmountPolicy)
+	case entry.Op == TraceSetTierAmountPolicy && entry.AmountPolicy != nil:
+		store.SetTierAmountPolicy(entry.Name, *entry.AmountPolicy)
+	case entry.Op == TraceSetTenantQuota && entry.Quota != nil:
+		store.SetTenantQuota(entry.TenantID, *entry.Quota)
+	case entry.Op == TraceSetTransferGuard && entry.Guard != nil:
+		store.SetTransferGuard(entry.TenantID, *entry.Guard)
+	case entry.Op == TraceReloadConfig && entry.Config != nil:
+		store.ReloadConfig(*entry.Config)
+	default:
+		return fmt.Errorf("%s is missing its setting", entry.Op)
+	}
+	return nil
+}
+
+// replayClock is the Clock of a replay. Time only moves when the replay sets it, and timers
+// only fire when the trace says so.
+type replayClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	next   int
+	timers map[int]*replayTimer
+}
+
+type replayTimer struct {
+	clock   *replayClock
+	id      int
+	f       func()
+	stopped bool
+}
+
+func (c *replayClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *replayClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.next++
+	timer := &replayTimer{clock: c, id: c.next, f: f}
+	c.timers[timer.id] = timer
+	return timer
+}
+
+func (t *replayTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	_, pending := t.clock.timers[t.id]
+	stopped := t.stopped
+	t.stopped = true
+	return pending && !stopped
+}
+
+// fire runs the id-th timer created during the replay. The trace says the timer fired, so it
+// runs even if it was stopped: the stop lost the race with the timer when the trace was
+// recorded, and the store's callbacks are written to cope with that.
+func (c *replayClock) fire(id int) error {
+	c.mu.Lock()
+	timer, pending := c.timers[id]
+	delete(c.timers, id)
+	c.mu.Unlock()
+
+	if !pending {
+		return fmt.Errorf("timer %d was never created or already fired; the trace does not match the store's behaviour", id)
+	}
+	timer.f()
+	return nil
+}
+
+// replaySequences hands out the recorded numbers of each sequence, in the order they were
+// drawn.
+type replaySequences struct {
+	mu     sync.Mutex
+	values map[string][]int
+}
+
+func (r *replaySequences) Next(ctx context.Context, name string) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	values := r.values[name]
+	if len(values) == 0 {
+		return 0, fmt.Errorf("trace has no more numbers for sequence %q", name)
+	}
+	r.values[name] = values[1:]
+	return values[0], nil
+}
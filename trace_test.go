@@ -0,0 +1,138 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace(t *testing.T) {
+	snapshot := func(store *AccountStore) ([]AccountSnapshot, []Event) {
+		var accounts []AccountSnapshot
+		store.ForEachAccount(func(account AccountSnapshot) bool {
+			accounts = append(accounts, account)
+			return true
+		})
+		var events []Event
+		store.ForEachTransaction(func(event Event) bool {
+			events = append(events, event)
+			return true
+		})
+		return accounts, events
+	}
+
+	t.Run("Replays To The Same State", func(t *testing.T) {
+		// ARRANGE
+		var trace bytes.Buffer
+		clock := newManualClock(time.Unix(1000, 0))
+		recorder := NewTraceRecorder(&trace)
+		store := NewAccountStore(WithClock(clock), WithTrace(recorder), WithPaymentRetries(1, time.Minute))
+		store.CreateAccount(1000, "a", 100)
+		store.CreateTenantAccount(1000, "acme", "b", 0)
+		store.SchedulePayment(1000, "a", 30, 60)
+		store.SchedulePaymentWithPriority(1000, "a", 500, 60, PriorityPayroll)
+		cancelled, _ := store.SchedulePayment(1000, "b", 10, 600)
+		holdID, _ := store.PlaceHold(1000, "a", 20)
+		store.Transfer(1010, "a", "b", 40)
+		clock.Advance(2 * time.Minute)
+		store.CancelScheduledPayment(*cancelled)
+		store.ReleaseHold(holdID)
+		store.Transfer(1130, "a", "b", 1000)
+		store.MergeAccounts(1140, "a", "b")
+		wantAccounts, wantEvents := snapshot(store)
+		assert.NoError(t, recorder.Err(), "unexpected error recording trace")
+
+		// ACT
+		entries, err := ReadTrace(&trace)
+		assert.NoError(t, err, "unexpected error reading trace")
+		replayed, err := ReplayTrace(entries, WithPaymentRetries(1, time.Minute))
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error replaying trace")
+		gotAccounts, gotEvents := snapshot(replayed)
+		assert.Equal(t, wantAccounts, gotAccounts, "replayed accounts should match")
+		assert.Equal(t, wantEvents, gotEvents, "replayed history should match")
+		wantAttempts, _ := store.GetPaymentAttempts("payment-a-2")
+		gotAttempts, _ := replayed.GetPaymentAttempts("payment-a-2")
+		assert.NotEmpty(t, wantAttempts, "expected the payroll payment to fail")
+		assert.Len(t, gotAttempts, len(wantAttempts), "payment attempts should match")
+		for i := range gotAttempts {
+			assert.True(t, wantAttempts[i].AttemptedAt.Equal(gotAttempts[i].AttemptedAt), "attempt time mismatch")
+			assert.Equal(t, wantAttempts[i].Outcome, gotAttempts[i].Outcome, "attempt outcome mismatch")
+		}
+	})
+
+	t.Run("Replays Every Mutator", func(t *testing.T) {
+		// ARRANGE
+		var trace bytes.Buffer
+		clock := newManualClock(time.Unix(1000, 0))
+		recorder := NewTraceRecorder(&trace)
+		store := NewAccountStore(WithClock(clock), WithTrace(recorder))
+		store.CreateAccount(1000, "a", 1000)
+		store.CreateAccount(1000, "b", 0)
+		store.CreateAccount(1000, "c", 0)
+		store.CreateAccount(1000, "d", 50)
+		store.SetOverdraft("a", OverdraftPolicy{Limit: 100})
+		store.SetMinimumBalance("b", 10)
+		store.SetAccountTags("a", "vip")
+		store.SetAmountPolicy("", AmountPolicy{MaxAmount: 500})
+		store.SplitTransfer(1001, "a", 300, []WeightedDest{{AccountID: "b", Percent: 50}, {AccountID: "c", Percent: 50}})
+		quote, _ := store.QuoteTransfer("a", "b", 40)
+		store.TransferWithQuote(1002, quote.ID)
+		store.PauseScheduling()
+		store.SchedulePayment(1003, "a", 20, 60)
+		store.ResumeScheduling()
+		store.Sweep(time.Minute)
+		store.TransitionAccount(1004, "c", StateFrozen, ReasonFraudSuspected, "ops")
+		store.RedenominateAccount(1005, "b", "EUR", 0.5)
+		store.MergeAccountsMany(1006, []string{"d"}, "a")
+		clock.Advance(2 * time.Minute)
+		store.AddAccountNote(1130, "a", "ops", "checked")
+		wantAccounts, wantEvents := snapshot(store)
+		assert.NoError(t, recorder.Err(), "unexpected error recording trace")
+
+		// ACT
+		entries, err := ReadTrace(&trace)
+		assert.NoError(t, err, "unexpected error reading trace")
+		replayed, err := ReplayTrace(entries)
+
+		// ASSERT
+		assert.NoError(t, err, "unexpected error replaying trace")
+		gotAccounts, gotEvents := snapshot(replayed)
+		assert.Equal(t, wantAccounts, gotAccounts, "replayed accounts should match")
+		assert.Equal(t, wantEvents, gotEvents, "replayed history should match")
+		assert.Equal(t, store.AccountNotes("a"), replayed.AccountNotes("a"), "replayed notes should match")
+	})
+
+	t.Run("Rejects Traces It Cannot Replay", func(t *testing.T) {
+		// ARRANGE
+		var trace bytes.Buffer
+		store := NewAccountStore(WithTrace(NewTraceRecorder(&trace)))
+		store.AddTransferRule(func(ProposedTransfer) (string, error) { return "", nil })
+		entries, _ := ReadTrace(&trace)
+
+		// ACT
+		_, err := ReplayTrace(entries)
+
+		// ASSERT
+		assert.EqualError(t, err, "replaying trace entry 1: AddTransferRule cannot be replayed", "unexpected error message")
+	})
+
+	t.Run("Rejects Traces That Do Not Match", func(t *testing.T) {
+		// ARRANGE
+		entries := []TraceEntry{{Op: TraceTimer, At: time.Unix(1000, 0), Timer: 1}}
+
+		// ACT
+		_, timerErr := ReplayTrace(entries)
+		_, opErr := ReplayTrace([]TraceEntry{{Op: "unknown"}})
+		_, readErr := ReadTrace(strings.NewReader("{\n"))
+
+		// ASSERT
+		assert.Error(t, timerErr, "expected a timer the replay never created to fail")
+		assert.Error(t, opErr, "expected an unknown operation to fail")
+		assert.Error(t, readErr, "expected malformed trace to fail")
+	})
+}
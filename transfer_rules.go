@@ -0,0 +1,138 @@
+package bankingsystem
+
+import (
+	"errors"
+)
+
+var (
+	ErrSelfTransfer = errors.New("transfer to the same account")
+	ErrRoundTrip    = errors.New("transfer reverses a recent transfer")
+)
+
+// GuardAction is what a TransferGuard does with a transfer that matches one of its patterns.
+type GuardAction string
+
+const (
+	GuardReject GuardAction = "reject"
+	GuardFlag   GuardAction = "flag"
+)
+
+// TransferGuard catches self-transfers and round trips, where B sends A at least
+// RoundTripThreshold within RoundTripWindow seconds of A sending B. A zero
+// RoundTripWindow disables round-trip detection.
+type TransferGuard struct {
+	SelfTransfers      GuardAction
+	RoundTrips         GuardAction
+	RoundTripWindow    int
+	RoundTripThreshold float64
+}
+
+// ProposedTransfer is a transfer being validated, as seen by transfer rules.
+type ProposedTransfer struct {
+	Timestamp int
+	FromID    string
+	ToID      string
+	TenantID  string
+	Amount    float64
+}
+
+// TransferRule inspects a proposed transfer. A non-nil error rejects it; a non-empty flag lets
+// it through but records it for review.
+type TransferRule func(transfer ProposedTransfer) (flag string, err error)
+
+// FlaggedTransfer is a committed transfer a guard or rule flagged for review.
+type FlaggedTransfer struct {
+	Seq       int
+	Timestamp int
+	FromID    string
+	ToID      string
+	Amount    float64
+	Reasons   []string
+}
+
+// SetTransferGuard sets the guard for transfers out of tenantID's accounts. The guard set for
+// the empty tenant ID applies to accounts without a tenant and to tenants without a guard.
+func (s *AccountStore) SetTransferGuard(tenantID string, guard TransferGuard) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceSetTransferGuard, TenantID: tenantID, Guard: &guard})
+
+	s.transferGuards[tenantID] = guard
+}
+
+// AddTransferRule runs rule on every transfer after the built-in guards.
+func (s *AccountStore) AddTransferRule(rule TransferRule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.traceCall(TraceEntry{Op: TraceUnreplayable, Name: "AddTransferRule"})
+
+	s.transferRules = append(s.transferRules, rule)
+}
+
+// checkTransferRules applies the tenant's guard and the custom rules to a transfer and returns
+// the reasons it was flagged. Callers must hold the lock.
+func (s *AccountStore) checkTransferRules(timestamp int, fromAccount *Account, toID string, amount float64) ([]string, error) {
+	guard, exists := s.transferGuards[fromAccount.tenantID]
+	if !exists {
+		guard = s.transferGuards[""]
+	}
+
+	var flags []string
+	if fromAccount.accountID == toID && guard.SelfTransfers != "" {
+		if guard.SelfTransfers == GuardReject {
+			return nil, ErrSelfTransfer
+		}
+		flags = append(flags, ErrSelfTransfer.Error())
+	}
+	if guard.RoundTrips != "" && guard.RoundTripWindow > 0 && amount >= guard.RoundTripThreshold && s.reversesRecentTransfer(timestamp, fromAccount.accountID, toID, guard) {
+		if guard.RoundTrips == GuardReject {
+			return nil, ErrRoundTrip
+		}
+		flags = append(flags, ErrRoundTrip.Error())
+	}
+
+	proposed := ProposedTransfer{Timestamp: timestamp, FromID: fromAccount.accountID, ToID: toID, TenantID: fromAccount.tenantID, Amount: amount}
+	for _, rule := range s.transferRules {
+		flag, err := rule(proposed)
+		if err != nil {
+			return nil, err
+		}
+		if flag != "" {
+			flags = append(flags, flag)
+		}
+	}
+	return flags, nil
+}
+
+// reversesRecentTransfer reports whether toID sent fromID at least the guard's threshold within
+// its window before timestamp.
+func (s *AccountStore) reversesRecentTransfer(timestamp int, fromID, toID string, guard TransferGuard) bool {
+	for _, movement := range s.counterparties[toID][fromID] {
+		if movement.sent && movement.amount >= guard.RoundTripThreshold && timestamp-movement.timestamp <= guard.RoundTripWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// flagTransfer records the transfer just committed as flagged. Callers must hold the write
+// lock.
+func (s *AccountStore) flagTransfer(event Event, reasons []string) {
+	s.flaggedTransfers = append(s.flaggedTransfers, FlaggedTransfer{
+		Seq:       s.lastSeq,
+		Timestamp: event.Timestamp,
+		FromID:    event.AccountID,
+		ToID:      event.CounterpartyID,
+		Amount:    event.Amount,
+		Reasons:   reasons,
+	})
+	s.logger.Warn("flagged transfer", "from", event.AccountID, "to", event.CounterpartyID, "amount", event.Amount, "reasons", reasons)
+}
+
+// FlaggedTransfers returns every flagged transfer in commit order.
+func (s *AccountStore) FlaggedTransfers() []FlaggedTransfer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]FlaggedTransfer(nil), s.flaggedTransfers...)
+}
@@ -0,0 +1,109 @@
+package bankingsystem
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferGuard(t *testing.T) {
+	t.Run("Rejects Self Transfers", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 100)
+		store.SetTransferGuard("", TransferGuard{SelfTransfers: GuardReject})
+
+		// ACT
+		_, err := store.Transfer(2, "a", "a", 10)
+		_, dryRunErr := store.DryRunTransfer(2, "a", "a", 10)
+
+		// ASSERT
+		assert.ErrorIs(t, err, ErrSelfTransfer, "expected self-transfer rejection")
+		assert.ErrorIs(t, dryRunErr, ErrSelfTransfer, "dry run should apply the guard")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(100), account.Balance, "balance should be untouched")
+	})
+
+	t.Run("Flags Self Transfers", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 100)
+		store.SetTransferGuard("", TransferGuard{SelfTransfers: GuardFlag})
+
+		// ACT
+		ok, err := store.Transfer(2, "a", "a", 10)
+
+		// ASSERT
+		assert.NoError(t, err, "flagged transfers should go through")
+		assert.True(t, ok, "expected transfer to succeed")
+		account, _ := store.GetAccount("a")
+		assert.Equal(t, float64(100), account.Balance, "a self-transfer should not change the balance")
+		assert.Equal(t, []FlaggedTransfer{{Seq: 2, Timestamp: 2, FromID: "a", ToID: "a", Amount: 10, Reasons: []string{ErrSelfTransfer.Error()}}}, store.FlaggedTransfers(), "flagged transfers mismatch")
+	})
+
+	t.Run("Rejects Round Trips Within The Window", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 1000)
+		store.SetTransferGuard("", TransferGuard{RoundTrips: GuardReject, RoundTripWindow: 60, RoundTripThreshold: 100})
+		store.Transfer(10, "a", "b", 500)
+
+		// ACT
+		_, small := store.Transfer(20, "b", "a", 50)
+		_, large := store.Transfer(30, "b", "a", 400)
+		_, late := store.Transfer(100, "b", "a", 400)
+
+		// ASSERT
+		assert.NoError(t, small, "transfers below the threshold should go through")
+		assert.ErrorIs(t, large, ErrRoundTrip, "expected round-trip rejection")
+		assert.NoError(t, late, "transfers after the window should go through")
+	})
+
+	t.Run("Guards Per Tenant", func(t *testing.T) {
+		// ARRANGE
+		store := NewAccountStore()
+		store.CreateTenantAccount(1, "acme", "a", 100)
+		store.CreateTenantAccount(1, "globex", "b", 100)
+		store.SetTransferGuard("acme", TransferGuard{SelfTransfers: GuardReject})
+
+		// ACT
+		_, acmeErr := store.Transfer(2, "a", "a", 10)
+		_, globexErr := store.Transfer(2, "b", "b", 10)
+
+		// ASSERT
+		assert.ErrorIs(t, acmeErr, ErrSelfTransfer, "expected acme's guard to apply")
+		assert.NoError(t, globexErr, "globex has no guard")
+	})
+
+	t.Run("Custom Rules", func(t *testing.T) {
+		// ARRANGE
+		errBlocked := errors.New("blocked")
+		store := NewAccountStore()
+		store.CreateAccount(1, "a", 1000)
+		store.CreateAccount(1, "b", 1000)
+		store.AddTransferRule(func(transfer ProposedTransfer) (string, error) {
+			switch {
+			case transfer.Amount > 500:
+				return "", errBlocked
+			case transfer.Amount > 100:
+				return "large", nil
+			}
+			return "", nil
+		})
+
+		// ACT
+		_, blocked := store.Transfer(2, "a", "b", 600)
+		_, flagged := store.Transfer(3, "a", "b", 200)
+		_, clean := store.Transfer(4, "a", "b", 50)
+
+		// ASSERT
+		assert.ErrorIs(t, blocked, errBlocked, "expected the rule's error")
+		assert.NoError(t, flagged, "flagged transfers should go through")
+		assert.NoError(t, clean, "unexpected error")
+		flags := store.FlaggedTransfers()
+		assert.Len(t, flags, 1, "expected one flagged transfer")
+		assert.Equal(t, []string{"large"}, flags[0].Reasons, "reasons mismatch")
+	})
+}
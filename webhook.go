@@ -0,0 +1,319 @@
+package bankingsystem
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WebhookSignatureHeader carries the delivery timestamp and one v1 signature per active
+// secret, e.g. "t=1700000000,v1=5257a8...,v1=9f86d0...".
+const WebhookSignatureHeader = "X-Bank-Signature"
+
+const webhookQueueSize = 1024
+
+// WebhookDispatcher delivers store events to HTTP endpoints, signing every payload with each
+// of the endpoint's active secrets.
+type WebhookDispatcher struct {
+	mu              sync.RWMutex
+	client          *http.Client
+	clock           Clock
+	retries         RetryPolicy
+	endpoints       map[string]*webhookEndpoint
+	queue           chan webhookDelivery
+	onUndeliverable func(endpointID string, event Event, reason string)
+}
+
+type webhookEndpoint struct {
+	url     string
+	secrets []webhookSecret
+}
+
+// webhookSecret is a signing secret. A zero expiresAt never expires.
+type webhookSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+type webhookDelivery struct {
+	endpointID string
+	event      Event
+}
+
+// NewWebhookDispatcher creates a dispatcher that retries each failed delivery according to
+// retries before giving up on it.
+func NewWebhookDispatcher(client *http.Client, clock Clock, retries RetryPolicy) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		client:    client,
+		clock:     clock,
+		retries:   retries,
+		endpoints: make(map[string]*webhookEndpoint),
+		queue:     make(chan webhookDelivery, webhookQueueSize),
+	}
+}
+
+// AttachWebhooks delivers every event committed from now on through d. Deliveries that still
+// fail after retries are moved to the dead-letter queue.
+func (s *AccountStore) AttachWebhooks(d *WebhookDispatcher) {
+	d.mu.Lock()
+	d.onUndeliverable = s.deadLetterWebhook
+	d.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.webhooks = d
+	s.subscribe(d.Enqueue)
+}
+
+func (s *AccountStore) deadLetterWebhook(endpointID string, event Event, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.addDeadLetter(&DeadLetter{
+		Kind:       DeadLetterWebhook,
+		AccountID:  event.AccountID,
+		Amount:     event.Amount,
+		EndpointID: endpointID,
+		Event:      event,
+		Reason:     reason,
+	})
+}
+
+func (d *WebhookDispatcher) AddEndpoint(endpointID, url, secret string) error {
+	if secret == "" {
+		return errors.New("webhook secret is required")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, exists := d.endpoints[endpointID]; exists {
+		return errors.New("webhook endpoint already exists")
+	}
+	d.endpoints[endpointID] = &webhookEndpoint{url: url, secrets: []webhookSecret{{value: secret}}}
+	return nil
+}
+
+// RotateSecret makes newSecret the endpoint's primary secret. Existing secrets keep being used
+// to sign deliveries for overlap, giving consumers time to switch over.
+func (d *WebhookDispatcher) RotateSecret(endpointID, newSecret string, overlap time.Duration) error {
+	if newSecret == "" {
+		return errors.New("webhook secret is required")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	endpoint, exists := d.endpoints[endpointID]
+	if !exists {
+		return errors.New("webhook endpoint not found")
+	}
+
+	now := d.clock.Now()
+	secrets := []webhookSecret{{value: newSecret}}
+	for _, secret := range endpoint.activeSecrets(now) {
+		if secret.expiresAt.IsZero() || secret.expiresAt.After(now.Add(overlap)) {
+			secret.expiresAt = now.Add(overlap)
+		}
+		secrets = append(secrets, secret)
+	}
+	endpoint.secrets = secrets
+	return nil
+}
+
+// Enqueue queues event for delivery to every endpoint. It never blocks; if the queue is full
+// the delivery is treated as undeliverable.
+func (d *WebhookDispatcher) Enqueue(event Event) {
+	d.mu.RLock()
+	endpointIDs := make([]string, 0, len(d.endpoints))
+	for endpointID := range d.endpoints {
+		endpointIDs = append(endpointIDs, endpointID)
+	}
+	d.mu.RUnlock()
+
+	for _, endpointID := range endpointIDs {
+		d.enqueue(endpointID, event)
+	}
+}
+
+func (d *WebhookDispatcher) enqueue(endpointID string, event Event) {
+	select {
+	case d.queue <- webhookDelivery{endpointID: endpointID, event: event}:
+	default:
+		// Enqueue may run under the store's lock, so report the failure asynchronously.
+		go d.undeliverable(endpointID, event, "delivery queue full")
+	}
+}
+
+// Run delivers queued events until ctx is done.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-d.queue:
+			d.deliverWithRetries(ctx, delivery)
+		}
+	}
+}
+
+func (d *WebhookDispatcher) deliverWithRetries(ctx context.Context, delivery webhookDelivery) {
+	var err error
+	for attempt := 0; attempt <= d.retries.MaxRetries; attempt++ {
+		if attempt > 0 {
+			if sleepErr := sleepContext(ctx, d.clock, d.retries.Backoff); sleepErr != nil {
+				return
+			}
+		}
+		if err = d.Deliver(ctx, delivery.endpointID, delivery.event); err == nil {
+			return
+		}
+	}
+	d.undeliverable(delivery.endpointID, delivery.event, err.Error())
+}
+
+func (d *WebhookDispatcher) undeliverable(endpointID string, event Event, reason string) {
+	d.mu.RLock()
+	onUndeliverable := d.onUndeliverable
+	d.mu.RUnlock()
+
+	if onUndeliverable != nil {
+		onUndeliverable(endpointID, event, reason)
+	}
+}
+
+// Deliver makes a single signed delivery attempt of event to an endpoint.
+func (d *WebhookDispatcher) Deliver(ctx context.Context, endpointID string, event Event) error {
+	d.mu.RLock()
+	endpoint, exists := d.endpoints[endpointID]
+	var url string
+	var secrets []string
+	now := d.clock.Now()
+	if exists {
+		url = endpoint.url
+		for _, secret := range endpoint.activeSecrets(now) {
+			secrets = append(secrets, secret.value)
+		}
+	}
+	d.mu.RUnlock()
+
+	if !exists {
+		return errors.New("webhook endpoint not found")
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookSignatureHeader, SignWebhookPayload(payload, now, secrets...))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("webhook endpoint responded with %s", response.Status)
+	}
+	return nil
+}
+
+func (e *webhookEndpoint) activeSecrets(now time.Time) []webhookSecret {
+	var active []webhookSecret
+	for _, secret := range e.secrets {
+		if secret.expiresAt.IsZero() || now.Before(secret.expiresAt) {
+			active = append(active, secret)
+		}
+	}
+	return active
+}
+
+// SignWebhookPayload builds a signature header value for payload, with one v1 signature per
+// secret. Each signature is the hex HMAC-SHA256 of "<unix timestamp>.<payload>".
+func SignWebhookPayload(payload []byte, timestamp time.Time, secrets ...string) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	parts := []string{"t=" + unix}
+	for _, secret := range secrets {
+		parts = append(parts, "v1="+webhookSignature(payload, unix, secret))
+	}
+	return strings.Join(parts, ",")
+}
+
+// VerifyWebhookSignature checks that header carries a valid signature of payload for secret,
+// made no more than tolerance before now. Consumers rotating secrets can call it once per
+// secret they accept.
+func VerifyWebhookSignature(payload []byte, header, secret string, tolerance time.Duration, now time.Time) error {
+	var unix string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil {
+		return errors.New("webhook signature has no valid timestamp")
+	}
+	age := now.Sub(time.Unix(seconds, 0))
+	if age > tolerance || age < -tolerance {
+		return errors.New("webhook signature timestamp outside tolerance")
+	}
+
+	expected := []byte(webhookSignature(payload, unix, secret))
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+	return errors.New("webhook signature mismatch")
+}
+
+func webhookSignature(payload []byte, unix, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// sleepContext waits d on clock, returning early with ctx's error if ctx is done first.
+func sleepContext(ctx context.Context, clock Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	done := make(chan struct{})
+	timer := clock.AfterFunc(d, func() { close(done) })
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,138 @@
+package bankingsystem
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSignatures(t *testing.T) {
+	payload := []byte(`{"Seq":1}`)
+	now := time.Unix(1700000000, 0)
+
+	t.Run("Valid Signature", func(t *testing.T) {
+		// ARRANGE
+		header := SignWebhookPayload(payload, now, "secret")
+
+		// ACT
+		err := VerifyWebhookSignature(payload, header, "secret", time.Minute, now.Add(30*time.Second))
+
+		// ASSERT
+		assert.NoError(t, err, "expected signature to verify")
+	})
+
+	t.Run("Wrong Secret", func(t *testing.T) {
+		// ARRANGE
+		header := SignWebhookPayload(payload, now, "secret")
+
+		// ACT
+		err := VerifyWebhookSignature(payload, header, "other", time.Minute, now)
+
+		// ASSERT
+		assert.EqualError(t, err, "webhook signature mismatch", "unexpected error message")
+	})
+
+	t.Run("Tampered Payload", func(t *testing.T) {
+		// ARRANGE
+		header := SignWebhookPayload(payload, now, "secret")
+
+		// ACT
+		err := VerifyWebhookSignature([]byte(`{"Seq":2}`), header, "secret", time.Minute, now)
+
+		// ASSERT
+		assert.EqualError(t, err, "webhook signature mismatch", "unexpected error message")
+	})
+
+	t.Run("Stale Signature", func(t *testing.T) {
+		// ARRANGE
+		header := SignWebhookPayload(payload, now, "secret")
+
+		// ACT
+		err := VerifyWebhookSignature(payload, header, "secret", time.Minute, now.Add(2*time.Minute))
+
+		// ASSERT
+		assert.EqualError(t, err, "webhook signature timestamp outside tolerance", "unexpected error message")
+	})
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	t.Run("Delivers Signed Events With Rotation", func(t *testing.T) {
+		// ARRANGE
+		var mu sync.Mutex
+		var headers []string
+		clock := newManualClock(time.Unix(100, 0))
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			header := r.Header.Get(WebhookSignatureHeader)
+			assert.NoError(t, VerifyWebhookSignature(body, header, "new", time.Minute, clock.Now()), "expected new secret to verify")
+			mu.Lock()
+			headers = append(headers, header)
+			mu.Unlock()
+		}))
+		defer server.Close()
+
+		dispatcher := NewWebhookDispatcher(server.Client(), clock, RetryPolicy{})
+		assert.NoError(t, dispatcher.AddEndpoint("hook", server.URL, "old"), "unexpected error adding endpoint")
+		assert.NoError(t, dispatcher.RotateSecret("hook", "new", time.Hour), "unexpected error rotating secret")
+		event := Event{Seq: 1, Timestamp: 100, Type: EventAccountCreated, AccountID: "a", Amount: 5}
+
+		// ACT
+		duringOverlap := dispatcher.Deliver(context.Background(), "hook", event)
+		clock.Advance(2 * time.Hour)
+		afterOverlap := dispatcher.Deliver(context.Background(), "hook", event)
+
+		// ASSERT
+		assert.NoError(t, duringOverlap, "unexpected delivery error")
+		assert.NoError(t, afterOverlap, "unexpected delivery error")
+		payload := []byte(`{"Seq":1,"Timestamp":100,"Type":"account_created","AccountID":"a","CounterpartyID":"","TenantID":"","Amount":5,"Currency":"","SourceIDs":null,"State":"","Reason":"","Actor":"","ValueTimestamp":0,"CreditedAmount":0}`)
+		assert.NoError(t, VerifyWebhookSignature(payload, headers[0], "old", time.Minute, time.Unix(100, 0)), "old secret should verify during overlap")
+		assert.EqualError(t, VerifyWebhookSignature(payload, headers[1], "old", time.Minute, clock.Now()), "webhook signature mismatch", "old secret should not sign after overlap")
+	})
+
+	t.Run("Undeliverable Events Are Dead-Lettered", func(t *testing.T) {
+		// ARRANGE
+		var mu sync.Mutex
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests++
+			mu.Unlock()
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		store := NewAccountStore()
+		dispatcher := NewWebhookDispatcher(server.Client(), systemClock{}, RetryPolicy{MaxRetries: 1})
+		dispatcher.AddEndpoint("hook", server.URL, "secret")
+		store.AttachWebhooks(dispatcher)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go dispatcher.Run(ctx)
+
+		// ACT
+		store.CreateAccount(1, "a", 100)
+
+		// ASSERT
+		assert.Eventually(t, func() bool {
+			return len(store.ListDeadLetters()) == 1
+		}, 5*time.Second, 10*time.Millisecond, "expected the delivery to be dead-lettered")
+		deadLetter := store.ListDeadLetters()[0]
+		assert.Equal(t, DeadLetterWebhook, deadLetter.Kind, "kind mismatch")
+		assert.Equal(t, "hook", deadLetter.EndpointID, "endpoint mismatch")
+		assert.Equal(t, EventAccountCreated, deadLetter.Event.Type, "event mismatch")
+		mu.Lock()
+		assert.Equal(t, 2, requests, "expected one attempt plus one retry")
+		mu.Unlock()
+
+		assert.NoError(t, store.RequeueDeadLetter(1, deadLetter.ID), "unexpected error requeueing")
+		assert.Eventually(t, func() bool {
+			return len(store.ListDeadLetters()) == 1 && store.ListDeadLetters()[0].ID != deadLetter.ID
+		}, 5*time.Second, 10*time.Millisecond, "expected the requeued delivery to be attempted again")
+	})
+}